@@ -2,6 +2,7 @@ package bootstrap
 
 import (
 	"app/bootstrap/closers"
+	"app/bootstrap/workers"
 	"context"
 	"errors"
 	"log"
@@ -15,6 +16,7 @@ import (
 type App struct {
 	srv     *http.Server
 	closers []closers.Closer
+	workers []workers.Worker
 }
 
 func NewApp(handler http.Handler, addr string) *App {
@@ -33,11 +35,19 @@ func (a *App) RegisterCloser(c closers.Closer) {
 	a.closers = append(a.closers, c)
 }
 
+func (a *App) RegisterWorker(w workers.Worker) {
+	a.workers = append(a.workers, w)
+}
+
 func (a *App) run() error {
 	return a.srv.ListenAndServe()
 }
 
 func (a *App) RunWithGracefulShutdown() {
+	for _, w := range a.workers {
+		w.Start(context.Background())
+	}
+
 	go func() {
 		if err := a.run(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("server error: %v", err)
@@ -3,6 +3,9 @@ package configs
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,7 +16,114 @@ type Config struct {
 	DBName        string
 	DBSSLMode     string
 	JWTPrivateKey string
-	JWTPublicKey  string
+
+	// Issuer is the public base URL of the service. It is the iss claim of every
+	// token and the base of the endpoints in the discovery document.
+	Issuer                 string
+	TokenEndpointPath      string
+	RevocationEndpointPath string
+
+	// JWTKeysDir, when set, takes precedence over JWTPrivateKey and the keys below.
+	JWTKeysDir           string
+	JWTNextPrivateKey    string
+	JWTNextKeyPromoteAt  string
+	JWTRetiredPublicKeys string
+
+	// TrustUserHeader lets requests without a bearer token authenticate with a
+	// raw X-User-Id header. Only enable it behind a gateway that verifies tokens.
+	TrustUserHeader bool
+
+	// TrustedProxies lists the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header gives the client IP of a request, such as
+	// "10.0.0.0/8". It is empty by default, so the header is ignored and
+	// cannot be forged to get around rate limits and lockouts.
+	TrustedProxies []string
+
+	// DevMode relaxes checks meant for production, such as the required token
+	// signing secret. Never enable it in a deployment.
+	DevMode bool
+
+	// AccessTokenDenylist is "postgres" or "memory". The memory denylist is not
+	// shared between instances.
+	AccessTokenDenylist   string
+	DenylistPurgeInterval time.Duration
+
+	// TokenSigningSecret signs the links sent to users, such as email
+	// verification links. It is required unless DevMode is set, where a random
+	// secret is used that invalidates outstanding links on every restart.
+	TokenSigningSecret   string
+	RequireVerifiedEmail bool
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+	MagicLinkTTL         time.Duration
+
+	// MFAIssuer names the service in authenticator apps. MFAChallengeTTL is how
+	// long a login waits for the second factor.
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+
+	// WebAuthnRPID is the domain passkeys are bound to and WebAuthnOrigins
+	// the comma-separated origins allowed to use them, which must be on that
+	// domain. WebAuthnChallengeTTL is how long a ceremony may take.
+	WebAuthnRPID         string
+	WebAuthnRPName       string
+	WebAuthnOrigins      string
+	WebAuthnChallengeTTL time.Duration
+
+	// SMSSender is "gateway" or "log" and has no default. The log sender
+	// writes every code to stdout, so it is only accepted in DevMode. The
+	// gateway gets every message as a JSON POST to SMSGatewayURL, with
+	// SMSGatewayToken as a bearer token.
+	SMSSender          string
+	SMSGatewayURL      string
+	SMSGatewayToken    string
+	SMSFrom            string
+	SMSCodeTTL         time.Duration
+	SMSCodeMaxAttempts int
+
+	// FederationProviders are the external OpenID Connect providers users
+	// can log in with, listed in FEDERATION_PROVIDERS, such as
+	// "google,apple". FederationStateTTL is how long a login may take there.
+	FederationProviders []FederationProvider
+	FederationStateTTL  time.Duration
+
+	// Argon2id parameters of new password hashes. Hashes made with other
+	// parameters or with bcrypt are upgraded on the next login.
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	// LoginLockoutThreshold and LoginLockoutIPThreshold are the failed logins
+	// after which an account or an IP address is locked, zero disables them.
+	LoginLockoutThreshold   int
+	LoginLockoutIPThreshold int
+	LoginLockoutCooldown    time.Duration
+	LoginLockoutMaxCooldown time.Duration
+
+	PasswordMinLength          int
+	PasswordMaxLength          int
+	PasswordMinCharClasses     int
+	PasswordRejectPersonalInfo bool
+	// BreachedPasswordsDir holds SHA-1 range files in the layout of the Have I
+	// Been Pwned range API. The short list shipped with the service is used
+	// when it is empty, "none" disables the check.
+	BreachedPasswordsDir string
+
+	// RateLimitStore is "memory" or "postgres". The memory store is not shared
+	// between instances.
+	RateLimitStore         string
+	RateLimitPurgeInterval time.Duration
+	// RateLimits lists the rules of every limited route, such as
+	// "login=ip:20/1m,email:5/1m;register=ip:5/1h". "none" disables them.
+	RateLimits string
+
+	// OutboxPublisher is "webhook" or "memory" and has no default. The memory
+	// publisher drops every event, including the emails to send, so it is only
+	// accepted in DevMode.
+	OutboxPublisher    string
+	OutboxWebhookURL   string
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
 }
 
 func LoadConfig() *Config {
@@ -25,8 +135,125 @@ func LoadConfig() *Config {
 		DBName:        getEnv("POSTGRES_DB", "postgres"),
 		DBSSLMode:     getEnv("POSTGRES_SSLMODE", "disable"),
 		JWTPrivateKey: getEnv("JWT_PRIVATE_KEY", "secret"),
-		JWTPublicKey:  getEnv("JWT_PUBLIC_KEY", "public_secret"),
+
+		Issuer:                 strings.TrimSuffix(getEnv("AUTH_ISSUER", "http://localhost:8080"), "/"),
+		TokenEndpointPath:      getEnv("OIDC_TOKEN_ENDPOINT_PATH", "/oauth/token"),
+		RevocationEndpointPath: getEnv("OIDC_REVOCATION_ENDPOINT_PATH", "/oauth/revoke"),
+
+		JWTKeysDir:           getEnv("JWT_KEYS_DIR", ""),
+		JWTNextPrivateKey:    getEnv("JWT_NEXT_PRIVATE_KEY", ""),
+		JWTNextKeyPromoteAt:  getEnv("JWT_NEXT_KEY_PROMOTE_AT", ""),
+		JWTRetiredPublicKeys: getEnv("JWT_RETIRED_PUBLIC_KEYS", ""),
+
+		TrustUserHeader: getEnvBool("AUTH_TRUST_USER_HEADER", false),
+
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		DevMode: getEnvBool("AUTH_DEV_MODE", false),
+
+		AccessTokenDenylist:   getEnv("ACCESS_TOKEN_DENYLIST", "postgres"),
+		DenylistPurgeInterval: getEnvDuration("DENYLIST_PURGE_INTERVAL", 5*time.Minute),
+
+		TokenSigningSecret:   getEnv("AUTH_TOKEN_SIGNING_SECRET", ""),
+		RequireVerifiedEmail: getEnvBool("AUTH_REQUIRE_VERIFIED_EMAIL", false),
+		EmailVerificationTTL: getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		MagicLinkTTL:         getEnvDuration("MAGIC_LINK_TTL", 15*time.Minute),
+
+		MFAIssuer:       getEnv("MFA_ISSUER", "App"),
+		MFAChallengeTTL: getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+
+		WebAuthnRPID:         getEnv("WEBAUTHN_RP_ID", "localhost"),
+		WebAuthnRPName:       getEnv("WEBAUTHN_RP_NAME", "App"),
+		WebAuthnOrigins:      getEnv("WEBAUTHN_ORIGINS", "http://localhost:8080"),
+		WebAuthnChallengeTTL: getEnvDuration("WEBAUTHN_CHALLENGE_TTL", 5*time.Minute),
+
+		SMSSender:          getEnv("SMS_SENDER", ""),
+		SMSGatewayURL:      getEnv("SMS_GATEWAY_URL", ""),
+		SMSGatewayToken:    getEnv("SMS_GATEWAY_TOKEN", ""),
+		SMSFrom:            getEnv("SMS_FROM", ""),
+		SMSCodeTTL:         getEnvDuration("SMS_CODE_TTL", 5*time.Minute),
+		SMSCodeMaxAttempts: getEnvInt("SMS_CODE_MAX_ATTEMPTS", 5),
+
+		FederationProviders: loadFederationProviders(getEnv("FEDERATION_PROVIDERS", "")),
+		FederationStateTTL:  getEnvDuration("FEDERATION_STATE_TTL", 10*time.Minute),
+
+		Argon2Memory:      uint32(getEnvInt("ARGON2_MEMORY_KIB", 64*1024)),
+		Argon2Iterations:  uint32(getEnvInt("ARGON2_ITERATIONS", 3)),
+		Argon2Parallelism: uint8(getEnvInt("ARGON2_PARALLELISM", 4)),
+
+		LoginLockoutThreshold:   getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+		LoginLockoutIPThreshold: getEnvInt("LOGIN_LOCKOUT_IP_THRESHOLD", 50),
+		LoginLockoutCooldown:    getEnvDuration("LOGIN_LOCKOUT_COOLDOWN", time.Minute),
+		LoginLockoutMaxCooldown: getEnvDuration("LOGIN_LOCKOUT_MAX_COOLDOWN", time.Hour),
+
+		PasswordMinLength:          getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMaxLength:          getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		PasswordMinCharClasses:     getEnvInt("PASSWORD_MIN_CHAR_CLASSES", 2),
+		PasswordRejectPersonalInfo: getEnvBool("PASSWORD_REJECT_PERSONAL_INFO", true),
+		BreachedPasswordsDir:       getEnv("BREACHED_PASSWORDS_DIR", ""),
+
+		RateLimitStore:         getEnv("RATE_LIMIT_STORE", "memory"),
+		RateLimitPurgeInterval: getEnvDuration("RATE_LIMIT_PURGE_INTERVAL", 5*time.Minute),
+		RateLimits:             getEnv("RATE_LIMITS", defaultRateLimits),
+
+		OutboxPublisher:    getEnv("OUTBOX_PUBLISHER", ""),
+		OutboxWebhookURL:   getEnv("OUTBOX_WEBHOOK_URL", ""),
+		OutboxPollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 100),
+	}
+}
+
+const defaultRateLimits = "login=ip:20/1m,email:5/1m;" +
+	"register=ip:5/1h;" +
+	"refresh=ip:60/1m;" +
+	"forgot-password=ip:5/15m,email:3/1h;" +
+	"reset-password=ip:10/15m;" +
+	"change-password=user:5/15m;" +
+	"login-mfa=ip:20/1m;" +
+	"mfa=user:10/15m;" +
+	"webauthn-login=ip:20/1m;" +
+	"magic-link=ip:5/15m,email:3/1h;" +
+	"magic-link-consume=ip:10/15m;" +
+	"phone=user:5/15m;" +
+	"sms-send=ip:5/15m,phone:3/15m;" +
+	"sms-login=ip:20/1m;" +
+	"federation=ip:30/1m;" +
+	"resend-verification=ip:5/15m,email:3/1h;" +
+	"verify-email=ip:10/15m;" +
+	"oauth-authorize=ip:20/1m;" +
+	"oauth-token=ip:60/1m"
+
+// FederationProvider is an external OpenID Connect provider, configured by
+// the FEDERATION_<NAME>_* variables. The issuer and scopes of google and
+// apple are known and only need to be set for other providers.
+type FederationProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// Scopes is space-separated.
+	Scopes string
+}
+
+func loadFederationProviders(names string) []FederationProvider {
+	var providers []FederationProvider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "FEDERATION_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		providers = append(providers, FederationProvider{
+			Name:         name,
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getEnv(prefix+"SCOPES", ""),
+		})
 	}
+	return providers
 }
 
 func (c *Config) DSN() string {
@@ -42,3 +269,35 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// getEnvList splits a comma-separated variable, skipping empty entries.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
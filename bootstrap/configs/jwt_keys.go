@@ -0,0 +1,108 @@
+package configs
+
+import (
+	"app/internal/utils"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LoadKeyring builds the JWT signing keyring. Keys are read from JWTKeysDir
+// when it is set and from the JWT_* env vars otherwise.
+func LoadKeyring(c *Config) (*utils.Keyring, error) {
+	if c.JWTKeysDir != "" {
+		return LoadKeyringFromDir(c.JWTKeysDir, c.JWTNextKeyPromoteAt)
+	}
+	return LoadKeyringFromPEM(c.JWTPrivateKey, c.JWTNextPrivateKey, c.JWTRetiredPublicKeys, c.JWTNextKeyPromoteAt)
+}
+
+// LoadKeyringFromPEM builds a keyring from PEM strings. next and retired are
+// optional; retired may hold several PEM blocks.
+func LoadKeyringFromPEM(active, next, retired, promoteAt string) (*utils.Keyring, error) {
+	activeKey, err := utils.ParseSigningKeyPEM(active)
+	if err != nil {
+		return nil, fmt.Errorf("active key: %w", err)
+	}
+
+	keyring, err := utils.NewKeyring(activeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if retired != "" {
+		retiredKeys, err := utils.ParseVerificationKeysPEM(retired)
+		if err != nil {
+			return nil, fmt.Errorf("retired keys: %w", err)
+		}
+		for _, key := range retiredKeys {
+			keyring.AddRetired(key)
+		}
+	}
+
+	if next != "" {
+		nextKey, err := utils.ParseSigningKeyPEM(next)
+		if err != nil {
+			return nil, fmt.Errorf("next key: %w", err)
+		}
+
+		at, err := parsePromoteAt(promoteAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := keyring.SchedulePromotion(nextKey, at); err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
+
+// LoadKeyringFromDir builds a keyring from a directory laid out as
+//
+//	active.pem     private key used for signing
+//	next.pem       optional private key promoted at promoteAt
+//	retired/*.pem  optional public or private keys kept for verification
+func LoadKeyringFromDir(dir string, promoteAt string) (*utils.Keyring, error) {
+	active, err := os.ReadFile(filepath.Join(dir, "active.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := os.ReadFile(filepath.Join(dir, "next.pem"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	retiredFiles, err := filepath.Glob(filepath.Join(dir, "retired", "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	var retired []byte
+	for _, file := range retiredFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		retired = append(retired, data...)
+		retired = append(retired, '\n')
+	}
+
+	return LoadKeyringFromPEM(string(active), string(next), string(retired), promoteAt)
+}
+
+func parsePromoteAt(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("JWT_NEXT_KEY_PROMOTE_AT is required when a next key is configured")
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT_NEXT_KEY_PROMOTE_AT: %w", err)
+	}
+
+	return at, nil
+}
@@ -2,15 +2,29 @@ package helpers
 
 import (
 	"app/bootstrap/configs"
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/federation"
 	"app/internal/handlers"
 	"app/internal/middlewares"
+	"app/internal/publishers"
+	"app/internal/ratelimits"
+	"app/internal/relays"
+	"app/internal/repositories"
 	"app/internal/services"
+	"app/internal/sms"
 	"app/internal/stores"
 	"app/internal/uows"
 	"app/internal/utils"
 	"app/internal/validators"
+	"app/internal/webauthn"
+	"crypto/rand"
+	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"log"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -22,43 +36,465 @@ func MustInitDB(cfg *configs.Config) *configs.Wrapper {
 	return dbWrapper
 }
 
-func BuildAuthHandler(dbWrapper *configs.Wrapper, jwtPrivateKey string) *handlers.AuthHandler {
-	jwtHelper, err := utils.NewJWTManager(jwtPrivateKey, 15*time.Minute, "my_key_id")
+// BuildRouter only takes the client IP from X-Forwarded-For when the request
+// comes from one of the configured proxies.
+func BuildRouter(cfg *configs.Config) *gin.Engine {
+	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("invalid trusted proxies: %v", err)
+	}
+	return r
+}
+
+func MustLoadKeyring(cfg *configs.Config) *utils.Keyring {
+	keyring, err := configs.LoadKeyring(cfg)
 	if err != nil {
-		log.Fatalf("could not initialize JWT manager: %v", err)
+		log.Fatalf("could not load JWT signing keys: %v", err)
+	}
+	return keyring
+}
+
+const accessTokenTTL = 15 * time.Minute
+
+func BuildJWTManager(keyring *utils.Keyring, issuer string) *utils.JWTManager {
+	return utils.NewJWTManager(keyring, accessTokenTTL, issuer)
+}
+
+func BuildDenylist(dbWrapper *configs.Wrapper, cfg *configs.Config) denylists.Denylist {
+	switch cfg.AccessTokenDenylist {
+	case "postgres":
+		return denylists.NewPostgresDenylist(dbWrapper.DB())
+	case "memory":
+		return denylists.NewMemoryDenylist()
+	default:
+		log.Fatalf("unknown access token denylist: %s", cfg.AccessTokenDenylist)
+		return nil
+	}
+}
+
+func BuildRateLimitStore(dbWrapper *configs.Wrapper, cfg *configs.Config) ratelimits.Store {
+	switch cfg.RateLimitStore {
+	case "postgres":
+		return ratelimits.NewPostgresStore(dbWrapper.DB())
+	case "memory":
+		return ratelimits.NewMemoryStore()
+	default:
+		log.Fatalf("unknown rate limit store: %s", cfg.RateLimitStore)
+		return nil
+	}
+}
+
+// BuildRateLimiter returns nil when rate limits are disabled, which lets every
+// request through.
+func BuildRateLimiter(store ratelimits.Store, cfg *configs.Config) *middlewares.RateLimiter {
+	if cfg.RateLimits == "none" {
+		return nil
+	}
+
+	rules, err := middlewares.ParseRateLimitRules(cfg.RateLimits)
+	if err != nil {
+		log.Fatalf("failed to parse rate limits: %v", err)
+	}
+	return middlewares.NewRateLimiter(store, rules)
+}
+
+func BuildTokenSigner(cfg *configs.Config) *utils.HMACTokenSigner {
+	secret := []byte(cfg.TokenSigningSecret)
+	if len(secret) == 0 {
+		if !cfg.DevMode {
+			log.Fatalf("AUTH_TOKEN_SIGNING_SECRET is required unless AUTH_DEV_MODE is set")
+		}
+		log.Println("WARNING: AUTH_TOKEN_SIGNING_SECRET is empty, links sent to users stop working on restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("could not generate token signing secret: %v", err)
+		}
+	}
+	return utils.NewHMACTokenSigner(secret)
+}
+
+func BuildPasswordHasher(cfg *configs.Config) *utils.DispatchHasher {
+	params := utils.DefaultArgon2idParams
+	params.Memory = cfg.Argon2Memory
+	params.Iterations = cfg.Argon2Iterations
+	params.Parallelism = cfg.Argon2Parallelism
+
+	return utils.NewDispatchHasher(utils.NewArgon2idHasher(params), utils.NewBcryptHasher())
+}
+
+func BuildLockoutService(cfg *configs.Config) *services.LockoutService {
+	return services.NewLockoutService(services.LockoutPolicy{
+		AccountThreshold: cfg.LoginLockoutThreshold,
+		IPThreshold:      cfg.LoginLockoutIPThreshold,
+		Cooldown:         cfg.LoginLockoutCooldown,
+		MaxCooldown:      cfg.LoginLockoutMaxCooldown,
+	})
+}
+
+func BuildMFAService(cfg *configs.Config, tokenSigner utils.TokenSigner) *services.MFAService {
+	return services.NewMFAService(utils.NewTOTP(), tokenSigner, cfg.MFAIssuer, cfg.MFAChallengeTTL)
+}
+
+func BuildPasswordPolicy(cfg *configs.Config) *validators.PasswordPolicy {
+	policy := &validators.PasswordPolicy{
+		MinLength:          cfg.PasswordMinLength,
+		MaxLength:          cfg.PasswordMaxLength,
+		MinCharClasses:     cfg.PasswordMinCharClasses,
+		RejectPersonalInfo: cfg.PasswordRejectPersonalInfo,
 	}
 
-	uow := uows.NewGormUnitOfWork[*stores.UserTokenOutboxStore](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
-	hasher := utils.NewBcryptHasher()
+	switch cfg.BreachedPasswordsDir {
+	case "none":
+	case "":
+		policy.Breached = validators.DefaultBreachedPasswords()
+	default:
+		if _, err := os.Stat(cfg.BreachedPasswordsDir); err != nil {
+			log.Fatalf("could not open breached password list: %v", err)
+		}
+		policy.Breached = validators.NewBreachedPasswordRanges(os.DirFS(cfg.BreachedPasswordsDir))
+	}
+
+	return policy
+}
+
+func buildRequestValidator(passwordPolicy *validators.PasswordPolicy) *middlewares.RequestValidator {
+	v := validator.New()
+	if err := passwordPolicy.Register(v); err != nil {
+		log.Fatalf("could not register password policy: %v", err)
+	}
+	return middlewares.NewRequestValidator(validators.NewValidator(v))
+}
+
+func BuildAccessTokenVerifier(
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	trustUserHeader bool,
+) *middlewares.AccessTokenVerifier {
+	if trustUserHeader {
+		log.Println("WARNING: X-User-Id header is trusted for requests without a bearer token")
+	}
+	return middlewares.NewAccessTokenVerifier(jwtHelper, denylist, trustUserHeader)
+}
+
+func BuildAuthHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	mfaService *services.MFAService,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.AuthHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	hasher := BuildPasswordHasher(cfg)
 	tokenGenerator := utils.NewTokenGenerator()
 	val := validators.NewValidator(validator.New())
 	middleware := middlewares.NewRequestValidator(val)
 
-	usersSvc := services.NewUserService(hasher)
-	tokensSvc := services.NewTokenService(hasher, tokenGenerator, jwtHelper)
+	usersSvc := services.NewUserService(hasher, nil, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(tokenGenerator, jwtHelper, denylist)
 	outboxSvc := services.NewOutboxService()
-	authHandler := handlers.NewAuthHandler(uow, middleware, usersSvc, tokensSvc, outboxSvc)
+	authHandler := handlers.NewAuthHandler(uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, tokensSvc, BuildLockoutService(cfg), mfaService, outboxSvc)
 
 	return authHandler
 }
 
-func BuildUserHandler(dbWrapper *configs.Wrapper) *handlers.UserHandler {
-	uow := uows.NewGormUnitOfWork[*stores.UserTokenOutboxStore](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
-	hasher := utils.NewBcryptHasher()
-	val := validators.NewValidator(validator.New())
-	middleware := middlewares.NewRequestValidator(val)
+func BuildUserHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	tokenSigner utils.TokenSigner,
+	passwordPolicy *validators.PasswordPolicy,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.UserHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	hasher := BuildPasswordHasher(cfg)
+	middleware := buildRequestValidator(passwordPolicy)
 
-	usersSvc := services.NewUserService(hasher)
+	usersSvc := services.NewUserService(hasher, passwordPolicy, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(utils.NewTokenGenerator(), jwtHelper, denylist)
+	verificationSvc := services.NewEmailVerificationService(tokenSigner, cfg.EmailVerificationTTL)
 	outboxSvc := services.NewOutboxService()
 
-	return handlers.NewUserHandler(uow, middleware, usersSvc, outboxSvc)
+	return handlers.NewUserHandler(uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, tokensSvc, verificationSvc, outboxSvc)
 }
 
-func BuildJwksHandler(jwtPublicKey string) *handlers.JwksHandler {
-	jwksStr, err := configs.LoadJWKSFromPEM(jwtPublicKey, "my_key_id")
-	if err != nil {
-		log.Fatalf("could not initialize JWT manager: %v", err)
+func BuildPasswordHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	passwordPolicy *validators.PasswordPolicy,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.PasswordHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	hasher := BuildPasswordHasher(cfg)
+	tokenGenerator := utils.NewTokenGenerator()
+	middleware := buildRequestValidator(passwordPolicy)
+
+	usersSvc := services.NewUserService(hasher, passwordPolicy, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(tokenGenerator, jwtHelper, denylist)
+	resetSvc := services.NewPasswordResetService(hasher, tokenGenerator, passwordPolicy, cfg.PasswordResetTTL)
+	outboxSvc := services.NewOutboxService()
+
+	return handlers.NewPasswordHandler(uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, tokensSvc, resetSvc, outboxSvc)
+}
+
+func BuildMagicLinkHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	mfaService *services.MFAService,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.MagicLinkHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	tokenGenerator := utils.NewTokenGenerator()
+	middleware := middlewares.NewRequestValidator(validators.NewValidator(validator.New()))
+
+	tokensSvc := services.NewTokenService(tokenGenerator, jwtHelper, denylist)
+	magicLinksSvc := services.NewMagicLinkService(BuildPasswordHasher(cfg), tokenGenerator, cfg.MagicLinkTTL)
+
+	return handlers.NewMagicLinkHandler(
+		uow, middleware, rateLimiter, tokensSvc, BuildLockoutService(cfg), mfaService, magicLinksSvc, services.NewOutboxService(),
+	)
+}
+
+func BuildSMSSender(cfg *configs.Config) sms.SMSSender {
+	var sender sms.SMSSender
+	switch cfg.SMSSender {
+	case "gateway":
+		if cfg.SMSGatewayURL == "" {
+			log.Fatalf("SMS_GATEWAY_URL is required for the gateway sms sender")
+		}
+		sender = sms.NewGatewaySender(cfg.SMSGatewayURL, cfg.SMSGatewayToken, cfg.SMSFrom, nil)
+	case "log":
+		if !cfg.DevMode {
+			log.Fatalf("the log sms sender prints every code and needs AUTH_DEV_MODE")
+		}
+		sender = sms.NewLogSender()
+	case "":
+		log.Fatalf("SMS_SENDER is required")
+	default:
+		log.Fatalf("unknown sms sender: %s", cfg.SMSSender)
+	}
+	return sender
+}
+
+func BuildSMSHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	mfaService *services.MFAService,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.SMSHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	hasher := BuildPasswordHasher(cfg)
+	v := validator.New()
+	if err := validators.RegisterPhone(v); err != nil {
+		log.Fatalf("could not register phone validator: %v", err)
+	}
+	middleware := middlewares.NewRequestValidator(validators.NewValidator(v))
+
+	usersSvc := services.NewUserService(hasher, nil, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(utils.NewTokenGenerator(), jwtHelper, denylist)
+	smsCodesSvc := services.NewSMSCodeService(hasher, BuildSMSSender(cfg), cfg.SMSCodeTTL, cfg.SMSCodeMaxAttempts)
+
+	return handlers.NewSMSHandler(
+		uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, tokensSvc, BuildLockoutService(cfg), mfaService, smsCodesSvc, services.NewOutboxService(),
+	)
+}
+
+// BuildFederationRegistry builds the providers of FEDERATION_PROVIDERS.
+// Their endpoints are discovered on first use.
+func BuildFederationRegistry(cfg *configs.Config) *federation.Registry {
+	providers := make([]*federation.Provider, 0, len(cfg.FederationProviders))
+	for _, p := range cfg.FederationProviders {
+		config := federation.Preset(federation.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Scopes:       strings.Fields(p.Scopes),
+		})
+		if config.Issuer == "" || config.ClientID == "" {
+			log.Fatalf("identity provider %s needs an issuer and a client id", p.Name)
+		}
+		providers = append(providers, federation.NewProvider(config, nil))
+	}
+	return federation.NewRegistry(providers...)
+}
+
+func BuildFederationHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	tokenSigner utils.TokenSigner,
+	mfaService *services.MFAService,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.FederationHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	tokenGenerator := utils.NewTokenGenerator()
+
+	tokensSvc := services.NewTokenService(tokenGenerator, jwtHelper, denylist)
+	federationSvc := services.NewFederationService(
+		BuildFederationRegistry(cfg), tokenSigner, tokenGenerator, cfg.Issuer+"/auth/federation", cfg.FederationStateTTL,
+	)
+
+	return handlers.NewFederationHandler(
+		uow, rateLimiter, tokensSvc, BuildLockoutService(cfg), mfaService, federationSvc, services.NewOutboxService(),
+		strings.HasPrefix(cfg.Issuer, "https://"),
+	)
+}
+
+func BuildOAuthHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	tokenSigner utils.TokenSigner,
+	mfaService *services.MFAService,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.OAuthHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	hasher := BuildPasswordHasher(cfg)
+	tokenGenerator := utils.NewTokenGenerator()
+
+	usersSvc := services.NewUserService(hasher, nil, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(tokenGenerator, jwtHelper, denylist)
+	clientsSvc := services.NewClientService(hasher, tokenGenerator)
+	authorizationsSvc := services.NewAuthorizationService(tokenGenerator, tokenSigner)
+	outboxSvc := services.NewOutboxService()
+
+	return handlers.NewOAuthHandler(
+		uow, rateLimiter, usersSvc, tokensSvc, clientsSvc, authorizationsSvc, BuildLockoutService(cfg), mfaService, outboxSvc, accessTokenTTL,
+		strings.HasPrefix(cfg.Issuer, "https://"),
+	)
+}
+
+func BuildMFAHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	mfaService *services.MFAService,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.MFAHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	middleware := middlewares.NewRequestValidator(validators.NewValidator(validator.New()))
+	usersSvc := services.NewUserService(BuildPasswordHasher(cfg), nil, cfg.RequireVerifiedEmail)
+
+	return handlers.NewMFAHandler(uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, mfaService, services.NewOutboxService())
+}
+
+func BuildWebAuthnHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	jwtHelper utils.JWTHelper,
+	denylist denylists.Denylist,
+	tokenSigner utils.TokenSigner,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+) *handlers.WebAuthnHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	middleware := middlewares.NewRequestValidator(validators.NewValidator(validator.New()))
+	usersSvc := services.NewUserService(BuildPasswordHasher(cfg), nil, cfg.RequireVerifiedEmail)
+	tokensSvc := services.NewTokenService(utils.NewTokenGenerator(), jwtHelper, denylist)
+
+	rp := &webauthn.RelyingParty{
+		ID:                      cfg.WebAuthnRPID,
+		Name:                    cfg.WebAuthnRPName,
+		RequireUserVerification: true,
+	}
+	for _, origin := range strings.Split(cfg.WebAuthnOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			rp.Origins = append(rp.Origins, origin)
+		}
+	}
+	webAuthnSvc := services.NewWebAuthnService(rp, tokenSigner, cfg.WebAuthnChallengeTTL)
+
+	return handlers.NewWebAuthnHandler(
+		uow, middleware, accessTokenVerifier, rateLimiter, usersSvc, tokensSvc, BuildLockoutService(cfg), webAuthnSvc, services.NewOutboxService(),
+	)
+}
+
+func BuildAdminHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+) *handlers.AdminHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	usersSvc := services.NewUserService(BuildPasswordHasher(cfg), nil, cfg.RequireVerifiedEmail)
+
+	return handlers.NewAdminHandler(uow, accessTokenVerifier, usersSvc, BuildLockoutService(cfg), services.NewOutboxService())
+}
+
+func BuildJwksHandler(keyring *utils.Keyring) *handlers.JwksHandler {
+	return handlers.NewJwksHandler(keyring)
+}
+
+func BuildOutboxRelay(dbWrapper *configs.Wrapper, cfg *configs.Config) *relays.OutboxRelay {
+	var publisher publishers.Publisher
+	switch cfg.OutboxPublisher {
+	case "webhook":
+		if cfg.OutboxWebhookURL == "" {
+			log.Fatalf("OUTBOX_WEBHOOK_URL is required for the webhook publisher")
+		}
+		publisher = publishers.NewWebhookPublisher(cfg.OutboxWebhookURL, nil)
+	case "memory":
+		if !cfg.DevMode {
+			log.Fatalf("the memory outbox publisher drops every event and needs AUTH_DEV_MODE")
+		}
+		publisher = publishers.NewMemoryPublisher()
+	case "":
+		log.Fatalf("OUTBOX_PUBLISHER is required")
+	default:
+		log.Fatalf("unknown outbox publisher: %s", cfg.OutboxPublisher)
+	}
+
+	uow := uows.NewGormUnitOfWork[repositories.EventRepository](dbWrapper.DB(), repositories.NewEventRepository)
+
+	return relays.NewOutboxRelay(uow, publisher, cfg.OutboxPollInterval, cfg.OutboxBatchSize)
+}
+
+func BuildOIDCHandler(
+	dbWrapper *configs.Wrapper,
+	cfg *configs.Config,
+	keyring *utils.Keyring,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+) *handlers.OIDCHandler {
+	uow := uows.NewGormUnitOfWork[stores.Store](dbWrapper.DB(), stores.NewUserTokenOutboxStore)
+	usersSvc := services.NewUserService(BuildPasswordHasher(cfg), nil, cfg.RequireVerifiedEmail)
+
+	grantTypes := []string{
+		domain.GrantTypeAuthorizationCode,
+		domain.GrantTypePassword,
+		domain.GrantTypeRefreshToken,
+		domain.GrantTypeClientCredentials,
+	}
+
+	configuration := dto.OpenIDConfiguration{
+		Issuer:                            cfg.Issuer,
+		AuthorizationEndpoint:             cfg.Issuer + "/oauth/authorize",
+		TokenEndpoint:                     cfg.Issuer + cfg.TokenEndpointPath,
+		JwksURI:                           cfg.Issuer + "/auth/.well-known/jwks.json",
+		UserinfoEndpoint:                  cfg.Issuer + "/userinfo",
+		RevocationEndpoint:                cfg.Issuer + cfg.RevocationEndpointPath,
+		IntrospectionEndpoint:             cfg.Issuer + "/oauth/introspect",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               grantTypes,
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{utils.PKCEMethodS256},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  keyring.Algorithms(),
+		ScopesSupported:                   []string{"openid", "profile", "email"},
+		ClaimsSupported:                   []string{"sub", "email", "given_name", "family_name"},
 	}
 
-	return handlers.NewJwksHandler(jwksStr)
+	return handlers.NewOIDCHandler(uow, accessTokenVerifier, usersSvc, configuration)
 }
@@ -0,0 +1,81 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Purger deletes the rows of a store that no longer matter.
+type Purger interface {
+	Purge(ctx context.Context) error
+}
+
+// PurgeWorker calls a Purger every interval until it is closed.
+type PurgeWorker struct {
+	name     string
+	purger   Purger
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewPurgeWorker(name string, purger Purger, interval time.Duration) *PurgeWorker {
+	return &PurgeWorker{
+		name:     name,
+		purger:   purger,
+		interval: interval,
+	}
+}
+
+func (w *PurgeWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.done != nil {
+		return
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+}
+
+func (w *PurgeWorker) Close(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *PurgeWorker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.purger.Purge(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("%s: failed to purge: %v", w.name, err)
+			}
+		}
+	}
+}
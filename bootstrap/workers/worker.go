@@ -0,0 +1,7 @@
+package workers
+
+import "context"
+
+type Worker interface {
+	Start(ctx context.Context)
+}
@@ -4,7 +4,9 @@ import (
 	"app/bootstrap"
 	"app/bootstrap/configs"
 	"app/bootstrap/helpers"
-	"github.com/gin-gonic/gin"
+	"app/bootstrap/workers"
+	"app/internal/denylists"
+	"app/internal/ratelimits"
 )
 
 func main() {
@@ -12,17 +14,60 @@ func main() {
 
 	dbWrapper := helpers.MustInitDB(cfg)
 
-	jwksHandler := helpers.BuildJwksHandler(cfg.JWTPublicKey)
-	userHandler := helpers.BuildUserHandler(dbWrapper)
-	authHandler := helpers.BuildAuthHandler(dbWrapper, cfg.JWTPrivateKey)
+	keyring := helpers.MustLoadKeyring(cfg)
+	jwtManager := helpers.BuildJWTManager(keyring, cfg.Issuer)
+	denylist := helpers.BuildDenylist(dbWrapper, cfg)
+	tokenSigner := helpers.BuildTokenSigner(cfg)
+	passwordPolicy := helpers.BuildPasswordPolicy(cfg)
+	mfaService := helpers.BuildMFAService(cfg, tokenSigner)
+	accessTokenVerifier := helpers.BuildAccessTokenVerifier(jwtManager, denylist, cfg.TrustUserHeader)
+	rateLimitStore := helpers.BuildRateLimitStore(dbWrapper, cfg)
+	rateLimiter := helpers.BuildRateLimiter(rateLimitStore, cfg)
+
+	jwksHandler := helpers.BuildJwksHandler(keyring)
+	userHandler := helpers.BuildUserHandler(dbWrapper, cfg, jwtManager, denylist, tokenSigner, passwordPolicy, accessTokenVerifier, rateLimiter)
+	authHandler := helpers.BuildAuthHandler(dbWrapper, cfg, jwtManager, denylist, mfaService, accessTokenVerifier, rateLimiter)
+	passwordHandler := helpers.BuildPasswordHandler(dbWrapper, cfg, jwtManager, denylist, passwordPolicy, accessTokenVerifier, rateLimiter)
+	magicLinkHandler := helpers.BuildMagicLinkHandler(dbWrapper, cfg, jwtManager, denylist, mfaService, rateLimiter)
+	smsHandler := helpers.BuildSMSHandler(dbWrapper, cfg, jwtManager, denylist, mfaService, accessTokenVerifier, rateLimiter)
+	federationHandler := helpers.BuildFederationHandler(dbWrapper, cfg, jwtManager, denylist, tokenSigner, mfaService, rateLimiter)
+	oidcHandler := helpers.BuildOIDCHandler(dbWrapper, cfg, keyring, accessTokenVerifier)
+	oauthHandler := helpers.BuildOAuthHandler(dbWrapper, cfg, jwtManager, denylist, tokenSigner, mfaService, rateLimiter)
+	mfaHandler := helpers.BuildMFAHandler(dbWrapper, cfg, mfaService, accessTokenVerifier, rateLimiter)
+	webAuthnHandler := helpers.BuildWebAuthnHandler(dbWrapper, cfg, jwtManager, denylist, tokenSigner, accessTokenVerifier, rateLimiter)
+	adminHandler := helpers.BuildAdminHandler(dbWrapper, cfg, accessTokenVerifier)
+	outboxRelay := helpers.BuildOutboxRelay(dbWrapper, cfg)
+
+	r := helpers.BuildRouter(cfg)
+	oidcHandler.BindRoutes(&r.RouterGroup)
+	oauthHandler.BindRoutes(&r.RouterGroup)
 
-	r := gin.Default()
 	auth := r.Group("/auth")
 	jwksHandler.BindRoutes(auth)
 	userHandler.BindRoutes(auth)
 	authHandler.BindRoutes(auth)
+	passwordHandler.BindRoutes(auth)
+	magicLinkHandler.BindRoutes(auth)
+	smsHandler.BindRoutes(auth)
+	federationHandler.BindRoutes(auth)
+	mfaHandler.BindRoutes(auth)
+	webAuthnHandler.BindRoutes(auth)
+
+	adminHandler.BindRoutes(r.Group("/admin"))
 
 	app := bootstrap.NewApp(r, ":8080")
+	app.RegisterWorker(outboxRelay)
+	app.RegisterCloser(outboxRelay)
+	if purger, ok := denylist.(*denylists.PostgresDenylist); ok {
+		worker := workers.NewPurgeWorker("denylist", purger, cfg.DenylistPurgeInterval)
+		app.RegisterWorker(worker)
+		app.RegisterCloser(worker)
+	}
+	if purger, ok := rateLimitStore.(*ratelimits.PostgresStore); ok {
+		worker := workers.NewPurgeWorker("ratelimits", purger, cfg.RateLimitPurgeInterval)
+		app.RegisterWorker(worker)
+		app.RegisterCloser(worker)
+	}
 	app.RegisterCloser(dbWrapper)
 
 	app.RunWithGracefulShutdown()
@@ -0,0 +1,51 @@
+// Command clients registers an OAuth client for the /oauth/token endpoint and
+// prints its credentials. The secret is only stored hashed, so this is the only
+// time it is shown.
+//
+//	go run ./cmd/clients -name billing -grants client_credentials -scopes "orders:read"
+package main
+
+import (
+	"app/bootstrap/configs"
+	"app/bootstrap/helpers"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+)
+
+func main() {
+	name := flag.String("name", "", "human readable client name")
+	grants := flag.String("grants", "client_credentials", "comma separated grant types")
+	scopes := flag.String("scopes", "", "comma or space separated scopes")
+	redirectURIs := flag.String("redirect-uris", "", "comma or space separated redirect URIs for authorization_code")
+	public := flag.Bool("public", false, "register a public client without a secret")
+	flag.Parse()
+
+	cfg := configs.LoadConfig()
+	dbWrapper := helpers.MustInitDB(cfg)
+	defer dbWrapper.Close(context.Background())
+
+	clientsSvc := services.NewClientService(helpers.BuildPasswordHasher(cfg), utils.NewTokenGenerator())
+	store := stores.NewUserTokenOutboxStore(dbWrapper.DB())
+
+	client, secret, err := clientsSvc.Register(store, *name, splitList(*grants), splitList(*scopes), splitList(*redirectURIs), *public)
+	if err != nil {
+		log.Fatalf("failed to register client: %v", err)
+	}
+
+	fmt.Printf("client_id=%s\n", client.ClientID)
+	if secret != "" {
+		fmt.Printf("client_secret=%s\n", secret)
+	}
+}
+
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
@@ -7,6 +7,7 @@ require (
 	github.com/go-playground/validator/v10 v10.20.0
 	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/google/uuid v1.6.0
+	github.com/jamoliddin05/auth-service/pkg/authz v0.0.0-00010101000000-000000000000
 	github.com/square/go-jose/v3 v3.0.0-20200630053402-0a67ce9b0693
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/crypto v0.42.0
@@ -53,3 +54,8 @@ require (
 	google.golang.org/protobuf v1.36.8 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+// authz is its own module, so services can depend on it without the rest of
+// the auth service. The replace only serves local builds of this repository;
+// other services require a tagged version.
+replace github.com/jamoliddin05/auth-service/pkg/authz => ./pkg/authz
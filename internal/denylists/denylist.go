@@ -0,0 +1,39 @@
+package denylists
+
+import (
+	"app/internal/utils"
+	"context"
+	"time"
+)
+
+// Denylist holds the jti of access tokens that were revoked before they
+// expired. Entries only need to live as long as the token would have, so every
+// entry carries a TTL equal to the remaining lifetime of its token.
+//
+// A subject entry revokes every token of a subject issued before a point in
+// time, for when the jtis of those tokens are not known. Its TTL is the
+// lifetime of an access token.
+//
+//go:generate mockery --name=Denylist --output=../mocks --structname=DenylistMock
+type Denylist interface {
+	Add(ctx context.Context, jti string, ttl time.Duration) error
+	Contains(ctx context.Context, jti string) (bool, error)
+	AddSubject(ctx context.Context, subject string, issuedBefore time.Time, ttl time.Duration) error
+	ContainsSubject(ctx context.Context, subject string, issuedAt time.Time) (bool, error)
+}
+
+// Revoked reports whether the token of claims was revoked, on its own or along
+// with the other tokens of its subject. A token without an issue time counts
+// as issued before any subject entry.
+func Revoked(ctx context.Context, d Denylist, claims *utils.Claims) (bool, error) {
+	revoked, err := d.Contains(ctx, claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	return d.ContainsSubject(ctx, claims.Subject, issuedAt)
+}
@@ -0,0 +1,91 @@
+package denylists
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryDenylist keeps revoked jtis in memory. Entries are not shared between
+// instances, so it is only meant for single instance deployments and tests.
+type MemoryDenylist struct {
+	mu       sync.Mutex
+	entries  map[string]time.Time
+	subjects map[string]subjectEntry
+	now      func() time.Time
+}
+
+type subjectEntry struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{
+		entries:  make(map[string]time.Time),
+		subjects: make(map[string]subjectEntry),
+		now:      time.Now,
+	}
+}
+
+func (d *MemoryDenylist) Add(_ context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for k, expiresAt := range d.entries {
+		if !expiresAt.After(now) {
+			delete(d.entries, k)
+		}
+	}
+	d.entries[jti] = now.Add(ttl)
+
+	return nil
+}
+
+func (d *MemoryDenylist) Contains(_ context.Context, jti string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.entries[jti]
+	return ok && expiresAt.After(d.now()), nil
+}
+
+func (d *MemoryDenylist) AddSubject(_ context.Context, subject string, issuedBefore time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	for k, entry := range d.subjects {
+		if !entry.expiresAt.After(now) {
+			delete(d.subjects, k)
+		}
+	}
+
+	entry := d.subjects[subject]
+	if issuedBefore.After(entry.issuedBefore) {
+		entry.issuedBefore = issuedBefore
+	}
+	if expiresAt := now.Add(ttl); expiresAt.After(entry.expiresAt) {
+		entry.expiresAt = expiresAt
+	}
+	d.subjects[subject] = entry
+
+	return nil
+}
+
+func (d *MemoryDenylist) ContainsSubject(_ context.Context, subject string, issuedAt time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.subjects[subject]
+	return ok && entry.expiresAt.After(d.now()) && issuedAt.Before(entry.issuedBefore), nil
+}
@@ -0,0 +1,63 @@
+package denylists
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryDenylist(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	denylist := NewMemoryDenylist()
+	denylist.now = func() time.Time { return now }
+
+	assert.NoError(t, denylist.Add(ctx, "revoked", time.Minute))
+	assert.NoError(t, denylist.Add(ctx, "expired", 0))
+
+	contains, _ := denylist.Contains(ctx, "revoked")
+	assert.True(t, contains)
+	contains, _ = denylist.Contains(ctx, "expired")
+	assert.False(t, contains)
+	contains, _ = denylist.Contains(ctx, "unknown")
+	assert.False(t, contains)
+
+	// Once the token would have expired the entry is no longer needed.
+	now = now.Add(time.Minute)
+	contains, _ = denylist.Contains(ctx, "revoked")
+	assert.False(t, contains)
+
+	assert.NoError(t, denylist.Add(ctx, "other", time.Minute))
+	assert.NotContains(t, denylist.entries, "revoked")
+}
+
+func TestMemoryDenylist_Subject(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	denylist := NewMemoryDenylist()
+	denylist.now = func() time.Time { return now }
+
+	assert.NoError(t, denylist.AddSubject(ctx, "user-1", now, time.Minute))
+
+	contains, _ := denylist.ContainsSubject(ctx, "user-1", now.Add(-time.Second))
+	assert.True(t, contains)
+	contains, _ = denylist.ContainsSubject(ctx, "user-1", now)
+	assert.False(t, contains)
+	contains, _ = denylist.ContainsSubject(ctx, "user-2", now.Add(-time.Second))
+	assert.False(t, contains)
+
+	// An earlier cutoff does not bring back the tokens already revoked.
+	assert.NoError(t, denylist.AddSubject(ctx, "user-1", now.Add(-time.Hour), time.Minute))
+	contains, _ = denylist.ContainsSubject(ctx, "user-1", now.Add(-time.Second))
+	assert.True(t, contains)
+
+	// Once every token issued before the cutoff expired, the entry is no
+	// longer needed.
+	now = now.Add(time.Minute)
+	contains, _ = denylist.ContainsSubject(ctx, "user-1", now.Add(-2*time.Minute))
+	assert.False(t, contains)
+}
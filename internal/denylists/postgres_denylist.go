@@ -0,0 +1,92 @@
+package denylists
+
+import (
+	"app/internal/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresDenylist stores revoked jtis in the revoked_access_tokens table and
+// subject entries in revoked_subjects, so every instance sees a revocation
+// right away. Purge deletes expired entries;
+// run it periodically with a workers.PurgeWorker.
+type PostgresDenylist struct {
+	db *gorm.DB
+}
+
+func NewPostgresDenylist(db *gorm.DB) *PostgresDenylist {
+	return &PostgresDenylist{
+		db: db,
+	}
+}
+
+func (d *PostgresDenylist) Add(ctx context.Context, jti string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.RevokedAccessToken{JTI: jti, ExpiresAt: time.Now().Add(ttl)}).Error
+}
+
+func (d *PostgresDenylist) Contains(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	err := d.db.WithContext(ctx).
+		Model(&domain.RevokedAccessToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// AddSubject keeps the latest issuedBefore and expiry when the subject is
+// already on the denylist.
+func (d *PostgresDenylist) AddSubject(ctx context.Context, subject string, issuedBefore time.Time, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return d.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "subject"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"issued_before": gorm.Expr("GREATEST(revoked_subjects.issued_before, excluded.issued_before)"),
+				"expires_at":    gorm.Expr("GREATEST(revoked_subjects.expires_at, excluded.expires_at)"),
+			}),
+		}).
+		Create(&domain.RevokedSubject{
+			Subject:      subject,
+			IssuedBefore: issuedBefore,
+			ExpiresAt:    time.Now().Add(ttl),
+		}).Error
+}
+
+func (d *PostgresDenylist) ContainsSubject(ctx context.Context, subject string, issuedAt time.Time) (bool, error) {
+	var count int64
+	err := d.db.WithContext(ctx).
+		Model(&domain.RevokedSubject{}).
+		Where("subject = ? AND issued_before > ? AND expires_at > ?", subject, issuedAt, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Purge deletes the entries of tokens that expired by now.
+func (d *PostgresDenylist) Purge(ctx context.Context) error {
+	now := time.Now()
+	if err := d.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.RevokedAccessToken{}).Error; err != nil {
+		return err
+	}
+
+	return d.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&domain.RevokedSubject{}).Error
+}
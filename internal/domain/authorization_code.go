@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// AuthorizationCode is a code issued by /oauth/authorize. Only its hash is
+// stored and UsedAt is set on exchange, so every code works exactly once.
+type AuthorizationCode struct {
+	ID                  uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	CodeHash            string     `json:"-" gorm:"uniqueIndex;not null"`
+	ClientID            string     `json:"client_id" gorm:"not null"`
+	UserID              uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	RedirectURI         string     `json:"redirect_uri" gorm:"not null"`
+	Scope               string     `json:"scope" gorm:"not null;default:''"`
+	CodeChallenge       string     `json:"-" gorm:"not null"`
+	CodeChallengeMethod string     `json:"-" gorm:"not null"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	UsedAt              *time.Time `json:"used_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
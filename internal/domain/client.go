@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	GrantTypePassword          = "password"
+	GrantTypeRefreshToken      = "refresh_token"
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeAuthorizationCode = "authorization_code"
+)
+
+// Client is an OAuth client registered with the token endpoint. Clients without
+// a secret are public and only identify themselves with their ClientID.
+// GrantTypes, Scopes and RedirectURIs are space separated, like the OAuth scope
+// parameter.
+type Client struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;"`
+	ClientID     string    `json:"client_id" gorm:"uniqueIndex;not null"`
+	SecretHash   string    `json:"-" gorm:"not null;default:''"`
+	Name         string    `json:"name" gorm:"not null;default:''"`
+	GrantTypes   string    `json:"grant_types" gorm:"not null;default:''"`
+	Scopes       string    `json:"scopes" gorm:"not null;default:''"`
+	RedirectURIs string    `json:"redirect_uris" gorm:"not null;default:''"`
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+func (c *Client) BeforeCreate(_ *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (c *Client) IsPublic() bool {
+	return c.SecretHash == ""
+}
+
+func (c *Client) AllowsGrant(grantType string) bool {
+	for _, g := range strings.Fields(c.GrantTypes) {
+		if g == grantType {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRedirectURI reports whether uri is one of the registered redirect URIs.
+// URIs are compared as exact strings, as RFC 6749 section 3.1.2 recommends.
+func (c *Client) AllowsRedirectURI(uri string) bool {
+	for _, u := range strings.Fields(c.RedirectURIs) {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsScopes reports whether every scope in the space separated scope list
+// was granted to the client.
+func (c *Client) AllowsScopes(scope string) bool {
+	allowed := strings.Fields(c.Scopes)
+	for _, s := range strings.Fields(scope) {
+		found := false
+		for _, a := range allowed {
+			if a == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
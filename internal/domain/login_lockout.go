@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// LoginLockout counts the failed logins for an account or an IP address. Key
+// is "account:<email>" or "ip:<address>".
+type LoginLockout struct {
+	Key            string     `gorm:"primaryKey"`
+	FailedAttempts int        `gorm:"not null;default:0"`
+	Lockouts       int        `gorm:"not null;default:0"`
+	LockedUntil    *time.Time `gorm:""`
+	LastFailureAt  time.Time  `gorm:"not null"`
+	UpdatedAt      time.Time
+}
+
+// IsLocked reports whether logins for the key are blocked at now.
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// MagicLinkToken is a single-use login token sent by email. The token is
+// "<selector>.<verifier>": the selector finds the row and only the hash of the
+// verifier is stored.
+type MagicLinkToken struct {
+	ID           uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Selector     string     `json:"-" gorm:"uniqueIndex;not null"`
+	VerifierHash string     `json:"-" gorm:"not null"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// RateLimitWindow counts the hits of a rate limit key in the fixed window that
+// starts at WindowStart. It can be deleted once ExpiresAt has passed, when it no
+// longer weighs in the sliding window.
+type RateLimitWindow struct {
+	Key         string    `json:"key" gorm:"primaryKey"`
+	WindowStart time.Time `json:"window_start" gorm:"primaryKey"`
+	Count       int       `json:"count" gorm:"not null"`
+	ExpiresAt   time.Time `json:"expires_at" gorm:"not null;index"`
+}
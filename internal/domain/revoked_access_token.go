@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// RevokedAccessToken is a denylist entry for an access token revoked before it
+// expired. It can be deleted once ExpiresAt has passed.
+type RevokedAccessToken struct {
+	JTI       string    `json:"jti" gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// RevokedSubject is a denylist entry revoking every access token of Subject
+// issued before IssuedBefore. It can be deleted once ExpiresAt has passed,
+// when those tokens expired too.
+type RevokedSubject struct {
+	Subject      string    `json:"subject" gorm:"primaryKey"`
+	IssuedBefore time.Time `json:"issued_before" gorm:"not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
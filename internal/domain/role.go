@@ -0,0 +1,21 @@
+package domain
+
+// Role is a named set of permissions. The roles and their permissions are
+// seeded by migrations; users get roles through UserRole.
+type Role struct {
+	Name        string       `json:"name" gorm:"primaryKey"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;foreignKey:Name;joinForeignKey:Role;references:Name;joinReferences:Permission"`
+}
+
+// Permission is an action a role allows, named "<resource>:<action>". Access
+// tokens carry the permissions of every role of the user.
+type Permission struct {
+	Name        string `json:"name" gorm:"primaryKey"`
+	Description string `json:"description"`
+}
+
+const (
+	PermissionUsersRead   = "users:read"
+	PermissionUsersUnlock = "users:unlock"
+)
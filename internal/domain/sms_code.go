@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// SMSCode is a one-time code sent by SMS to log in or to confirm a phone.
+// Only its hash is stored, and it stops working after too many wrong
+// attempts.
+type SMSCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Phone     string     `json:"phone" gorm:"not null;index:idx_sms_codes_phone_purpose"`
+	Purpose   string     `json:"purpose" gorm:"not null;index:idx_sms_codes_phone_purpose"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -5,12 +5,28 @@ import (
 	"time"
 )
 
+// Token is a refresh token. Every rotation adds a new row with the same
+// SessionID, so a session is also the token family used for reuse detection.
 type Token struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
-	User      *User     `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
-	TokenHash string    `json:"-" gorm:"uniqueIndex;not null"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	User       *User      `json:"user,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	SessionID  uuid.UUID  `json:"session_id" gorm:"type:uuid;not null;index"`
+	ParentID   *uint      `json:"parent_id,omitempty"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;not null"`
+	ClientID   string     `json:"client_id" gorm:"not null;default:''"`
+	Scope      string     `json:"scope" gorm:"not null;default:''"`
+	UserAgent  string     `json:"user_agent" gorm:"not null;default:''"`
+	IP         string     `json:"ip" gorm:"not null;default:''"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the token can still be used to refresh a session.
+func (t *Token) IsActive() bool {
+	return t.RevokedAt == nil && t.RotatedAt == nil && t.ExpiresAt.After(time.Now())
 }
@@ -8,14 +8,20 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;"`
-	Email     string     `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string     `json:"-" gorm:"not null"`
-	Name      string     `json:"name"`
-	Surname   string     `json:"surname"`
-	CreatedAt time.Time  `json:"-"`
-	UpdatedAt time.Time  `json:"-"`
-	Roles     []UserRole `json:"roles" gorm:"foreignKey:UserID"`
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;"`
+	Email    string    `json:"email" gorm:"uniqueIndex;not null"`
+	Password string    `json:"-" gorm:"not null"`
+	Name     string    `json:"name"`
+	Surname  string    `json:"surname"`
+	// EmailVerifiedAt is nil until the user opened the verification link.
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Phone is optional and only set once the user confirmed it with a code
+	// sent by SMS, so it can be used to log in.
+	Phone           *string    `json:"phone" gorm:"uniqueIndex"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"-"`
+	Roles           []UserRole `json:"roles" gorm:"foreignKey:UserID"`
 }
 
 func (u *User) BeforeCreate(_ *gorm.DB) error {
@@ -25,6 +31,15 @@ func (u *User) BeforeCreate(_ *gorm.DB) error {
 	return nil
 }
 
+// RoleNames returns the names of the roles of the user.
+func (u *User) RoleNames() []string {
+	roles := make([]string, len(u.Roles))
+	for i, r := range u.Roles {
+		roles[i] = r.Role
+	}
+	return roles
+}
+
 type UserRole struct {
 	ID     uint      `json:"-" gorm:"primaryKey"`
 	UserID uuid.UUID `json:"-" gorm:"type:uuid;not null"`
@@ -35,4 +50,5 @@ type UserRole struct {
 const (
 	RoleCustomer = "customer"
 	RoleSeller   = "seller"
+	RoleAdmin    = "admin"
 )
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// WebAuthnChallenge records the challenge of a running ceremony. Only its hash
+// is stored, and finishing the ceremony deletes the row, so a challenge
+// answers a single ceremony.
+type WebAuthnChallenge struct {
+	ID            uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChallengeHash string    `json:"-" gorm:"uniqueIndex;not null"`
+	Purpose       string    `json:"purpose" gorm:"not null"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
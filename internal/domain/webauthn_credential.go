@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// WebAuthnCredential is a passkey or security key registered by a user.
+type WebAuthnCredential struct {
+	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User         *User     `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	CredentialID []byte    `json:"-" gorm:"uniqueIndex;not null"`
+	// PublicKey is the COSE_Key of the credential.
+	PublicKey []byte `json:"-" gorm:"not null"`
+	SignCount uint32 `json:"-" gorm:"not null;default:0"`
+	// Transports lists the transports reported at registration, comma
+	// separated, such as "internal,hybrid".
+	Transports      string     `json:"transports"`
+	AAGUID          []byte     `json:"-"`
+	AttestationType string     `json:"attestation_type" gorm:"not null"`
+	Name            string     `json:"name"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+func (c *WebAuthnCredential) TransportList() []string {
+	if c.Transports == "" {
+		return nil
+	}
+	return strings.Split(c.Transports, ",")
+}
@@ -0,0 +1,14 @@
+package dto
+
+// AuthorizeRequest holds the /oauth/authorize parameters. They arrive in the
+// query string on GET and are echoed as hidden fields of the login form, so
+// the POST carries them in the form body.
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
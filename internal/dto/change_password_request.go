@@ -0,0 +1,23 @@
+package dto
+
+// ChangePasswordRequest carries the refresh token of the current session so
+// that it survives when the other sessions are revoked.
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"current_password" validate:"required"`
+	NewPassword         string `json:"new_password" validate:"required,password"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions"`
+	RefreshToken        string `json:"refresh_token" validate:"required_if=RevokeOtherSessions true"`
+}
+
+func (r *ChangePasswordRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "currentpassword":
+		return "ERR_INVALID_CREDENTIALS"
+	case "newpassword":
+		return passwordErrorCode(tag)
+	case "refreshtoken":
+		return "ERR_INVALID_REFRESH_TOKEN"
+	default:
+		return "ERR"
+	}
+}
@@ -0,0 +1,14 @@
+package dto
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *ForgotPasswordRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "email":
+		return "ERR_INVALID_EMAIL"
+	default:
+		return "ERR"
+	}
+}
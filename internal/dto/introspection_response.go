@@ -0,0 +1,19 @@
+package dto
+
+// IntrospectionResponse is the RFC 7662 section 2.2 introspection response.
+// Inactive tokens only carry active=false.
+type IntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"token_type,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	// Permissions is only set for access tokens, with the permissions they
+	// carry.
+	Permissions []string `json:"permissions,omitempty"`
+	Exp         int64    `json:"exp,omitempty"`
+	Iat         int64    `json:"iat,omitempty"`
+	Iss         string   `json:"iss,omitempty"`
+	Jti         string   `json:"jti,omitempty"`
+}
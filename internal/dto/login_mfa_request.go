@@ -0,0 +1,19 @@
+package dto
+
+// LoginMFARequest completes a login that returned an MFAChallengeResponse.
+// Code is a TOTP code or a recovery code.
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+func (r *LoginMFARequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "mfatoken":
+		return "ERR_INVALID_TOKEN"
+	case "code":
+		return "ERR_INVALID_MFA_CODE"
+	default:
+		return "ERR"
+	}
+}
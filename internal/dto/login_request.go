@@ -5,7 +5,7 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required,min=6"`
 }
 
-func (r *LoginRequest) FieldErrorCode(field string) string {
+func (r *LoginRequest) FieldErrorCode(field, tag string) string {
 	switch field {
 	case "email":
 		return "ERR_INVALID_EMAIL"
@@ -0,0 +1,14 @@
+package dto
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (r *LogoutRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "refresh_token":
+		return "ERR_INVALID_REFRESH_TOKEN"
+	default:
+		return "ERR"
+	}
+}
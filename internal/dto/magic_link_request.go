@@ -0,0 +1,27 @@
+package dto
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+func (r *MagicLinkRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "email":
+		return "ERR_INVALID_EMAIL"
+	default:
+		return "ERR"
+	}
+}
+
+type ConsumeMagicLinkRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (r *ConsumeMagicLinkRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "token":
+		return "ERR_INVALID_TOKEN"
+	default:
+		return "ERR"
+	}
+}
@@ -0,0 +1,15 @@
+package dto
+
+// MFACodeRequest carries a TOTP code, or a recovery code where one is accepted.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+func (r *MFACodeRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "code":
+		return "ERR_INVALID_MFA_CODE"
+	default:
+		return "ERR"
+	}
+}
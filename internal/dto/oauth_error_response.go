@@ -0,0 +1,7 @@
+package dto
+
+// OAuthErrorResponse is the RFC 6749 section 5.2 error response.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// passwordErrorCode maps the validation a new password failed to an error
+// code. The password_* tags are the rules of validators.PasswordPolicy.
+func passwordErrorCode(tag string) string {
+	switch tag {
+	case "password_max":
+		return "ERR_PASSWORD_LONG"
+	case "password_classes":
+		return "ERR_PASSWORD_WEAK"
+	case "password_breached":
+		return "ERR_PASSWORD_BREACHED"
+	case "password_personal":
+		return "ERR_PASSWORD_PERSONAL_INFO"
+	default:
+		return "ERR_PASSWORD_SHORT"
+	}
+}
@@ -0,0 +1,31 @@
+package dto
+
+type PhoneRequest struct {
+	Phone string `json:"phone" validate:"required,uzphone"`
+}
+
+func (r *PhoneRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "phone":
+		return "ERR_INVALID_PHONE"
+	default:
+		return "ERR"
+	}
+}
+
+// PhoneCodeRequest carries a code sent by SMS to Phone.
+type PhoneCodeRequest struct {
+	Phone string `json:"phone" validate:"required,uzphone"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
+func (r *PhoneCodeRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "phone":
+		return "ERR_INVALID_PHONE"
+	case "code":
+		return "ERR_INVALID_SMS_CODE"
+	default:
+		return "ERR"
+	}
+}
@@ -4,7 +4,7 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
-func (r *RefreshRequest) FieldErrorCode(field string) string {
+func (r *RefreshRequest) FieldErrorCode(field, tag string) string {
 	switch field {
 	case "refresh_token":
 		return "ERR_INVALID_REFRESH_TOKEN"
@@ -2,17 +2,17 @@ package dto
 
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required,password,password_personal=Email Name Surname"`
 	Name     string `json:"name" validate:"required"`
 	Surname  string `json:"surname" validate:"required"`
 }
 
-func (r *RegisterRequest) FieldErrorCode(field string) string {
+func (r *RegisterRequest) FieldErrorCode(field, tag string) string {
 	switch field {
 	case "email":
 		return "ERR_INVALID_EMAIL"
 	case "password":
-		return "ERR_PASSWORD_SHORT"
+		return passwordErrorCode(tag)
 	case "name":
 		return "ERR_INVALID_NAME"
 	case "surname":
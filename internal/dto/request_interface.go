@@ -1,5 +1,7 @@
 package dto
 
+// Request maps a failed validation to an error code. tag is the validation
+// that failed, such as "required" or one of the password policy rules.
 type Request interface {
-	FieldErrorCode(field string) string
+	FieldErrorCode(field, tag string) string
 }
@@ -0,0 +1,17 @@
+package dto
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+func (r *ResetPasswordRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "token":
+		return "ERR_INVALID_TOKEN"
+	case "password":
+		return passwordErrorCode(tag)
+	default:
+		return "ERR"
+	}
+}
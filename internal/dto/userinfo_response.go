@@ -0,0 +1,20 @@
+package dto
+
+import "app/internal/domain"
+
+// UserInfoResponse holds the standard OpenID Connect claims of a user.
+type UserInfoResponse struct {
+	Sub        string `json:"sub"`
+	Email      string `json:"email"`
+	GivenName  string `json:"given_name,omitempty"`
+	FamilyName string `json:"family_name,omitempty"`
+}
+
+func NewUserInfoResponse(user *domain.User) UserInfoResponse {
+	return UserInfoResponse{
+		Sub:        user.ID.String(),
+		Email:      user.Email,
+		GivenName:  user.Name,
+		FamilyName: user.Surname,
+	}
+}
@@ -0,0 +1,14 @@
+package dto
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (r *VerifyEmailRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "token":
+		return "ERR_INVALID_TOKEN"
+	default:
+		return "ERR"
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "app/internal/webauthn"
+
+// WebAuthnRegistrationRequest finishes the registration of a passkey. Session
+// is the token returned with the creation options.
+type WebAuthnRegistrationRequest struct {
+	Session    string                           `json:"session" validate:"required"`
+	Name       string                           `json:"name" validate:"max=64"`
+	Credential *webauthn.RegistrationCredential `json:"credential" validate:"required"`
+}
+
+func (r *WebAuthnRegistrationRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "session":
+		return "ERR_INVALID_TOKEN"
+	case "name":
+		return "ERR_INVALID_NAME"
+	case "credential":
+		return "ERR_INVALID_WEBAUTHN_RESPONSE"
+	default:
+		return "ERR"
+	}
+}
+
+type WebAuthnLoginRequest struct {
+	Session    string                        `json:"session" validate:"required"`
+	Credential *webauthn.AssertionCredential `json:"credential" validate:"required"`
+}
+
+func (r *WebAuthnLoginRequest) FieldErrorCode(field, tag string) string {
+	switch field {
+	case "session":
+		return "ERR_INVALID_TOKEN"
+	case "credential":
+		return "ERR_INVALID_WEBAUTHN_RESPONSE"
+	default:
+		return "ERR"
+	}
+}
@@ -0,0 +1,14 @@
+package dto
+
+// WebAuthnOptionsResponse carries the options to pass to
+// navigator.credentials and the session token the finishing request must
+// send back.
+type WebAuthnOptionsResponse struct {
+	PublicKey interface{} `json:"publicKey"`
+	Session   string      `json:"session"`
+}
+
+type WebAuthnCredentialResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,250 @@
+package federation
+
+import (
+	"app/internal/utils"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID    = "client-123"
+	testRedirectURI = "https://auth.example.com/auth/federation/acme/callback"
+)
+
+// fakeOIDC is an OpenID Connect provider serving discovery, a JWKS and a
+// token endpoint that answers with ID tokens signed by its key.
+type fakeOIDC struct {
+	*httptest.Server
+	key          *rsa.PrivateKey
+	kid          string
+	jwksRequests int
+	// claims is added to the ID tokens, overriding the defaults.
+	claims jwt.MapClaims
+	// challenges and nonces hold what authorize bound each code to.
+	challenges map[string]string
+	nonces     map[string]string
+}
+
+func newFakeOIDC(t *testing.T) *fakeOIDC {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeOIDC{key: key, kid: "key-1", challenges: map[string]string{}, nonces: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.URL,
+			"authorization_endpoint": f.URL + "/authorize",
+			"token_endpoint":         f.URL + "/token",
+			"jwks_uri":               f.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.jwksRequests++
+		jwk, err := utils.NewJWK(&f.key.PublicKey, f.kid)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(utils.JWKS{Keys: []utils.JWK{jwk}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		code := r.PostFormValue("code")
+		challenge, ok := f.challenges[code]
+		if !ok || r.PostFormValue("client_id") != testClientID || !utils.VerifyPKCE(r.PostFormValue("code_verifier"), challenge) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(f.challenges, code)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": f.idToken(t, f.nonces[code]), "token_type": "Bearer"})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// authorize stands for the user signing in at the provider and returns the
+// code the provider would send to the redirect URI.
+func (f *fakeOIDC) authorize(t *testing.T, authCodeURL string) (code, state string) {
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+	query := u.Query()
+
+	code = "code-" + query.Get("state")
+	f.challenges[code] = query.Get("code_challenge")
+	f.nonces[code] = query.Get("nonce")
+	return code, query.Get("state")
+}
+
+func (f *fakeOIDC) idToken(t *testing.T, nonce string) string {
+	claims := jwt.MapClaims{
+		"iss":            f.URL,
+		"sub":            "subject-1",
+		"aud":            testClientID,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"nonce":          nonce,
+		"email":          "jane@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+	}
+	for name, value := range f.claims {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = f.kid
+	signed, err := token.SignedString(f.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (f *fakeOIDC) provider() *Provider {
+	return NewProvider(Config{Name: "acme", Issuer: f.URL, ClientID: testClientID, ClientSecret: "secret"}, f.Client())
+}
+
+func TestProvider_CodeFlow(t *testing.T) {
+	fake := newFakeOIDC(t)
+	provider := fake.provider()
+	verifier := "verifier-verifier-verifier-verifier-verifier"
+
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), testRedirectURI, "state-1", "nonce-1", utils.PKCEChallenge(verifier))
+	require.NoError(t, err)
+	u, err := url.Parse(authCodeURL)
+	require.NoError(t, err)
+	assert.Equal(t, fake.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", u.Query().Get("response_type"))
+	assert.Equal(t, testRedirectURI, u.Query().Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+
+	code, _ := fake.authorize(t, authCodeURL)
+	rawIDToken, err := provider.Exchange(context.Background(), code, testRedirectURI, verifier)
+	require.NoError(t, err)
+
+	identity, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{Subject: "subject-1", Email: "jane@example.com", EmailVerified: true, GivenName: "Jane", FamilyName: "Doe"}, identity)
+}
+
+func TestProvider_Exchange_WrongVerifier(t *testing.T) {
+	fake := newFakeOIDC(t)
+	provider := fake.provider()
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), testRedirectURI, "state-1", "nonce-1", utils.PKCEChallenge("right-verifier"))
+	require.NoError(t, err)
+	code, _ := fake.authorize(t, authCodeURL)
+
+	_, err = provider.Exchange(context.Background(), code, testRedirectURI, "wrong-verifier")
+
+	assert.ErrorIs(t, err, ErrProvider)
+}
+
+func TestProvider_VerifyIDToken_Rejects(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		nonce  string
+		key    *rsa.PrivateKey
+	}{
+		{name: "nonce mismatch", nonce: "other-nonce"},
+		{name: "wrong audience", claims: jwt.MapClaims{"aud": "other-client"}},
+		{name: "wrong issuer", claims: jwt.MapClaims{"iss": "https://evil.example.com"}},
+		{name: "expired", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "unauthorized party", claims: jwt.MapClaims{"aud": []string{testClientID, "other-client"}, "azp": "other-client"}},
+		{name: "signed by another key", key: otherKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeOIDC(t)
+			fake.claims = tt.claims
+			provider := fake.provider()
+			if tt.key != nil {
+				// The provider caches the genuine key before the forged token arrives.
+				_, err := provider.VerifyIDToken(context.Background(), fake.idToken(t, "nonce-1"), "nonce-1")
+				require.NoError(t, err)
+				fake.key = tt.key
+			}
+			rawIDToken := fake.idToken(t, "nonce-1")
+			nonce := "nonce-1"
+			if tt.nonce != "" {
+				nonce = tt.nonce
+			}
+
+			_, err := provider.VerifyIDToken(context.Background(), rawIDToken, nonce)
+
+			assert.ErrorIs(t, err, ErrInvalidIDToken)
+		})
+	}
+}
+
+func TestProvider_VerifyIDToken_RefetchesRotatedKeys(t *testing.T) {
+	fake := newFakeOIDC(t)
+	provider := fake.provider()
+	_, err := provider.VerifyIDToken(context.Background(), fake.idToken(t, "nonce-1"), "nonce-1")
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	fake.key, fake.kid = newKey, "key-2"
+	// Tokens with an unknown kid only refetch the JWKS once per interval.
+	provider.keysFetchedAt = time.Now().Add(-keysRefreshInterval)
+
+	_, err = provider.VerifyIDToken(context.Background(), fake.idToken(t, "nonce-1"), "nonce-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.jwksRequests)
+}
+
+func TestProvider_VerifyIDToken_AppleStringBooleans(t *testing.T) {
+	fake := newFakeOIDC(t)
+	fake.claims = jwt.MapClaims{"email_verified": "true"}
+
+	identity, err := fake.provider().VerifyIDToken(context.Background(), fake.idToken(t, "nonce-1"), "nonce-1")
+
+	require.NoError(t, err)
+	assert.True(t, identity.EmailVerified)
+}
+
+func TestProvider_Discovery_IssuerMismatch(t *testing.T) {
+	fake := newFakeOIDC(t)
+	provider := NewProvider(Config{Name: "acme", Issuer: fake.URL + "/tenant", ClientID: testClientID}, fake.Client())
+
+	_, err := provider.AuthCodeURL(context.Background(), testRedirectURI, "state-1", "nonce-1", "challenge")
+
+	assert.ErrorIs(t, err, ErrProvider)
+}
+
+func TestPreset(t *testing.T) {
+	apple := Preset(Config{Name: "apple", ClientID: "com.example.web"})
+	custom := Preset(Config{Name: "google", Issuer: "https://accounts.example.com", Scopes: []string{"openid"}})
+
+	assert.Equal(t, "https://appleid.apple.com", apple.Issuer)
+	assert.Equal(t, "form_post", apple.ResponseMode)
+	assert.Equal(t, "https://accounts.example.com", custom.Issuer)
+	assert.Equal(t, []string{"openid"}, custom.Scopes)
+}
+
+func TestRegistry_Get(t *testing.T) {
+	registry := NewRegistry(NewProvider(Config{Name: "google"}, nil))
+
+	provider, err := registry.Get("google")
+	require.NoError(t, err)
+	assert.Equal(t, "google", provider.Name())
+
+	_, err = registry.Get("facebook")
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+	assert.Equal(t, []string{"google"}, registry.Names())
+}
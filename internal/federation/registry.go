@@ -0,0 +1,83 @@
+package federation
+
+import (
+	"errors"
+	"sort"
+)
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+// presets holds the settings of well-known providers, so that only the
+// client credentials have to be configured for them.
+var presets = map[string]Config{
+	"google": {
+		Issuer:  "https://accounts.google.com",
+		Issuers: []string{"accounts.google.com"},
+		Scopes:  []string{"openid", "email", "profile"},
+	},
+	"apple": {
+		Issuer:       "https://appleid.apple.com",
+		Scopes:       []string{"openid", "email", "name"},
+		ResponseMode: "form_post",
+	},
+}
+
+// Preset completes config with the settings of the well-known provider of
+// the same name. Values set in config win.
+func Preset(config Config) Config {
+	preset, ok := presets[config.Name]
+	if !ok {
+		return config
+	}
+
+	if config.Issuer == "" {
+		config.Issuer = preset.Issuer
+	}
+	if len(config.Issuers) == 0 {
+		config.Issuers = preset.Issuers
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = preset.Scopes
+	}
+	if config.ResponseMode == "" {
+		config.ResponseMode = preset.ResponseMode
+	}
+	return config
+}
+
+// Registry holds the configured providers by name.
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(providers ...*Provider) *Registry {
+	registry := &Registry{providers: make(map[string]*Provider, len(providers))}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	if r == nil {
+		return nil, ErrUnknownProvider
+	}
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names returns the names of the providers in alphabetical order.
+func (r *Registry) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jamoliddin05/auth-service/pkg/authz"
+)
+
+// AdminHandler serves the operations reserved to administrators, each behind
+// its own permission.
+type AdminHandler struct {
+	uow                 uows.UnitOfWork[stores.Store]
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	users               *services.UserService
+	lockouts            *services.LockoutService
+	outbox              *services.UserTokenOutboxService
+}
+
+func NewAdminHandler(
+	uow uows.UnitOfWork[stores.Store],
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	users *services.UserService,
+	lockouts *services.LockoutService,
+	outbox *services.UserTokenOutboxService,
+) *AdminHandler {
+	return &AdminHandler{
+		uow:                 uow,
+		accessTokenVerifier: accessTokenVerifier,
+		users:               users,
+		lockouts:            lockouts,
+		outbox:              outbox,
+	}
+}
+
+func (h *AdminHandler) BindRoutes(r *gin.RouterGroup) {
+	r.Use(h.accessTokenVerifier.Handle, authz.RequirePermission(domain.PermissionUsersUnlock))
+	r.POST("/users/:id/unlock", h.UnlockUser)
+}
+
+// UnlockUser lifts the login lock of a user before it runs out.
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			return services.ErrInvalidCredentials
+		}
+
+		user, err := h.users.GetByID(store, c.Param("id"))
+		if err != nil {
+			return err
+		}
+
+		if err := h.lockouts.Unlock(store, user); err != nil {
+			return err
+		}
+
+		return h.outbox.SaveAccountUnlockedEvent(store, services.AccountUnlockedPayload{
+			UserID:     user.ID,
+			UnlockedBy: currentUserID(c),
+		})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			resp.Errors["error"] = "ERR_USER_NOT_FOUND"
+			status = http.StatusNotFound
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/middlewares"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAdminHandler(store stores.Store, jwtHelper utils.JWTHelper) *AdminHandler {
+	return NewAdminHandler(
+		newTxUow(store),
+		middlewares.NewAccessTokenVerifier(jwtHelper, denylists.NewMemoryDenylist(), false),
+		services.NewUserService(nil, nil, false),
+		services.NewLockoutService(services.LockoutPolicy{AccountThreshold: 5}),
+		services.NewOutboxService(),
+	)
+}
+
+func TestAdminHandler_UnlockUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	adminID := "22222222-2222-2222-2222-222222222222"
+	path := "/admin/users/" + userID().String() + "/unlock"
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockLockoutRepo := new(mocks.LoginLockoutRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+		mockJwtHelper := new(mocks.JWTHelperMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("LoginLockouts").Return(mockLockoutRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+		mockJwtHelper.On("ParseAccessToken", "admin").
+			Return(&utils.Claims{
+				UserID:      adminID,
+				Roles:       []string{domain.RoleAdmin},
+				Permissions: []string{domain.PermissionUsersUnlock},
+			}, nil)
+		mockUserRepo.On("GetByID", userID()).Return(&domain.User{ID: userID(), Email: "John@example.com"}, nil)
+		mockLockoutRepo.On("Delete", "account:john@example.com").Return(nil)
+		mockEventRepo.On("Save", services.AccountUnlocked, services.AccountUnlockedPayload{
+			UserID:     userID(),
+			UnlockedBy: adminID,
+		}).Return(nil)
+
+		r := gin.New()
+		newTestAdminHandler(mockStore, mockJwtHelper).BindRoutes(r.Group("/admin"))
+
+		w := performRequest(r, "POST", path, "", map[string]string{"Authorization": "Bearer admin"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockLockoutRepo.AssertExpectations(t)
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockJwtHelper := new(mocks.JWTHelperMock)
+		mockJwtHelper.On("ParseAccessToken", "admin").
+			Return(&utils.Claims{
+				UserID:      adminID,
+				Roles:       []string{domain.RoleAdmin},
+				Permissions: []string{domain.PermissionUsersUnlock},
+			}, nil)
+
+		r := gin.New()
+		newTestAdminHandler(nil, mockJwtHelper).BindRoutes(r.Group("/admin"))
+
+		w := performRequest(r, "POST", "/admin/users/not-a-uuid/unlock", "", map[string]string{"Authorization": "Bearer admin"})
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Not an admin", func(t *testing.T) {
+		mockJwtHelper := new(mocks.JWTHelperMock)
+		mockJwtHelper.On("ParseAccessToken", "customer").
+			Return(&utils.Claims{UserID: userID().String(), Roles: []string{domain.RoleCustomer}}, nil)
+
+		r := gin.New()
+		newTestAdminHandler(nil, mockJwtHelper).BindRoutes(r.Group("/admin"))
+
+		w := performRequest(r, "POST", path, "", map[string]string{"Authorization": "Bearer customer"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_FORBIDDEN")
+	})
+
+	t.Run("Admin role without the permission", func(t *testing.T) {
+		mockJwtHelper := new(mocks.JWTHelperMock)
+		mockJwtHelper.On("ParseAccessToken", "admin").
+			Return(&utils.Claims{UserID: adminID, Roles: []string{domain.RoleAdmin}}, nil)
+
+		r := gin.New()
+		newTestAdminHandler(nil, mockJwtHelper).BindRoutes(r.Group("/admin"))
+
+		w := performRequest(r, "POST", path, "", map[string]string{"Authorization": "Bearer admin"})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -14,32 +14,47 @@ import (
 )
 
 type AuthHandler struct {
-	uow              uows.UnitOfWork[*stores.UserTokenOutboxStore]
-	requestValidator *middlewares.RequestValidator
-	users            *services.UserService
-	tokens           *services.TokenService
-	outbox           *services.UserTokenOutboxService
+	uow                 uows.UnitOfWork[stores.Store]
+	requestValidator    *middlewares.RequestValidator
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	rateLimiter         *middlewares.RateLimiter
+	users               *services.UserService
+	tokens              *services.TokenService
+	lockouts            *services.LockoutService
+	mfa                 *services.MFAService
+	outbox              *services.UserTokenOutboxService
 }
 
 func NewAuthHandler(
-	uow uows.UnitOfWork[*stores.UserTokenOutboxStore],
+	uow uows.UnitOfWork[stores.Store],
 	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
 	users *services.UserService,
 	tokens *services.TokenService,
+	lockouts *services.LockoutService,
+	mfa *services.MFAService,
 	outbox *services.UserTokenOutboxService,
 ) *AuthHandler {
 	return &AuthHandler{
-		uow:              uow,
-		requestValidator: requestValidator,
-		users:            users,
-		tokens:           tokens,
-		outbox:           outbox,
+		uow:                 uow,
+		requestValidator:    requestValidator,
+		accessTokenVerifier: accessTokenVerifier,
+		rateLimiter:         rateLimiter,
+		users:               users,
+		tokens:              tokens,
+		lockouts:            lockouts,
+		mfa:                 mfa,
+		outbox:              outbox,
 	}
 }
 
 func (h *AuthHandler) BindRoutes(r *gin.RouterGroup) {
-	r.POST("/login", h.Login)
-	r.POST("/refresh", h.Refresh)
+	r.POST("/login", h.rateLimiter.Limit("login"), h.Login)
+	r.POST("/login/mfa", h.rateLimiter.Limit("login-mfa"), h.LoginMFA)
+	r.POST("/refresh", h.rateLimiter.Limit("refresh"), h.Refresh)
+	r.POST("/logout", h.accessTokenVerifier.Optional, h.Logout)
+	r.POST("/logout-all", h.accessTokenVerifier.Handle, h.LogoutAll)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -49,19 +64,42 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 	var accessToken string
 	var refreshToken string
-	err := h.uow.DoTransaction(func(store *stores.UserTokenOutboxStore) error {
+	var mfaToken string
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		if err := h.lockouts.Check(store, req.Email, c.ClientIP()); err != nil {
+			return err
+		}
+
 		user, err := h.users.Authenticate(store, req.Email, req.Password)
 		if err != nil {
 			return err
 		}
 
-		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(store, user)
+		// The lockout is only reset once the second factor passed too, so
+		// guessing codes still counts as failed logins.
+		mfaEnabled, err := h.mfa.Enabled(store, user.ID)
+		if err != nil {
+			return err
+		}
+		if mfaEnabled {
+			mfaToken, err = h.mfa.Challenge(user)
+			return err
+		}
+
+		if err := h.lockouts.RecordSuccess(store, req.Email); err != nil {
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(store, user, clientInfo(c))
 		if err != nil {
 			return err
 		}
 
 		return h.outbox.SaveUserLoggedInEvent(store, user)
 	})
+	if err != nil {
+		err = loginFailed(c, h.uow, h.lockouts, h.outbox, req.Email, err)
+	}
 
 	resp := dto.APIResponse{
 		Errors: make(map[string]string),
@@ -73,6 +111,97 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
 			status = http.StatusConflict
+		case errors.Is(err, services.ErrEmailNotVerified):
+			resp.Errors["error"] = "ERR_EMAIL_NOT_VERIFIED"
+			status = http.StatusForbidden
+		case errors.Is(err, services.ErrAccountLocked):
+			setRetryAfter(c, err)
+			resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+			status = http.StatusTooManyRequests
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	if mfaToken != "" {
+		resp.Data = dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+	} else {
+		resp.Data = dto.TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}
+	}
+
+	c.JSON(status, resp)
+}
+
+// LoginMFA completes a login that returned an MFA challenge, with a TOTP code
+// or a recovery code. Wrong codes count as failed logins of the account.
+func (h *AuthHandler) LoginMFA(c *gin.Context) {
+	var req dto.LoginMFARequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var accessToken string
+	var refreshToken string
+	var email string
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		user, err := h.mfa.ChallengeUser(store, req.MFAToken)
+		if err != nil {
+			return err
+		}
+		email = user.Email
+
+		if err := h.lockouts.Check(store, email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		if err := h.mfa.VerifyCode(store, user.ID, req.Code); err != nil {
+			return err
+		}
+
+		if err := h.lockouts.RecordSuccess(store, email); err != nil {
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(store, user, clientInfo(c))
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedInEvent(store, user)
+	})
+	if err != nil && email != "" {
+		err = loginFailed(c, h.uow, h.lockouts, h.outbox, email, err)
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			resp.Errors["error"] = "ERR_INVALID_TOKEN"
+			status = http.StatusUnauthorized
+		case errors.Is(err, services.ErrInvalidMFACode):
+			resp.Errors["error"] = "ERR_INVALID_MFA_CODE"
+			status = http.StatusUnauthorized
+		case errors.Is(err, services.ErrMFANotEnabled):
+			resp.Errors["error"] = "ERR_MFA_NOT_ENABLED"
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrAccountLocked):
+			setRetryAfter(c, err)
+			resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+			status = http.StatusTooManyRequests
 		default:
 			resp.Errors["error"] = "ERR_INTERNAL"
 			status = http.StatusInternalServerError
@@ -95,27 +224,41 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 	if !h.requestValidator.ValidateRequest(c, &req) {
 		return
 	}
-	userID := c.GetHeader("X-User-Id")
 	var accessToken string
 	var refreshToken string
-	err := h.uow.DoTransaction(func(store *stores.UserTokenOutboxStore) error {
-		user, err := h.users.GetByID(store, userID)
+	var reuseDetected bool
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		token, err := h.tokens.VerifyRefreshToken(store, req.RefreshToken)
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			// The family is already revoked; commit that together with the event
+			// and report the reuse once the transaction is done.
+			reuseDetected = true
+			return h.outbox.SaveRefreshTokenReuseDetectedEvent(store, token)
+		}
 		if err != nil {
-			return err
+			return services.ErrInvalidCredentials
+		}
+		// Tokens of OAuth clients are only refreshed at the token endpoint,
+		// where the client authenticates.
+		if token.ClientID != "" {
+			return services.ErrUnauthorizedClient
 		}
 
-		ok, err := h.tokens.VerifyRefreshToken(store, user.ID, req.RefreshToken)
-		if err != nil || !ok {
-			return services.ErrInvalidCredentials
+		user, err := h.users.GetByID(store, token.UserID.String())
+		if err != nil {
+			return err
 		}
 
-		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(store, user)
+		accessToken, refreshToken, err = h.tokens.RotateRefreshToken(store, user, token, clientInfo(c))
 		if err != nil {
 			return err
 		}
 
 		return nil
 	})
+	if err == nil && reuseDetected {
+		err = services.ErrRefreshTokenReused
+	}
 
 	resp := dto.APIResponse{
 		Errors: make(map[string]string),
@@ -127,6 +270,12 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		case errors.Is(err, services.ErrInvalidCredentials):
 			resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
 			status = http.StatusConflict
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			resp.Errors["error"] = "ERR_REFRESH_TOKEN_REUSED"
+			status = http.StatusUnauthorized
+		case errors.Is(err, services.ErrUnauthorizedClient):
+			resp.Errors["error"] = "ERR_INVALID_REFRESH_TOKEN"
+			status = http.StatusUnauthorized
 		default:
 			resp.Errors["error"] = "ERR_INTERNAL"
 			status = http.StatusInternalServerError
@@ -143,3 +292,112 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 
 	c.JSON(status, resp)
 }
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		token, err := h.tokens.RevokeRefreshToken(store, req.RefreshToken)
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedOutEvent(store, services.UserLoggedOutPayload{
+			UserID:    token.UserID,
+			SessionID: &token.SessionID,
+		})
+	})
+	if err == nil {
+		err = revokeCurrentAccessToken(c, h.tokens)
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
+			status = http.StatusUnauthorized
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := currentUserID(c)
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		user, err := h.users.GetByID(store, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := h.tokens.RevokeAllForUser(store, user.ID); err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedOutEvent(store, services.UserLoggedOutPayload{
+			UserID: user.ID,
+		})
+	})
+	if err == nil {
+		err = revokeCurrentAccessToken(c, h.tokens)
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
+			status = http.StatusUnauthorized
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+// revokeCurrentAccessToken denylists the access token the request was
+// authenticated with, so it stops working before it expires.
+func revokeCurrentAccessToken(c *gin.Context, tokens *services.TokenService) error {
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		return nil
+	}
+	return tokens.RevokeAccessToken(c.Request.Context(), claims)
+}
+
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
+
+func currentUserID(c *gin.Context) string {
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		return ""
+	}
+	return claims.UserID
+}
@@ -1,242 +1,555 @@
 package handlers
 
 import (
+	"app/internal/denylists"
 	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
 	"app/internal/mocks"
-	"app/internal/repositories"
+	"app/internal/ratelimits"
 	"app/internal/services"
-	"github.com/google/uuid"
-	"gorm.io/gorm"
+	"app/internal/stores"
+	"app/internal/utils"
+	"app/internal/validators"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-func registerTestValidators() {
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		_ = v.RegisterValidation("uzphone", func(fl validator.FieldLevel) bool {
-			val := fl.Field().String()
-			return strings.HasPrefix(val, "+998") && len(val) == 13
-		})
+// newTxUow returns a UnitOfWork mock that runs every transaction against store.
+func newTxUow(store stores.Store) *mocks.UnitOfWorkMock[stores.Store] {
+	mockUow := new(mocks.UnitOfWorkMock[stores.Store])
+	mockUow.On("DoTransaction", mock.Anything).Return(func(fn func(stores.Store) error) error {
+		return fn(store)
+	})
+	return mockUow
+}
+
+// withPermissions makes the roles of any user grant permissions.
+func withPermissions(store *mocks.StoreMock, permissions ...string) {
+	mockRoleRepo := new(mocks.RoleRepositoryMock)
+	mockRoleRepo.On("PermissionsOf", mock.Anything).Return(permissions, nil)
+	store.On("Roles").Return(mockRoleRepo)
+}
+
+var testPasswordPolicy = &validators.PasswordPolicy{
+	MinLength:          8,
+	MaxLength:          72,
+	MinCharClasses:     2,
+	RejectPersonalInfo: true,
+	Breached:           validators.DefaultBreachedPasswords(),
+}
+
+func newTestRequestValidator() *middlewares.RequestValidator {
+	v := validator.New()
+	if err := testPasswordPolicy.Register(v); err != nil {
+		panic(err)
 	}
+	if err := validators.RegisterPhone(v); err != nil {
+		panic(err)
+	}
+	return middlewares.NewRequestValidator(validators.NewValidator(v))
+}
+
+func newTestAuthHandler(store stores.Store, hasher utils.PasswordHasher, jwtHelper utils.JWTHelper) *AuthHandler {
+	return NewAuthHandler(
+		newTxUow(store),
+		newTestRequestValidator(),
+		middlewares.NewAccessTokenVerifier(jwtHelper, denylists.NewMemoryDenylist(), false),
+		nil,
+		services.NewUserService(hasher, nil, false),
+		services.NewTokenService(utils.NewTokenGenerator(), jwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		nil,
+		services.NewOutboxService(),
+	)
 }
 
-func TestGinAuthHandler_Register(t *testing.T) {
-	registerTestValidators()
+func performRequest(r http.Handler, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Invalid credentials", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockHasher := new(mocks.PasswordHasherMock)
 
-	t.Run("Invalid JSON", func(t *testing.T) {
-		r := gin.Default()
-		handler := &GinAuthHandler{authService: nil}
-		r.POST("/register", handler.Register)
+		mockStore.On("Users").Return(mockUserRepo)
+		mockUserRepo.On("GetByEmail", "john@example.com").Return(nil, nil)
 
-		w := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/register", strings.NewReader(`{"phone": "123"`))
-		req.Header.Set("Content-Type", "application/json")
+		r := gin.New()
+		newTestAuthHandler(mockStore, mockHasher, nil).BindRoutes(r.Group("/auth"))
 
-		r.ServeHTTP(w, req)
+		w := performRequest(r, "POST", "/auth/login",
+			`{"email": "john@example.com", "password": "password123"}`, nil)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
-		assert.Contains(t, w.Body.String(), `"success":false`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_CREDENTIALS")
 	})
 
-	t.Run("User already exists", func(t *testing.T) {
-		mockUow := new(mocks.UnitOfWorkMock)
+	t.Run("Success", func(t *testing.T) {
 		mockStore := new(mocks.StoreMock)
 		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
 		mockEventRepo := new(mocks.EventRepositoryMock)
 		mockHasher := new(mocks.PasswordHasherMock)
 		mockJwtHelper := new(mocks.JWTHelperMock)
 
-		mockUow.On("Store").Return(mockStore)
 		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
 		mockStore.On("Outbox").Return(mockEventRepo)
 
-		handler := &GinAuthHandler{authService: services.NewAuthService(mockUow, mockHasher, mockJwtHelper)}
-
-		mockUserRepo.On("GetByPhone", "+998901234567").Return(&domain.User{}, nil)
+		user := &domain.User{
+			ID:       userID(),
+			Email:    "john@example.com",
+			Password: "hashed_password",
+			Roles:    []domain.UserRole{{Role: domain.RoleCustomer}},
+		}
 
-		r := gin.Default()
-		r.POST("/register", handler.Register)
+		mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+		mockHasher.On("Verify", "password123", "hashed_password").Return(true)
+		mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+		withPermissions(mockStore)
+		mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+		mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+		mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil)
 
-		w := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/register",
-			strings.NewReader(`{"phone": "+998901234567", "password": "password123", "name": "Jamol", "surname": "Jackson"}`))
-		req.Header.Set("Content-Type", "application/json")
+		r := gin.New()
+		newTestAuthHandler(mockStore, mockHasher, mockJwtHelper).BindRoutes(r.Group("/auth"))
 
-		r.ServeHTTP(w, req)
+		w := performRequest(r, "POST", "/auth/login",
+			`{"email": "john@example.com", "password": "password123"}`, nil)
 
-		assert.Equal(t, http.StatusConflict, w.Code)
-		assert.Contains(t, w.Body.String(), "already exists")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "jwt_token")
+		mockEventRepo.AssertExpectations(t)
 	})
+}
 
-	t.Run("Success", func(t *testing.T) {
-		mockUow := new(mocks.UnitOfWorkMock)
+func TestAuthHandler_Login_Lockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := services.LockoutPolicy{AccountThreshold: 2, Cooldown: time.Minute, MaxCooldown: time.Hour}
+	newHandler := func(store stores.Store, hasher utils.PasswordHasher) *AuthHandler {
+		return NewAuthHandler(
+			newTxUow(store),
+			newTestRequestValidator(),
+			nil,
+			nil,
+			services.NewUserService(hasher, nil, false),
+			nil,
+			services.NewLockoutService(policy),
+			nil,
+			services.NewOutboxService(),
+		)
+	}
+
+	t.Run("Locks after repeated failures", func(t *testing.T) {
 		mockStore := new(mocks.StoreMock)
 		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockLockoutRepo := new(mocks.LoginLockoutRepositoryMock)
 		mockEventRepo := new(mocks.EventRepositoryMock)
 		mockHasher := new(mocks.PasswordHasherMock)
-		mockJwtHelper := new(mocks.JWTHelperMock)
 
-		mockUow.On("Store").Return(mockStore)
+		user := &domain.User{ID: userID(), Email: "john@example.com", Password: "hashed_password"}
+		lockouts := make(map[string]domain.LoginLockout)
+
 		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("LoginLockouts").Return(mockLockoutRepo)
 		mockStore.On("Outbox").Return(mockEventRepo)
+		mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+		mockHasher.On("Verify", "wrong-password", "hashed_password").Return(false)
+		mockLockoutRepo.On("Get", mock.Anything).Return(func(key string) (*domain.LoginLockout, error) {
+			if lockout, ok := lockouts[key]; ok {
+				return &lockout, nil
+			}
+			return nil, nil
+		})
+		mockLockoutRepo.On("Save", mock.Anything).Return(func(lockout *domain.LoginLockout) error {
+			lockouts[lockout.Key] = *lockout
+			return nil
+		})
+		mockEventRepo.On("Save", services.AccountLocked, mock.MatchedBy(func(payload *services.AccountLockedPayload) bool {
+			return payload.UserID == userID()
+		})).Return(nil).Once()
+
+		r := gin.New()
+		newHandler(mockStore, mockHasher).BindRoutes(r.Group("/auth"))
+		body := `{"email": "john@example.com", "password": "wrong-password"}`
+
+		first := performRequest(r, "POST", "/auth/login", body, nil)
+		second := performRequest(r, "POST", "/auth/login", body, nil)
+		third := performRequest(r, "POST", "/auth/login", body, nil)
+
+		assert.Equal(t, http.StatusConflict, first.Code)
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Contains(t, second.Body.String(), "ERR_ACCOUNT_LOCKED")
+		assert.Equal(t, "60", second.Header().Get("Retry-After"))
+		assert.Equal(t, http.StatusTooManyRequests, third.Code)
+		assert.Contains(t, third.Body.String(), "ERR_ACCOUNT_LOCKED")
+		assert.NotEmpty(t, third.Header().Get("Retry-After"))
+		mockHasher.AssertNumberOfCalls(t, "Verify", 2)
+		mockEventRepo.AssertExpectations(t)
+	})
+}
 
-		handler := &GinAuthHandler{authService: services.NewAuthService(mockUow, mockHasher, mockJwtHelper)}
+func TestAuthHandler_Login_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
 
-		mockUserRepo.On("GetByPhone", "+998901234567").Return(nil, nil)
-		mockUserRepo.On("Save", mock.Anything).Return(nil)
-		mockEventRepo.On("Save", "UserRegistered", mock.Anything).Return(nil)
-		mockHasher.On("Hash", "password123").Return("hashed_password")
-		mockUow.On("DoRegistration", mock.Anything).Run(func(args mock.Arguments) {
-			cb := args.Get(0).(func(repositories.UserRepository, repositories.EventRepository) error)
-			_ = cb(mockUserRepo, mockEventRepo)
-		}).Return(nil)
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
 
-		r := gin.Default()
-		r.POST("/register", handler.Register)
+	mockStore.On("Users").Return(mockUserRepo)
+	mockUserRepo.On("GetByEmail", mock.Anything).Return(nil, nil)
 
-		w := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/register",
-			strings.NewReader(`{"phone": "+998901234567", "password": "password123", "name": "Jamol", "surname": "Jackson"}`))
-		req.Header.Set("Content-Type", "application/json")
+	rateLimiter := middlewares.NewRateLimiter(ratelimits.NewMemoryStore(), map[string][]middlewares.RateLimitRule{
+		"login": {
+			{Key: middlewares.RateLimitByIP, Limit: 10, Window: time.Minute},
+			{Key: middlewares.RateLimitByEmail, Limit: 2, Window: time.Minute},
+		},
+	})
+
+	r := gin.New()
+	NewAuthHandler(
+		newTxUow(mockStore),
+		newTestRequestValidator(),
+		nil,
+		rateLimiter,
+		services.NewUserService(nil, nil, false),
+		nil,
+		services.NewLockoutService(services.LockoutPolicy{}),
+		nil,
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+
+	login := func(email string) *httptest.ResponseRecorder {
+		return performRequest(r, "POST", "/auth/login",
+			`{"email": "`+email+`", "password": "password123"}`, nil)
+	}
+
+	first := login("john@example.com")
+	assert.Equal(t, http.StatusConflict, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, first.Header().Get("RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusConflict, login("John@Example.com").Code)
 
-		r.ServeHTTP(w, req)
+	limited := login("john@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Contains(t, limited.Body.String(), "ERR_RATE_LIMITED")
+	assert.Equal(t, "0", limited.Header().Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, limited.Header().Get("Retry-After"))
+
+	// Other emails have their own limit, the body still reaches the handler.
+	other := login("jane@example.com")
+	assert.Equal(t, http.StatusConflict, other.Code)
+	assert.Contains(t, other.Body.String(), "ERR_INVALID_CREDENTIALS")
+}
+
+func TestAuthHandler_LoginMFA(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockCodeRepo := new(mocks.RecoveryCodeRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	confirmedAt := time.Now()
+	user := &domain.User{ID: userID(), Email: "john@example.com", Password: "hashed_password"}
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockStore.On("RecoveryCodes").Return(mockCodeRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockCodeRepo.On("GetUnused", user.ID, mock.Anything).Return(nil, nil)
+	mockHasher.On("Verify", "password123", "hashed_password").Return(true)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+	mockCredentialRepo.On("Get", user.ID).
+		Return(&domain.TOTPCredential{UserID: user.ID, Secret: secret, ConfirmedAt: &confirmedAt}, nil)
+	mockCredentialRepo.On("Save", mock.Anything).Return(nil)
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil).Once()
+
+	totp := utils.NewTOTP()
+	r := gin.New()
+	NewAuthHandler(
+		newTxUow(mockStore),
+		newTestRequestValidator(),
+		nil,
+		nil,
+		services.NewUserService(mockHasher, nil, false),
+		services.NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		services.NewMFAService(totp, testTokenSigner, "Shop", 5*time.Minute),
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+
+	w := performRequest(r, "POST", "/auth/login",
+		`{"email": "john@example.com", "password": "password123"}`, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "jwt_token")
+	var challenge struct {
+		Data dto.MFAChallengeResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &challenge))
+	assert.True(t, challenge.Data.MFARequired)
+	require.NotEmpty(t, challenge.Data.MFAToken)
 
-		assert.Equal(t, http.StatusCreated, w.Code)
-		assert.Contains(t, w.Body.String(), "+998901234567")
+	t.Run("Invalid challenge", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/login/mfa", `{"mfa_token": "forged", "code": "123456"}`, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/login/mfa",
+			`{"mfa_token": "`+challenge.Data.MFAToken+`", "code": "not-a-code"}`, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_MFA_CODE")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		key, _ := utils.DecodeTOTPSecret(secret)
+		code := totp.Code(key, totp.Step(time.Now()))
+
+		w := performRequest(r, "POST", "/auth/login/mfa",
+			`{"mfa_token": "`+challenge.Data.MFAToken+`", "code": "`+code+`"}`, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "jwt_token")
+		mockEventRepo.AssertExpectations(t)
 	})
 }
 
-func TestGinAuthHandler_Login(t *testing.T) {
-	registerTestValidators()
+func TestAuthHandler_Refresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
 
-	t.Run("Invalid credentials", func(t *testing.T) {
-		mockUow := new(mocks.UnitOfWorkMock)
+	t.Run("Invalid credentials - unknown token", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockTokenRepo.On("GetByHash", utils.HashToken("some_token")).Return(nil, nil)
+
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/refresh", `{"refresh_token": "some_token"}`, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_CREDENTIALS")
+	})
+
+	t.Run("Success", func(t *testing.T) {
 		mockStore := new(mocks.StoreMock)
 		mockUserRepo := new(mocks.UserRepositoryMock)
-		mockHasher := new(mocks.PasswordHasherMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
 		mockJwtHelper := new(mocks.JWTHelperMock)
 
-		mockUow.On("Store").Return(mockStore)
 		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
 
-		handler := &GinAuthHandler{authService: services.NewAuthService(mockUow, mockHasher, mockJwtHelper)}
+		token := &domain.Token{
+			ID:        1,
+			UserID:    userID(),
+			SessionID: uuid.New(),
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		user := &domain.User{ID: userID()}
 
-		// User not found
-		mockUserRepo.On("GetByPhone", "+998901234567").Return(nil, gorm.ErrRecordNotFound)
+		mockTokenRepo.On("GetByHash", utils.HashToken("refresh")).Return(token, nil)
+		mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+		mockUserRepo.On("GetByID", userID()).Return(user, nil)
+		withPermissions(mockStore)
+		mockJwtHelper.On("GenerateAccessToken", userID().String(), []string{}, []string(nil)).Return("new_access_token", nil)
 
-		r := gin.Default()
-		r.POST("/login", handler.Login)
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, mockJwtHelper).BindRoutes(r.Group("/auth"))
 
-		w := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/login",
-			strings.NewReader(`{"phone": "+998901234567", "password": "password123"}`))
-		req.Header.Set("Content-Type", "application/json")
+		w := performRequest(r, "POST", "/auth/refresh", `{"refresh_token": "refresh"}`, nil)
 
-		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "new_access_token")
+		assert.NotNil(t, token.RotatedAt)
+	})
+
+	t.Run("Token of an OAuth client", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		token := &domain.Token{
+			ID:        1,
+			UserID:    userID(),
+			SessionID: uuid.New(),
+			ClientID:  "orders",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		mockTokenRepo.On("GetByHash", utils.HashToken("client_refresh")).Return(token, nil)
+
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/refresh", `{"refresh_token": "client_refresh"}`, nil)
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
-		assert.Contains(t, w.Body.String(), "invalid credentials")
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_REFRESH_TOKEN")
+		assert.Nil(t, token.RotatedAt)
+		mockTokenRepo.AssertNotCalled(t, "Save", mock.Anything)
 	})
 
-	t.Run("Success", func(t *testing.T) {
-		mockUow := new(mocks.UnitOfWorkMock)
+	t.Run("Reused token", func(t *testing.T) {
 		mockStore := new(mocks.StoreMock)
-		mockUserRepo := new(mocks.UserRepositoryMock)
-		mockEventRepo := new(mocks.EventRepositoryMock)
 		mockTokenRepo := new(mocks.TokenRepositoryMock)
-		mockHasher := new(mocks.PasswordHasherMock)
-		mockJwtHelper := new(mocks.JWTHelperMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
 
-		mockUow.On("Store").Return(mockStore)
-		mockStore.On("Users").Return(mockUserRepo)
 		mockStore.On("Tokens").Return(mockTokenRepo)
 		mockStore.On("Outbox").Return(mockEventRepo)
 
-		handler := &GinAuthHandler{
-			authService: services.NewAuthService(mockUow, mockHasher, mockJwtHelper),
+		rotatedAt := time.Now()
+		token := &domain.Token{
+			ID:        1,
+			UserID:    userID(),
+			SessionID: uuid.New(),
+			ExpiresAt: time.Now().Add(time.Hour),
+			RotatedAt: &rotatedAt,
 		}
 
-		user := &domain.User{
-			ID:       userID(),
-			Phone:    "+998901234567",
-			Password: "hashed_password",
-			Roles:    []domain.UserRole{{Role: "customer"}},
-		}
+		mockTokenRepo.On("GetByHash", utils.HashToken("stolen_token")).Return(token, nil)
+		mockTokenRepo.On("RevokeSession", token.SessionID).Return(nil)
+		mockEventRepo.On("Save", services.RefreshTokenReuseDetected, token).Return(nil)
 
-		mockUserRepo.On("GetByPhone", "+998901234567").Return(user, nil)
-		mockHasher.On("Verify", "password123", "hashed_password").Return(true)
-		mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything).Return("jwt_token", nil)
-		mockHasher.On("Hash", mock.Anything).Return("hashed_refresh_token")
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
 
-		// Mock Save on tokenRepo and EventRepo
-		mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
-		mockEventRepo.On("Save", "UserLoggedIn", user).Return(nil)
+		w := performRequest(r, "POST", "/auth/refresh", `{"refresh_token": "stolen_token"}`, nil)
 
-		// Use interface types in callback
-		mockUow.On("DoLogin", mock.Anything).Run(func(args mock.Arguments) {
-			cb := args.Get(0).(func(repositories.TokenRepository, repositories.EventRepository) error)
-			_ = cb(mockTokenRepo, mockEventRepo)
-		}).Return(nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_REFRESH_TOKEN_REUSED")
+		mockTokenRepo.AssertExpectations(t)
+		mockEventRepo.AssertExpectations(t)
+	})
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Unknown token", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockTokenRepo.On("GetByHash", utils.HashToken("unknown")).Return(nil, nil)
 
-		r := gin.Default()
-		r.POST("/login", handler.Login)
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
 
-		w := httptest.NewRecorder()
-		req := httptest.NewRequest("POST", "/login",
-			strings.NewReader(`{"phone": "+998901234567", "password": "password123"}`))
-		req.Header.Set("Content-Type", "application/json")
+		w := performRequest(r, "POST", "/auth/logout", `{"refresh_token": "unknown"}`, nil)
 
-		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_CREDENTIALS")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+
+		token := &domain.Token{ID: 1, UserID: userID(), SessionID: uuid.New()}
+		mockTokenRepo.On("GetByHash", utils.HashToken("refresh")).Return(token, nil)
+		mockTokenRepo.On("RevokeSession", token.SessionID).Return(nil)
+		mockEventRepo.On("Save", services.UserLoggedOut, services.UserLoggedOutPayload{
+			UserID:    userID(),
+			SessionID: &token.SessionID,
+		}).Return(nil)
+
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/logout", `{"refresh_token": "refresh"}`, nil)
 
 		assert.Equal(t, http.StatusOK, w.Code)
-		assert.Contains(t, w.Body.String(), "jwt_token")
+		mockTokenRepo.AssertExpectations(t)
+		mockEventRepo.AssertExpectations(t)
 	})
 }
 
-func TestGinAuthHandler_Refresh(t *testing.T) {
-	registerTestValidators()
+func TestAuthHandler_LogoutAll(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Missing access token", func(t *testing.T) {
+		r := gin.New()
+		newTestAuthHandler(nil, nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/logout-all", "", map[string]string{"X-User-Id": userID().String()})
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_UNAUTHORIZED")
+	})
 
-	t.Run("Invalid credentials - user not found", func(t *testing.T) {
-		mockUow := new(mocks.UnitOfWorkMock)
+	t.Run("Success", func(t *testing.T) {
 		mockStore := new(mocks.StoreMock)
 		mockUserRepo := new(mocks.UserRepositoryMock)
 		mockTokenRepo := new(mocks.TokenRepositoryMock)
-		mockHasher := new(mocks.PasswordHasherMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
 		mockJwtHelper := new(mocks.JWTHelperMock)
 
-		mockUow.On("Store").Return(mockStore)
 		mockStore.On("Users").Return(mockUserRepo)
 		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
 
-		handler := &GinAuthHandler{
-			authService: services.NewAuthService(mockUow, mockHasher, mockJwtHelper),
-		}
-
-		userID := uuid.New()
-		mockUserRepo.On("GetByID", userID).Return(nil, gorm.ErrRecordNotFound)
-
-		r := gin.Default()
-		r.POST("/refresh", handler.Refresh)
+		mockJwtHelper.On("ParseAccessToken", "access").Return(&utils.Claims{UserID: userID().String()}, nil)
+		mockUserRepo.On("GetByID", userID()).Return(&domain.User{ID: userID()}, nil)
+		mockTokenRepo.On("RevokeByUser", userID()).Return(nil)
+		mockEventRepo.On("Save", services.UserLoggedOut, services.UserLoggedOutPayload{UserID: userID()}).Return(nil)
 
-		// JSON body only needs refreshToken
-		body := `{"refreshToken":"some_token"}`
-		req := httptest.NewRequest("POST", "/refresh", strings.NewReader(body))
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("X-User-Id", userID.String()) // ✅ pass userId in header
+		r := gin.New()
+		newTestAuthHandler(mockStore, nil, mockJwtHelper).BindRoutes(r.Group("/auth"))
 
-		w := httptest.NewRecorder()
-		r.ServeHTTP(w, req)
+		w := performRequest(r, "POST", "/auth/logout-all", "", map[string]string{"Authorization": "Bearer access"})
 
-		assert.Equal(t, http.StatusUnauthorized, w.Code)
-		assert.Contains(t, w.Body.String(), "invalid credentials")
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokenRepo.AssertExpectations(t)
+		mockEventRepo.AssertExpectations(t)
 	})
 }
 
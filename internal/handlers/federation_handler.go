@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"app/internal/dto"
+	"app/internal/federation"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const federationStateCookie = "federation_state"
+
+// FederationHandler logs users in with an account at an external OpenID
+// Connect provider, such as Google or Apple.
+type FederationHandler struct {
+	uow         uows.UnitOfWork[stores.Store]
+	rateLimiter *middlewares.RateLimiter
+	tokens      *services.TokenService
+	lockouts    *services.LockoutService
+	mfa         *services.MFAService
+	federation  *services.FederationService
+	outbox      *services.UserTokenOutboxService
+	// secureCookie marks the state cookie Secure and lets it travel on the
+	// cross-site POST of providers answering with form_post.
+	secureCookie bool
+}
+
+func NewFederationHandler(
+	uow uows.UnitOfWork[stores.Store],
+	rateLimiter *middlewares.RateLimiter,
+	tokens *services.TokenService,
+	lockouts *services.LockoutService,
+	mfa *services.MFAService,
+	federation *services.FederationService,
+	outbox *services.UserTokenOutboxService,
+	secureCookie bool,
+) *FederationHandler {
+	return &FederationHandler{
+		uow:          uow,
+		rateLimiter:  rateLimiter,
+		tokens:       tokens,
+		lockouts:     lockouts,
+		mfa:          mfa,
+		federation:   federation,
+		outbox:       outbox,
+		secureCookie: secureCookie,
+	}
+}
+
+func (h *FederationHandler) BindRoutes(r *gin.RouterGroup) {
+	limit := h.rateLimiter.Limit("federation")
+	r.GET("/federation/:provider/authorize", limit, h.Authorize)
+	r.GET("/federation/:provider/callback", limit, h.Callback)
+	r.POST("/federation/:provider/callback", limit, h.Callback)
+}
+
+// Authorize redirects the user to the provider. The state of the login is
+// kept in a cookie until the callback.
+func (h *FederationHandler) Authorize(c *gin.Context) {
+	authURL, stateToken, err := h.federation.Begin(c.Request.Context(), c.Param("provider"))
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	if err != nil {
+		status := setFederationError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	h.setStateCookie(c, stateToken, 0)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the login the provider sent the user back from. The
+// first login with an identity links it to the account with the same
+// verified email, or registers a customer. Users with two-factor
+// authentication get an MFA challenge, as from Login.
+func (h *FederationHandler) Callback(c *gin.Context) {
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	stateToken, _ := c.Cookie(federationStateCookie)
+	h.setStateCookie(c, "", -1)
+
+	if c.Request.FormValue("error") != "" {
+		resp.Errors["error"] = "ERR_FEDERATION_DENIED"
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	providerName := c.Param("provider")
+	identity, err := h.federation.Complete(
+		c.Request.Context(),
+		providerName,
+		stateToken,
+		c.Request.FormValue("state"),
+		c.Request.FormValue("code"),
+	)
+	if err != nil {
+		status := setFederationError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+	applyAppleUser(identity, c.Request.PostFormValue("user"))
+
+	var accessToken string
+	var refreshToken string
+	var mfaToken string
+	err = h.uow.DoTransaction(func(txStore stores.Store) error {
+		login, err := h.federation.Login(txStore, providerName, identity)
+		if err != nil {
+			return err
+		}
+		user := login.User
+
+		if login.Created {
+			if err := h.outbox.SaveUserRegisteredEvent(txStore, user); err != nil {
+				return err
+			}
+		}
+		if login.Reclaimed {
+			if err := h.tokens.RevokeAllAccessTokens(c.Request.Context(), user.ID); err != nil {
+				return err
+			}
+		}
+		if login.Linked {
+			err := h.outbox.SaveIdentityLinkedEvent(txStore, services.IdentityLinkedPayload{
+				UserID:   user.ID,
+				Provider: providerName,
+				Email:    identity.Email,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := h.lockouts.Check(txStore, user.Email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		mfaEnabled, err := h.mfa.Enabled(txStore, user.ID)
+		if err != nil {
+			return err
+		}
+		if mfaEnabled {
+			mfaToken, err = h.mfa.Challenge(user)
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(txStore, user, clientInfo(c))
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedInEvent(txStore, user)
+	})
+
+	status := http.StatusOK
+
+	if err != nil {
+		if errors.Is(err, services.ErrAccountLocked) {
+			setRetryAfter(c, err)
+		}
+		status = setFederationError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	if mfaToken != "" {
+		resp.Data = dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+	} else {
+		resp.Data = dto.TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}
+	}
+
+	c.JSON(status, resp)
+}
+
+// setStateCookie scopes the cookie to the routes of the handler. Providers
+// posting the callback do it cross-site, which only SameSite=None cookies
+// survive, and browsers only accept those when Secure.
+func (h *FederationHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	path, _, _ := strings.Cut(c.FullPath(), "/:provider")
+
+	if h.secureCookie {
+		c.SetSameSite(http.SameSiteNoneMode)
+	} else {
+		c.SetSameSite(http.SameSiteLaxMode)
+	}
+	c.SetCookie(federationStateCookie, value, maxAge, path, "", h.secureCookie, true)
+}
+
+// applyAppleUser takes the name of the user from the user field Apple posts
+// on the first login only, since its ID tokens never carry it.
+func applyAppleUser(identity *federation.Identity, user string) {
+	if user == "" {
+		return
+	}
+
+	var appleUser struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(user), &appleUser); err != nil {
+		return
+	}
+	if identity.GivenName == "" {
+		identity.GivenName = appleUser.Name.FirstName
+	}
+	if identity.FamilyName == "" {
+		identity.FamilyName = appleUser.Name.LastName
+	}
+}
+
+func setFederationError(resp dto.APIResponse, err error) int {
+	switch {
+	case errors.Is(err, federation.ErrUnknownProvider):
+		resp.Errors["error"] = "ERR_UNKNOWN_PROVIDER"
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidToken):
+		resp.Errors["error"] = "ERR_INVALID_STATE"
+		return http.StatusBadRequest
+	case errors.Is(err, federation.ErrInvalidIDToken):
+		resp.Errors["error"] = "ERR_INVALID_ID_TOKEN"
+		return http.StatusUnauthorized
+	case errors.Is(err, federation.ErrProvider):
+		resp.Errors["error"] = "ERR_PROVIDER_UNAVAILABLE"
+		return http.StatusBadGateway
+	case errors.Is(err, services.ErrIdentityEmailNotVerified):
+		resp.Errors["error"] = "ERR_EMAIL_NOT_VERIFIED"
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAccountLocked):
+		resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+		return http.StatusTooManyRequests
+	default:
+		resp.Errors["error"] = "ERR_INTERNAL"
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/federation"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/utils"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIdentityProvider is an OpenID Connect provider that lets in whoever
+// its authorize URL is followed for, as the user with its claims.
+type fakeIdentityProvider struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	claims     jwt.MapClaims
+	challenges map[string]string
+	nonces     map[string]string
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeIdentityProvider{key: key, challenges: map[string]string{}, nonces: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.URL,
+			"authorization_endpoint": f.URL + "/authorize",
+			"token_endpoint":         f.URL + "/token",
+			"jwks_uri":               f.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, err := utils.NewJWK(&f.key.PublicKey, "key-1")
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(utils.JWKS{Keys: []utils.JWK{jwk}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		code := r.PostFormValue("code")
+		challenge, ok := f.challenges[code]
+		if !ok || !utils.VerifyPKCE(r.PostFormValue("code_verifier"), challenge) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(f.challenges, code)
+
+		claims := jwt.MapClaims{
+			"iss":   f.URL,
+			"aud":   "client-123",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": f.nonces[code],
+		}
+		for name, value := range f.claims {
+			claims[name] = value
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key-1"
+		idToken, err := token.SignedString(f.key)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// authorize follows the authorize URL and returns the query of the callback.
+func (f *fakeIdentityProvider) authorize(t *testing.T, location string) url.Values {
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(location, f.URL+"/authorize?"))
+
+	code := "code-" + u.Query().Get("state")
+	f.challenges[code] = u.Query().Get("code_challenge")
+	f.nonces[code] = u.Query().Get("nonce")
+	return url.Values{"code": {code}, "state": {u.Query().Get("state")}}
+}
+
+func TestFederationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	provider := newFakeIdentityProvider(t)
+	provider.claims = jwt.MapClaims{"sub": "subject-1", "email": "jane@example.com", "email_verified": true, "given_name": "Jane"}
+
+	var user *domain.User
+	var linked *domain.UserIdentity
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("UserIdentities").Return(mockIdentityRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockIdentityRepo.On("GetByProviderSubject", "acme", "subject-1").Return(func(_, _ string) (*domain.UserIdentity, error) {
+		return linked, nil
+	})
+	mockIdentityRepo.On("Save", mock.AnythingOfType("*domain.UserIdentity")).
+		Run(func(args mock.Arguments) { linked = args.Get(0).(*domain.UserIdentity) }).
+		Return(nil)
+	mockUserRepo.On("GetByEmail", "jane@example.com").Return(nil, nil)
+	mockUserRepo.On("Save", mock.AnythingOfType("*domain.User")).
+		Run(func(args mock.Arguments) {
+			user = args.Get(0).(*domain.User)
+			user.ID = userID()
+		}).
+		Return(nil)
+	mockUserRepo.On("GetByID", userID()).Return(func(uuid.UUID) (*domain.User, error) { return user, nil })
+	mockCredentialRepo.On("Get", userID()).Return(nil, nil)
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", userID().String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.UserRegistered, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+	mockEventRepo.On("Save", services.IdentityLinked, mock.AnythingOfType("services.IdentityLinkedPayload")).Return(nil).Once()
+	mockEventRepo.On("Save", services.UserLoggedIn, mock.AnythingOfType("*domain.User")).Return(nil).Times(3)
+
+	registry := federation.NewRegistry(federation.NewProvider(federation.Config{
+		Name: "acme", Issuer: provider.URL, ClientID: "client-123", ClientSecret: "secret",
+	}, provider.Client()))
+
+	r := gin.New()
+	NewFederationHandler(
+		newTxUow(mockStore),
+		nil,
+		services.NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		services.NewMFAService(utils.NewTOTP(), testTokenSigner, "Shop", 5*time.Minute),
+		services.NewFederationService(
+			registry, testTokenSigner, utils.NewTokenGenerator(), "https://auth.example.com/auth/federation", 10*time.Minute,
+		),
+		services.NewOutboxService(),
+		true,
+	).BindRoutes(r.Group("/auth"))
+
+	authorize := func(t *testing.T) (url.Values, *http.Cookie) {
+		w := performRequest(r, "GET", "/auth/federation/acme/authorize", "", nil)
+		require.Equal(t, http.StatusFound, w.Code)
+
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, "/auth/federation", cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+
+		location := w.Header().Get("Location")
+		u, err := url.Parse(location)
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example.com/auth/federation/acme/callback", u.Query().Get("redirect_uri"))
+		return provider.authorize(t, location), cookies[0]
+	}
+
+	callback := func(method string, query url.Values, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/auth/federation/acme/callback?"+query.Encode(), nil)
+		if method == http.MethodPost {
+			req = httptest.NewRequest(method, "/auth/federation/acme/callback", strings.NewReader(query.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		w := performRequest(r, "GET", "/auth/federation/facebook/authorize", "", nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_UNKNOWN_PROVIDER")
+	})
+
+	t.Run("State mismatch", func(t *testing.T) {
+		query, cookie := authorize(t)
+		query.Set("state", "forged")
+
+		w := callback(http.MethodGet, query, cookie)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_STATE")
+	})
+
+	t.Run("Missing cookie", func(t *testing.T) {
+		query, _ := authorize(t)
+
+		w := callback(http.MethodGet, query, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_STATE")
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		_, cookie := authorize(t)
+
+		w := callback(http.MethodGet, url.Values{"error": {"access_denied"}}, cookie)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_FEDERATION_DENIED")
+	})
+
+	t.Run("First login", func(t *testing.T) {
+		query, cookie := authorize(t)
+
+		w := callback(http.MethodGet, query, cookie)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body struct {
+			Data dto.TokenResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "jwt_token", body.Data.AccessToken)
+		assert.NotEmpty(t, body.Data.RefreshToken)
+		assert.Equal(t, "Jane", user.Name)
+		assert.Equal(t, []string{domain.RoleCustomer}, user.RoleNames())
+		assert.Equal(t, "acme", linked.Provider)
+	})
+
+	t.Run("Replayed code", func(t *testing.T) {
+		query, cookie := authorize(t)
+		require.Equal(t, http.StatusOK, callback(http.MethodGet, query, cookie).Code)
+
+		w := callback(http.MethodGet, query, cookie)
+
+		assert.Equal(t, http.StatusBadGateway, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_PROVIDER_UNAVAILABLE")
+	})
+
+	t.Run("Form post with linked identity", func(t *testing.T) {
+		query, cookie := authorize(t)
+
+		w := callback(http.MethodPost, query, cookie)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotNil(t, linked.LastLoginAt)
+		mockEventRepo.AssertExpectations(t)
+	})
+}
@@ -1,23 +1,36 @@
 package handlers
 
 import (
+	"app/internal/dto"
+	"app/internal/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type JwksHandler struct {
-	jwks string
+	keyring *utils.Keyring
 }
 
-func NewJwksHandler(jwks string) *JwksHandler {
-	return &JwksHandler{jwks: jwks}
+func NewJwksHandler(keyring *utils.Keyring) *JwksHandler {
+	return &JwksHandler{keyring: keyring}
 }
 
 func (h *JwksHandler) BindRoutes(r *gin.RouterGroup) {
 	r.GET("/.well-known/jwks.json", h.JWKS)
 }
 
+// JWKS publishes every key of the keyring: the active one, the next one waiting
+// for promotion and the retired ones that still verify tokens in flight.
 func (h *JwksHandler) JWKS(c *gin.Context) {
-	c.Data(http.StatusOK, "application/json", []byte(h.jwks))
+	jwks, err := h.keyring.JWKS()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Errors: map[string]string{"error": "ERR_INTERNAL"},
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/json", jwks)
 }
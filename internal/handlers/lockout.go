@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recordLoginFailure counts a failed login in a transaction of its own, since
+// the transaction of the login is rolled back. It returns the lock the failure
+// started, if any.
+func recordLoginFailure(
+	c *gin.Context,
+	uow uows.UnitOfWork[stores.Store],
+	lockouts *services.LockoutService,
+	outbox *services.UserTokenOutboxService,
+	email string,
+) (*services.LockedError, error) {
+	var locked *services.LockedError
+	err := uow.DoTransaction(func(store stores.Store) error {
+		var payload *services.AccountLockedPayload
+		var err error
+		locked, payload, err = lockouts.RecordFailure(store, email, c.ClientIP())
+		if err != nil || payload == nil {
+			return err
+		}
+
+		return outbox.SaveAccountLockedEvent(store, payload)
+	})
+	return locked, err
+}
+
+// loginFailed records err when it is a wrong password or two-factor code and
+// returns the error to report: the lock the failure started, or err itself.
+func loginFailed(
+	c *gin.Context,
+	uow uows.UnitOfWork[stores.Store],
+	lockouts *services.LockoutService,
+	outbox *services.UserTokenOutboxService,
+	email string,
+	err error,
+) error {
+	if !errors.Is(err, services.ErrInvalidCredentials) && !errors.Is(err, services.ErrInvalidMFACode) {
+		return err
+	}
+
+	locked, recordErr := recordLoginFailure(c, uow, lockouts, outbox, email)
+	if recordErr != nil {
+		return recordErr
+	}
+	if locked != nil {
+		return locked
+	}
+	return err
+}
+
+// setRetryAfter tells the client when to try again if err is a lock.
+func setRetryAfter(c *gin.Context, err error) {
+	var locked *services.LockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter().Seconds())))
+	}
+}
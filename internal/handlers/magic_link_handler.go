@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MagicLinkHandler logs users in with a link sent by email instead of a
+// password.
+type MagicLinkHandler struct {
+	uow              uows.UnitOfWork[stores.Store]
+	requestValidator *middlewares.RequestValidator
+	rateLimiter      *middlewares.RateLimiter
+	tokens           *services.TokenService
+	lockouts         *services.LockoutService
+	mfa              *services.MFAService
+	magicLinks       *services.MagicLinkService
+	outbox           *services.UserTokenOutboxService
+}
+
+func NewMagicLinkHandler(
+	uow uows.UnitOfWork[stores.Store],
+	requestValidator *middlewares.RequestValidator,
+	rateLimiter *middlewares.RateLimiter,
+	tokens *services.TokenService,
+	lockouts *services.LockoutService,
+	mfa *services.MFAService,
+	magicLinks *services.MagicLinkService,
+	outbox *services.UserTokenOutboxService,
+) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		uow:              uow,
+		requestValidator: requestValidator,
+		rateLimiter:      rateLimiter,
+		tokens:           tokens,
+		lockouts:         lockouts,
+		mfa:              mfa,
+		magicLinks:       magicLinks,
+		outbox:           outbox,
+	}
+}
+
+func (h *MagicLinkHandler) BindRoutes(r *gin.RouterGroup) {
+	r.POST("/magic-link", h.rateLimiter.Limit("magic-link"), h.RequestLink)
+	r.POST("/magic-link/consume", h.rateLimiter.Limit("magic-link-consume"), h.Consume)
+}
+
+// RequestLink answers the same way whether or not the email belongs to an
+// account, so it cannot be used to probe for accounts.
+func (h *MagicLinkHandler) RequestLink(c *gin.Context) {
+	var req dto.MagicLinkRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		link, err := h.magicLinks.RequestLink(txStore, req.Email)
+		if err != nil || link == nil {
+			return err
+		}
+
+		return h.outbox.SaveMagicLinkRequestedEvent(txStore, link)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	if err != nil {
+		resp.Errors["error"] = "ERR_INTERNAL"
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(http.StatusOK, resp)
+}
+
+// Consume exchanges the token of a link for tokens. The link only replaces
+// the password: users with two-factor authentication get an MFA challenge,
+// as from Login.
+func (h *MagicLinkHandler) Consume(c *gin.Context) {
+	var req dto.ConsumeMagicLinkRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var accessToken string
+	var refreshToken string
+	var mfaToken string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.magicLinks.Consume(txStore, req.Token)
+		if err != nil {
+			return err
+		}
+
+		if err := h.lockouts.Check(txStore, user.Email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		mfaEnabled, err := h.mfa.Enabled(txStore, user.ID)
+		if err != nil {
+			return err
+		}
+		if mfaEnabled {
+			mfaToken, err = h.mfa.Challenge(user)
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(txStore, user, clientInfo(c))
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedInEvent(txStore, user)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			resp.Errors["error"] = "ERR_INVALID_TOKEN"
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrAccountLocked):
+			setRetryAfter(c, err)
+			resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+			status = http.StatusTooManyRequests
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	if mfaToken != "" {
+		resp.Data = dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+	} else {
+		resp.Data = dto.TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}
+	}
+
+	c.JSON(status, resp)
+}
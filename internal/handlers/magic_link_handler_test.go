@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/utils"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &domain.User{ID: userID(), Email: "john@example.com"}
+
+	var saved *domain.MagicLinkToken
+	var link *services.MagicLinkRequestedPayload
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockLinkRepo := new(mocks.MagicLinkTokenRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("MagicLinkTokens").Return(mockLinkRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockLinkRepo.On("DeleteByUser", user.ID).Return(nil)
+	mockLinkRepo.On("Save", mock.AnythingOfType("*domain.MagicLinkToken")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*domain.MagicLinkToken) }).
+		Return(nil)
+	mockLinkRepo.On("GetBySelector", mock.Anything).Return(func(selector string) (*domain.MagicLinkToken, error) {
+		if saved == nil || saved.Selector != selector {
+			return nil, nil
+		}
+		return saved, nil
+	})
+	mockCredentialRepo.On("Get", user.ID).Return(nil, nil)
+	mockHasher.On("Hash", mock.Anything).Return("verifier_hash", nil)
+	mockHasher.On("Verify", mock.Anything, "verifier_hash").Return(true)
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.MagicLinkRequested, mock.AnythingOfType("*services.MagicLinkRequestedPayload")).
+		Run(func(args mock.Arguments) { link = args.Get(1).(*services.MagicLinkRequestedPayload) }).
+		Return(nil).Once()
+	mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil).Once()
+
+	r := gin.New()
+	NewMagicLinkHandler(
+		newTxUow(mockStore),
+		newTestRequestValidator(),
+		nil,
+		services.NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		services.NewMFAService(utils.NewTOTP(), testTokenSigner, "Shop", 5*time.Minute),
+		services.NewMagicLinkService(mockHasher, utils.NewTokenGenerator(), 15*time.Minute),
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+
+	known := performRequest(r, "POST", "/auth/magic-link", `{"email": "john@example.com"}`, nil)
+	unknown := performRequest(r, "POST", "/auth/magic-link", `{"email": "nobody@example.com"}`, nil)
+
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	require.NotNil(t, link)
+	assert.Equal(t, "john@example.com", link.Email)
+
+	t.Run("Invalid token", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/magic-link/consume", `{"token": "forged.token"}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/magic-link/consume", `{"token": "`+link.Token+`"}`, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data dto.TokenResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "jwt_token", body.Data.AccessToken)
+		assert.NotEmpty(t, body.Data.RefreshToken)
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Used twice", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/magic-link/consume", `{"token": "`+link.Token+`"}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MFAHandler lets users manage TOTP two-factor authentication. The second
+// step of the login lives in AuthHandler.
+type MFAHandler struct {
+	uow                 uows.UnitOfWork[stores.Store]
+	requestValidator    *middlewares.RequestValidator
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	rateLimiter         *middlewares.RateLimiter
+	userService         *services.UserService
+	mfaService          *services.MFAService
+	outboxService       *services.UserTokenOutboxService
+}
+
+func NewMFAHandler(
+	uow uows.UnitOfWork[stores.Store],
+	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+	userService *services.UserService,
+	mfaService *services.MFAService,
+	outboxService *services.UserTokenOutboxService,
+) *MFAHandler {
+	return &MFAHandler{
+		uow:                 uow,
+		requestValidator:    requestValidator,
+		accessTokenVerifier: accessTokenVerifier,
+		rateLimiter:         rateLimiter,
+		userService:         userService,
+		mfaService:          mfaService,
+		outboxService:       outboxService,
+	}
+}
+
+func (h *MFAHandler) BindRoutes(r *gin.RouterGroup) {
+	mfa := r.Group("/mfa", h.accessTokenVerifier.Handle, h.rateLimiter.Limit("mfa"))
+	mfa.POST("/totp/enroll", h.EnrollTOTP)
+	mfa.POST("/totp/confirm", h.ConfirmTOTP)
+	mfa.POST("/totp/disable", h.DisableTOTP)
+	mfa.POST("/recovery-codes", h.RegenerateRecoveryCodes)
+}
+
+// EnrollTOTP returns a new secret. Two-factor authentication stays off until
+// the user confirms it with a code from the app.
+func (h *MFAHandler) EnrollTOTP(c *gin.Context) {
+	var enrollment *services.TOTPEnrollment
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.userService.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		enrollment, err = h.mfaService.Enroll(txStore, user)
+		return err
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setMFAError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	}
+
+	c.JSON(status, resp)
+}
+
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var recoveryCodes []string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.userService.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = h.mfaService.Confirm(txStore, user, req.Code)
+		if err != nil {
+			return err
+		}
+
+		return h.outboxService.SaveMFAEnabledEvent(txStore, services.MFAPayload{UserID: user.ID})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setMFAError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}
+
+	c.JSON(status, resp)
+}
+
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.userService.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		if err := h.mfaService.Disable(txStore, user, req.Code); err != nil {
+			return err
+		}
+
+		return h.outboxService.SaveMFADisabledEvent(txStore, services.MFAPayload{UserID: user.ID})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setMFAError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req dto.MFACodeRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var recoveryCodes []string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.userService.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		recoveryCodes, err = h.mfaService.RegenerateRecoveryCodes(txStore, user, req.Code)
+		if err != nil {
+			return err
+		}
+
+		return h.outboxService.SaveRecoveryCodesRegeneratedEvent(txStore, services.MFAPayload{UserID: user.ID})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setMFAError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}
+
+	c.JSON(status, resp)
+}
+
+// setMFAError reports err in resp and returns the status to answer with.
+func setMFAError(resp dto.APIResponse, err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFACode):
+		resp.Errors["error"] = "ERR_INVALID_MFA_CODE"
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrMFANotEnabled):
+		resp.Errors["error"] = "ERR_MFA_NOT_ENABLED"
+		return http.StatusConflict
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		resp.Errors["error"] = "ERR_MFA_ALREADY_ENABLED"
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidCredentials):
+		resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
+		return http.StatusUnauthorized
+	default:
+		resp.Errors["error"] = "ERR_INTERNAL"
+		return http.StatusInternalServerError
+	}
+}
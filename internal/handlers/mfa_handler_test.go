@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/utils"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMFAHandler_EnrollAndConfirm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	auth := map[string]string{"Authorization": "Bearer access"}
+	user := &domain.User{ID: userID(), Email: "john@example.com"}
+
+	var credential *domain.TOTPCredential
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockCodeRepo := new(mocks.RecoveryCodeRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockStore.On("RecoveryCodes").Return(mockCodeRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockCredentialRepo.On("Get", user.ID).Return(func(uuid.UUID) (*domain.TOTPCredential, error) {
+		return credential, nil
+	})
+	mockCredentialRepo.On("Save", mock.Anything).Return(func(c *domain.TOTPCredential) error {
+		credential = c
+		return nil
+	})
+	mockCodeRepo.On("DeleteByUser", user.ID).Return(nil)
+	mockCodeRepo.On("Create", mock.MatchedBy(func(codes []domain.RecoveryCode) bool {
+		return len(codes) == 10
+	})).Return(nil)
+	mockEventRepo.On("Save", services.MFAEnabled, services.MFAPayload{UserID: user.ID}).Return(nil).Once()
+	mockJwtHelper.On("ParseAccessToken", "access").Return(&utils.Claims{UserID: user.ID.String()}, nil)
+
+	totp := utils.NewTOTP()
+	r := gin.New()
+	NewMFAHandler(
+		newTxUow(mockStore),
+		newTestRequestValidator(),
+		middlewares.NewAccessTokenVerifier(mockJwtHelper, denylists.NewMemoryDenylist(), false),
+		nil,
+		services.NewUserService(nil, nil, false),
+		services.NewMFAService(totp, testTokenSigner, "Shop", 5*time.Minute),
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+
+	w := performRequest(r, "POST", "/auth/mfa/totp/enroll", "", auth)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var enrollment struct {
+		Data dto.TOTPEnrollmentResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+	assert.Equal(t, credential.Secret, enrollment.Data.Secret)
+	assert.Contains(t, enrollment.Data.OTPAuthURI, "otpauth://totp/Shop:john@example.com")
+
+	t.Run("Wrong code", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/mfa/totp/confirm", `{"code": "abcdef"}`, auth)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_MFA_CODE")
+		assert.Nil(t, credential.ConfirmedAt)
+	})
+
+	t.Run("Confirm", func(t *testing.T) {
+		key, _ := utils.DecodeTOTPSecret(enrollment.Data.Secret)
+		code := totp.Code(key, totp.Step(time.Now()))
+
+		w := performRequest(r, "POST", "/auth/mfa/totp/confirm", `{"code": "`+code+`"}`, auth)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var confirmed struct {
+			Data dto.RecoveryCodesResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &confirmed))
+		assert.Len(t, confirmed.Data.RecoveryCodes, 10)
+		assert.NotNil(t, credential.ConfirmedAt)
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Enroll again", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/mfa/totp/enroll", "", auth)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_MFA_ALREADY_ENABLED")
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/mfa/totp/enroll", "", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
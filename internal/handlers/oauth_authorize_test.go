@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/utils"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRedirectURI  = "https://app.example.com/callback"
+	testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+)
+
+func newTestJWTManager(t *testing.T) *utils.JWTManager {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	key, err := utils.ParseSigningKeyPEM(string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	})))
+	require.NoError(t, err)
+
+	keyring, err := utils.NewKeyring(key)
+	require.NoError(t, err)
+
+	return utils.NewJWTManager(keyring, 15*time.Minute, "http://auth.test")
+}
+
+// newAuthorizationCodeServer runs the OAuth endpoints against mocks that keep
+// authorization codes in memory, so a code issued by /oauth/authorize can be
+// exchanged at /oauth/token.
+func newAuthorizationCodeServer(t *testing.T, jwtHelper utils.JWTHelper) *httptest.Server {
+	user := &domain.User{ID: userID(), Email: "john@example.com", Password: "hashed_password"}
+	client := &domain.Client{
+		ClientID:     "spa",
+		Name:         "Example App",
+		GrantTypes:   "authorization_code refresh_token",
+		Scopes:       "openid profile orders:create",
+		RedirectURIs: testRedirectURI,
+	}
+
+	mockStore := new(mocks.StoreMock)
+	mockClientRepo := new(mocks.ClientRepositoryMock)
+	mockCodeRepo := new(mocks.AuthorizationCodeRepositoryMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Clients").Return(mockClientRepo)
+	mockStore.On("AuthorizationCodes").Return(mockCodeRepo)
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	withPermissions(mockStore, "orders:create", "users:unlock")
+
+	codes := make(map[string]domain.AuthorizationCode)
+	mockCodeRepo.On("Save", mock.AnythingOfType("*domain.AuthorizationCode")).Return(func(code *domain.AuthorizationCode) error {
+		codes[code.CodeHash] = *code
+		return nil
+	})
+	mockCodeRepo.On("GetByHash", mock.AnythingOfType("string")).Return(func(hash string) (*domain.AuthorizationCode, error) {
+		code, ok := codes[hash]
+		if !ok {
+			return nil, nil
+		}
+		return &code, nil
+	})
+
+	mockClientRepo.On("GetByClientID", "spa").Return(client, nil)
+	mockClientRepo.On("GetByClientID", mock.Anything).Return(nil, nil)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockHasher.On("Verify", "password123", "hashed_password").Return(true)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+	mockHasher.On("Verify", mock.Anything, "hashed_password").Return(false)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil)
+
+	r := gin.New()
+	newTestOAuthHandler(mockStore, mockHasher, jwtHelper).BindRoutes(&r.RouterGroup)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func authorizeQuery(overrides map[string]string) url.Values {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {testRedirectURI},
+		"scope":                 {"openid orders:create"},
+		"state":                 {"xyz"},
+		"code_challenge":        {utils.PKCEChallenge(testCodeVerifier)},
+		"code_challenge_method": {utils.PKCEMethodS256},
+	}
+	for k, v := range overrides {
+		query.Set(k, v)
+	}
+	return query
+}
+
+var csrfTokenField = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+// noRedirectClient keeps cookies like a browser, so the CSRF cookie of the
+// login page is sent back with its form.
+func noRedirectClient() *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// showLoginForm loads the login page and returns the CSRF token of its form.
+func showLoginForm(t *testing.T, httpClient *http.Client, serverURL string, query url.Values) string {
+	resp, err := httpClient.Get(serverURL + "/oauth/authorize?" + query.Encode())
+	require.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	match := csrfTokenField.FindSubmatch(page)
+	require.Len(t, match, 2)
+	return string(match[1])
+}
+
+func TestOAuthHandler_AuthorizationCodeFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := newTestJWTManager(t)
+	server := newAuthorizationCodeServer(t, jwtManager)
+	httpClient := noRedirectClient()
+
+	// The login page carries the authorization request as hidden fields.
+	resp, err := httpClient.Get(server.URL + "/oauth/authorize?" + authorizeQuery(nil).Encode())
+	require.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"))
+	assert.Contains(t, string(page), "Example App")
+	assert.Contains(t, string(page), `name="code_challenge" value="`+utils.PKCEChallenge(testCodeVerifier)+`"`)
+
+	match := csrfTokenField.FindSubmatch(page)
+	require.Len(t, match, 2)
+	csrfToken := string(match[1])
+
+	// Wrong credentials keep the user on the login page.
+	form := authorizeQuery(map[string]string{"email": "john@example.com", "password": "wrong", "csrf_token": csrfToken})
+	resp, err = httpClient.PostForm(server.URL+"/oauth/authorize", form)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Signing in redirects back to the client with a code and the state.
+	form = authorizeQuery(map[string]string{"email": "john@example.com", "password": "password123", "csrf_token": csrfToken})
+	resp, err = httpClient.PostForm(server.URL+"/oauth/authorize", form)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, testRedirectURI, location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "xyz", location.Query().Get("state"))
+	code := location.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	exchange := func(verifier string) *http.Response {
+		resp, err := httpClient.PostForm(server.URL+"/oauth/token", url.Values{
+			"grant_type":    {"authorization_code"},
+			"client_id":     {"spa"},
+			"code":          {code},
+			"redirect_uri":  {testRedirectURI},
+			"code_verifier": {verifier},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// A wrong verifier does not burn the code.
+	resp = exchange(strings.Repeat("a", 43))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = exchange(testCodeVerifier)
+	var tokens dto.OAuthTokenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, "openid orders:create", tokens.Scope)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	claims, err := jwtManager.ParseAccessToken(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, userID().String(), claims.Subject)
+	assert.Equal(t, "spa", claims.ClientID)
+	assert.Equal(t, "openid orders:create", claims.Scope)
+	// users:unlock is a permission of the user the scope does not name.
+	assert.Equal(t, []string{"orders:create"}, claims.Permissions)
+
+	// Codes are single use.
+	resp = exchange(testCodeVerifier)
+	var oauthErr dto.OAuthErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&oauthErr))
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "invalid_grant", oauthErr.Error)
+}
+
+func TestOAuthHandler_AuthorizeCSRF(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newAuthorizationCodeServer(t, nil)
+
+	signIn := func(httpClient *http.Client, form url.Values) *http.Response {
+		resp, err := httpClient.PostForm(server.URL+"/oauth/authorize", form)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	t.Run("Form without a token", func(t *testing.T) {
+		httpClient := noRedirectClient()
+		showLoginForm(t, httpClient, server.URL, authorizeQuery(nil))
+
+		resp := signIn(httpClient, authorizeQuery(map[string]string{"email": "john@example.com", "password": "password123"}))
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Location"))
+	})
+
+	t.Run("Form posted from another browser", func(t *testing.T) {
+		csrfToken := showLoginForm(t, noRedirectClient(), server.URL, authorizeQuery(nil))
+
+		resp := signIn(noRedirectClient(), authorizeQuery(map[string]string{
+			"email": "john@example.com", "password": "password123", "csrf_token": csrfToken,
+		}))
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Location"))
+	})
+
+	t.Run("Token of another authorization request", func(t *testing.T) {
+		httpClient := noRedirectClient()
+		csrfToken := showLoginForm(t, httpClient, server.URL, authorizeQuery(nil))
+
+		resp := signIn(httpClient, authorizeQuery(map[string]string{
+			"email": "john@example.com", "password": "password123", "csrf_token": csrfToken, "state": "forged",
+		}))
+
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Location"))
+	})
+}
+
+func TestOAuthHandler_AuthorizeErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newAuthorizationCodeServer(t, nil)
+	httpClient := noRedirectClient()
+
+	t.Run("Unregistered redirect URI is not followed", func(t *testing.T) {
+		query := authorizeQuery(map[string]string{"redirect_uri": "https://evil.example.com/callback"})
+		resp, err := httpClient.Get(server.URL + "/oauth/authorize?" + query.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get("Location"))
+	})
+
+	t.Run("Unknown client", func(t *testing.T) {
+		query := authorizeQuery(map[string]string{"client_id": "unknown"})
+		resp, err := httpClient.Get(server.URL + "/oauth/authorize?" + query.Encode())
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	tests := []struct {
+		name      string
+		overrides map[string]string
+		error     string
+	}{
+		{"Missing PKCE", map[string]string{"code_challenge": ""}, "invalid_request"},
+		{"Plain PKCE", map[string]string{"code_challenge_method": "plain"}, "invalid_request"},
+		{"Token response type", map[string]string{"response_type": "token"}, "unsupported_response_type"},
+		{"Unknown scope", map[string]string{"scope": "admin"}, "invalid_scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := httpClient.Get(server.URL + "/oauth/authorize?" + authorizeQuery(tt.overrides).Encode())
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+			location, err := url.Parse(resp.Header.Get("Location"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.error, location.Query().Get("error"))
+			assert.Equal(t, "xyz", location.Query().Get("state"))
+		})
+	}
+}
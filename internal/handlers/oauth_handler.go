@@ -0,0 +1,743 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"app/internal/utils"
+	_ "embed"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed templates/authorize.html
+var authorizeHTML string
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(authorizeHTML))
+
+const authorizeCSRFCookie = "oauth_authorize_csrf"
+
+// OAuthHandler serves the RFC 6749 authorization and token endpoints. Token
+// requests are form encoded and every grant needs a client registered in the
+// clients table. Responses use the OAuth format instead of dto.APIResponse.
+type OAuthHandler struct {
+	uow            uows.UnitOfWork[stores.Store]
+	rateLimiter    *middlewares.RateLimiter
+	users          *services.UserService
+	tokens         *services.TokenService
+	clients        *services.ClientService
+	authorizations *services.AuthorizationService
+	lockouts       *services.LockoutService
+	mfa            *services.MFAService
+	outbox         *services.UserTokenOutboxService
+	accessTokenTTL time.Duration
+	// secureCookie marks the CSRF cookie of the login form Secure.
+	secureCookie bool
+}
+
+func NewOAuthHandler(
+	uow uows.UnitOfWork[stores.Store],
+	rateLimiter *middlewares.RateLimiter,
+	users *services.UserService,
+	tokens *services.TokenService,
+	clients *services.ClientService,
+	authorizations *services.AuthorizationService,
+	lockouts *services.LockoutService,
+	mfa *services.MFAService,
+	outbox *services.UserTokenOutboxService,
+	accessTokenTTL time.Duration,
+	secureCookie bool,
+) *OAuthHandler {
+	return &OAuthHandler{
+		uow:            uow,
+		rateLimiter:    rateLimiter,
+		users:          users,
+		tokens:         tokens,
+		clients:        clients,
+		authorizations: authorizations,
+		lockouts:       lockouts,
+		mfa:            mfa,
+		outbox:         outbox,
+		accessTokenTTL: accessTokenTTL,
+		secureCookie:   secureCookie,
+	}
+}
+
+func (h *OAuthHandler) BindRoutes(r *gin.RouterGroup) {
+	r.GET("/oauth/authorize", h.AuthorizeForm)
+	r.POST("/oauth/authorize", h.rateLimiter.Limit("oauth-authorize"), h.Authorize)
+	r.POST("/oauth/token", h.rateLimiter.Limit("oauth-token"), h.Token)
+	r.POST("/oauth/revoke", h.Revoke)
+	r.POST("/oauth/introspect", h.Introspect)
+}
+
+// authorizePage is the data of the login page. Fatal errors are shown without
+// the form, because the request cannot be redirected back to the client.
+type authorizePage struct {
+	Action     string
+	Request    dto.AuthorizeRequest
+	ClientName string
+	Email      string
+	Error      string
+	// CSRFToken is bound to the authorization request and to a cookie of the
+	// browser, so the form cannot be posted from another site.
+	CSRFToken string
+	Fatal     bool
+	// ShowOTP asks for the two-factor code along with the password.
+	ShowOTP bool
+}
+
+// AuthorizeForm validates an authorization request and shows the login page.
+func (h *OAuthHandler) AuthorizeForm(c *gin.Context) {
+	var req dto.AuthorizeRequest
+	_ = c.ShouldBindQuery(&req)
+
+	client, ok := h.validateAuthorizeRequest(c, req)
+	if !ok {
+		return
+	}
+
+	csrfToken, err := h.newAuthorizeForm(c, req)
+	if err != nil {
+		renderAuthorizePage(c, http.StatusInternalServerError, authorizePage{
+			Error: "Something went wrong, please try again later.",
+			Fatal: true,
+		})
+		return
+	}
+
+	renderAuthorizePage(c, http.StatusOK, authorizePage{Request: req, ClientName: client.Name, CSRFToken: csrfToken})
+}
+
+// Authorize checks the credentials posted from the login page and redirects
+// back to the client with a single-use code.
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req dto.AuthorizeRequest
+	_ = c.ShouldBind(&req)
+
+	client, ok := h.validateAuthorizeRequest(c, req)
+	if !ok {
+		return
+	}
+
+	email := c.PostForm("email")
+	csrfToken := c.PostForm("csrf_token")
+	nonce, _ := c.Cookie(authorizeCSRFCookie)
+	if err := h.authorizations.VerifyFormToken(csrfToken, nonce, authorizeRequestKey(req)); err != nil {
+		// Show a fresh form rather than checking credentials posted from
+		// elsewhere or with an expired form.
+		csrfToken, err = h.newAuthorizeForm(c, req)
+		if err != nil {
+			redirectWithError(c, req, "server_error")
+			return
+		}
+		renderAuthorizePage(c, http.StatusForbidden, authorizePage{
+			Request:    req,
+			ClientName: client.Name,
+			Email:      email,
+			Error:      "The sign-in form expired, please try again.",
+			CSRFToken:  csrfToken,
+		})
+		return
+	}
+
+	var code string
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		if err := h.lockouts.Check(store, email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		user, err := h.users.Authenticate(store, email, c.PostForm("password"))
+		if err != nil {
+			return err
+		}
+
+		mfaEnabled, err := h.mfa.Enabled(store, user.ID)
+		if err != nil {
+			return err
+		}
+		if mfaEnabled {
+			if c.PostForm("otp") == "" {
+				return services.ErrMFARequired
+			}
+			if err := h.mfa.VerifyCode(store, user.ID, c.PostForm("otp")); err != nil {
+				return err
+			}
+		}
+
+		if err := h.lockouts.RecordSuccess(store, email); err != nil {
+			return err
+		}
+
+		scope, err := h.clients.Authorize(client, domain.GrantTypeAuthorizationCode, req.Scope)
+		if err != nil {
+			return err
+		}
+
+		code, err = h.authorizations.IssueCode(store, client, user, req.RedirectURI, scope, req.CodeChallenge)
+		return err
+	})
+	if err != nil {
+		err = loginFailed(c, h.uow, h.lockouts, h.outbox, email, err)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			renderAuthorizePage(c, http.StatusUnauthorized, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Email:      email,
+				CSRFToken:  csrfToken,
+				Error:      "Invalid email or password.",
+			})
+		case errors.Is(err, services.ErrMFARequired):
+			renderAuthorizePage(c, http.StatusUnauthorized, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Email:      email,
+				CSRFToken:  csrfToken,
+				Error:      "Enter the code from your authenticator app.",
+				ShowOTP:    true,
+			})
+		case errors.Is(err, services.ErrInvalidMFACode), errors.Is(err, services.ErrMFANotEnabled):
+			renderAuthorizePage(c, http.StatusUnauthorized, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Email:      email,
+				CSRFToken:  csrfToken,
+				Error:      "Invalid authentication code.",
+				ShowOTP:    true,
+			})
+		case errors.Is(err, services.ErrEmailNotVerified):
+			renderAuthorizePage(c, http.StatusForbidden, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Email:      email,
+				CSRFToken:  csrfToken,
+				Error:      "Please verify your email address before signing in.",
+			})
+		case errors.Is(err, services.ErrAccountLocked):
+			setRetryAfter(c, err)
+			renderAuthorizePage(c, http.StatusTooManyRequests, authorizePage{
+				Request:    req,
+				ClientName: client.Name,
+				Email:      email,
+				CSRFToken:  csrfToken,
+				Error:      "Too many failed sign-in attempts, please try again later.",
+			})
+		default:
+			redirectWithError(c, req, "server_error")
+		}
+		return
+	}
+
+	redirectToClient(c, req.RedirectURI, url.Values{"code": {code}}, req.State)
+}
+
+// validateAuthorizeRequest checks an authorization request in the order of
+// RFC 6749 section 4.1.2.1: an unknown client or redirect URI is shown to the
+// user, every other problem is reported to the client through the redirect.
+func (h *OAuthHandler) validateAuthorizeRequest(c *gin.Context, req dto.AuthorizeRequest) (*domain.Client, bool) {
+	var client *domain.Client
+	err := h.uow.Do(func(store stores.Store) error {
+		var err error
+		client, err = h.clients.GetByClientID(store, req.ClientID)
+		return err
+	})
+	if err != nil {
+		message := "The application could not be identified."
+		status := http.StatusBadRequest
+		if !errors.Is(err, services.ErrInvalidClient) {
+			message = "Something went wrong, please try again later."
+			status = http.StatusInternalServerError
+		}
+		renderAuthorizePage(c, status, authorizePage{Error: message, Fatal: true})
+		return nil, false
+	}
+
+	if !client.AllowsRedirectURI(req.RedirectURI) {
+		renderAuthorizePage(c, http.StatusBadRequest, authorizePage{
+			Error: "The redirect URI is not registered for this application.",
+			Fatal: true,
+		})
+		return nil, false
+	}
+
+	switch {
+	case req.ResponseType != "code":
+		redirectWithError(c, req, "unsupported_response_type")
+	case !client.AllowsGrant(domain.GrantTypeAuthorizationCode):
+		redirectWithError(c, req, "unauthorized_client")
+	case req.CodeChallenge == "" || req.CodeChallengeMethod != utils.PKCEMethodS256:
+		redirectWithError(c, req, "invalid_request")
+	case !client.AllowsScopes(req.Scope):
+		redirectWithError(c, req, "invalid_scope")
+	default:
+		return client, true
+	}
+
+	return nil, false
+}
+
+// newAuthorizeForm issues the CSRF token of a login form and keeps its nonce
+// in a cookie scoped to the authorize endpoint.
+func (h *OAuthHandler) newAuthorizeForm(c *gin.Context, req dto.AuthorizeRequest) (string, error) {
+	token, nonce, err := h.authorizations.IssueFormToken(authorizeRequestKey(req))
+	if err != nil {
+		return "", err
+	}
+
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(authorizeCSRFCookie, nonce, 0, c.Request.URL.Path, "", h.secureCookie, true)
+	return token, nil
+}
+
+// authorizeRequestKey identifies an authorization request, so a form token is
+// only accepted for the request its form was shown for.
+func authorizeRequestKey(req dto.AuthorizeRequest) string {
+	return utils.HashToken(strings.Join([]string{
+		req.ResponseType,
+		req.ClientID,
+		req.RedirectURI,
+		req.Scope,
+		req.State,
+		req.CodeChallenge,
+		req.CodeChallengeMethod,
+	}, "\n"))
+}
+
+func renderAuthorizePage(c *gin.Context, status int, page authorizePage) {
+	page.Action = c.Request.URL.Path
+
+	// The page collects credentials, so it must not be framed or cached.
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+
+	if err := authorizeTemplate.Execute(c.Writer, page); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func redirectWithError(c *gin.Context, req dto.AuthorizeRequest, code string) {
+	redirectToClient(c, req.RedirectURI, url.Values{"error": {code}}, req.State)
+}
+
+// redirectToClient sends the user back to a validated redirect URI, keeping its
+// own query parameters.
+func redirectToClient(c *gin.Context, redirectURI string, params url.Values, state string) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		renderAuthorizePage(c, http.StatusBadRequest, authorizePage{
+			Error: "The redirect URI is not valid.",
+			Fatal: true,
+		})
+		return
+	}
+
+	query := u.Query()
+	for k, v := range params {
+		query[k] = v
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	u.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusSeeOther, u.String())
+}
+
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	grantType := c.PostForm("grant_type")
+	switch grantType {
+	case "":
+		oauthError(c, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	case domain.GrantTypePassword:
+		if c.PostForm("username") == "" || c.PostForm("password") == "" {
+			oauthError(c, http.StatusBadRequest, "invalid_request", "username and password are required")
+			return
+		}
+	case domain.GrantTypeRefreshToken:
+		if c.PostForm("refresh_token") == "" {
+			oauthError(c, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+			return
+		}
+	case domain.GrantTypeAuthorizationCode:
+		if c.PostForm("code") == "" || c.PostForm("redirect_uri") == "" || c.PostForm("code_verifier") == "" {
+			oauthError(c, http.StatusBadRequest, "invalid_request", "code, redirect_uri and code_verifier are required")
+			return
+		}
+	case domain.GrantTypeClientCredentials:
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "")
+		return
+	}
+
+	clientID, clientSecret, basicAuth := clientCredentials(c)
+	if clientID == "" {
+		oauthInvalidClient(c, basicAuth)
+		return
+	}
+
+	resp := dto.OAuthTokenResponse{
+		TokenType: "Bearer",
+		ExpiresIn: int(h.accessTokenTTL.Seconds()),
+	}
+	var reuseDetected bool
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		client, err := h.clients.Authenticate(store, clientID, clientSecret)
+		if err != nil {
+			return err
+		}
+
+		scope, err := h.clients.Authorize(client, grantType, c.PostForm("scope"))
+		if err != nil {
+			return err
+		}
+
+		info := clientInfo(c)
+		info.ClientID = client.ClientID
+
+		switch grantType {
+		case domain.GrantTypePassword:
+			if err := h.lockouts.Check(store, c.PostForm("username"), c.ClientIP()); err != nil {
+				return err
+			}
+
+			user, err := h.users.Authenticate(store, c.PostForm("username"), c.PostForm("password"))
+			if err != nil {
+				return err
+			}
+
+			// The password grant has no step for a second factor.
+			mfaEnabled, err := h.mfa.Enabled(store, user.ID)
+			if err != nil {
+				return err
+			}
+			if mfaEnabled {
+				return services.ErrMFARequired
+			}
+
+			if err := h.lockouts.RecordSuccess(store, c.PostForm("username")); err != nil {
+				return err
+			}
+
+			info.Scope = scope
+			resp.Scope = scope
+			resp.AccessToken, resp.RefreshToken, err = h.tokens.IssueTokenForUser(store, user, info)
+			if err != nil {
+				return err
+			}
+
+			return h.outbox.SaveUserLoggedInEvent(store, user)
+		case domain.GrantTypeRefreshToken:
+			token, err := h.tokens.VerifyRefreshToken(store, c.PostForm("refresh_token"))
+			if errors.Is(err, services.ErrRefreshTokenReused) {
+				// Same as /auth/refresh: commit the revoked family and the event.
+				reuseDetected = true
+				return h.outbox.SaveRefreshTokenReuseDetectedEvent(store, token)
+			}
+			if err != nil {
+				return services.ErrInvalidCredentials
+			}
+			if token.ClientID != client.ClientID {
+				return services.ErrInvalidCredentials
+			}
+
+			user, err := h.users.GetByID(store, token.UserID.String())
+			if err != nil {
+				return err
+			}
+
+			// The session keeps the scope the user granted when it started.
+			resp.Scope = token.Scope
+			resp.AccessToken, resp.RefreshToken, err = h.tokens.RotateRefreshToken(store, user, token, info)
+			return err
+		case domain.GrantTypeAuthorizationCode:
+			authCode, err := h.authorizations.ExchangeCode(
+				store, client, c.PostForm("code"), c.PostForm("redirect_uri"), c.PostForm("code_verifier"),
+			)
+			if err != nil {
+				return err
+			}
+
+			user, err := h.users.GetByID(store, authCode.UserID.String())
+			if err != nil {
+				return err
+			}
+
+			info.Scope = authCode.Scope
+			resp.Scope = authCode.Scope
+			resp.AccessToken, resp.RefreshToken, err = h.tokens.IssueTokenForUser(store, user, info)
+			if err != nil {
+				return err
+			}
+
+			return h.outbox.SaveUserLoggedInEvent(store, user)
+		default:
+			// Anyone may present the client_id of a public client, so it
+			// cannot get tokens of its own.
+			if client.IsPublic() {
+				return services.ErrUnauthorizedClient
+			}
+			resp.Scope = scope
+			resp.AccessToken, err = h.tokens.IssueTokenForClient(client, scope)
+			return err
+		}
+	})
+	if err == nil && reuseDetected {
+		err = services.ErrRefreshTokenReused
+	}
+	if err != nil && grantType == domain.GrantTypePassword {
+		err = loginFailed(c, h.uow, h.lockouts, h.outbox, c.PostForm("username"), err)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidClient):
+			oauthInvalidClient(c, basicAuth)
+		case errors.Is(err, services.ErrUnauthorizedClient):
+			oauthError(c, http.StatusBadRequest, "unauthorized_client", "")
+		case errors.Is(err, services.ErrInvalidScope):
+			oauthError(c, http.StatusBadRequest, "invalid_scope", "")
+		case errors.Is(err, services.ErrInvalidCredentials),
+			errors.Is(err, services.ErrRefreshTokenReused):
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+		case errors.Is(err, services.ErrEmailNotVerified):
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "email address is not verified")
+		case errors.Is(err, services.ErrMFARequired):
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "two-factor authentication is required, use the authorization code grant")
+		case errors.Is(err, services.ErrAccountLocked):
+			setRetryAfter(c, err)
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "too many failed attempts, try again later")
+		default:
+			oauthError(c, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke implements RFC 7009. Revoking a refresh token ends its whole session,
+// revoking an access token puts its jti on the denylist.
+// Unknown and already revoked tokens are not an error, so the response does not
+// tell which tokens exist. Refresh tokens of first-party logins belong to no
+// client and are treated as unknown; they are ended through /auth/logout.
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	clientID, clientSecret, basicAuth := clientCredentials(c)
+	if clientID == "" {
+		oauthInvalidClient(c, basicAuth)
+		return
+	}
+
+	err := h.uow.DoTransaction(func(store stores.Store) error {
+		client, err := h.clients.Authenticate(store, clientID, clientSecret)
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err := h.tokens.FindRefreshToken(store, token)
+		if err != nil {
+			return err
+		}
+		if refreshToken != nil {
+			if refreshToken.ClientID == "" {
+				return nil
+			}
+			if refreshToken.ClientID != client.ClientID {
+				return services.ErrUnauthorizedClient
+			}
+			if refreshToken.RevokedAt != nil {
+				return nil
+			}
+
+			if _, err := h.tokens.RevokeRefreshToken(store, token); err != nil {
+				return err
+			}
+
+			return h.outbox.SaveUserLoggedOutEvent(store, services.UserLoggedOutPayload{
+				UserID:    refreshToken.UserID,
+				SessionID: &refreshToken.SessionID,
+			})
+		}
+
+		// Invalid and already revoked access tokens need no work either.
+		claims, err := h.tokens.ParseAccessToken(c.Request.Context(), token)
+		if err != nil {
+			return nil
+		}
+		if claims.ClientID != "" && claims.ClientID != client.ClientID {
+			return services.ErrUnauthorizedClient
+		}
+
+		return h.tokens.RevokeAccessToken(c.Request.Context(), claims)
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidClient):
+			oauthInvalidClient(c, basicAuth)
+		case errors.Is(err, services.ErrUnauthorizedClient):
+			oauthError(c, http.StatusBadRequest, "unauthorized_client", "")
+		default:
+			oauthError(c, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// Introspect implements RFC 7662 for refresh tokens and access tokens. Only
+// confidential clients may introspect. Tokens issued through the token
+// endpoint name their client and scope; those of first-party logins have
+// neither.
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	clientID, clientSecret, basicAuth := clientCredentials(c)
+	if clientID == "" {
+		oauthInvalidClient(c, basicAuth)
+		return
+	}
+
+	var resp dto.IntrospectionResponse
+	err := h.uow.Do(func(store stores.Store) error {
+		client, err := h.clients.Authenticate(store, clientID, clientSecret)
+		if err != nil {
+			return err
+		}
+		if client.IsPublic() {
+			return services.ErrInvalidClient
+		}
+
+		refreshToken, err := h.tokens.FindRefreshToken(store, token)
+		if err != nil {
+			return err
+		}
+		if refreshToken != nil {
+			if !refreshToken.IsActive() {
+				return nil
+			}
+
+			user, err := h.users.GetByID(store, refreshToken.UserID.String())
+			if errors.Is(err, services.ErrInvalidCredentials) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			resp = dto.IntrospectionResponse{
+				Active:    true,
+				TokenType: "refresh_token",
+				Sub:       user.ID.String(),
+				ClientID:  refreshToken.ClientID,
+				Scope:     refreshToken.Scope,
+				Roles:     user.RoleNames(),
+				Exp:       refreshToken.ExpiresAt.Unix(),
+				Iat:       refreshToken.CreatedAt.Unix(),
+			}
+			return nil
+		}
+
+		claims, err := h.tokens.ParseAccessToken(c.Request.Context(), token)
+		if errors.Is(err, services.ErrAccessTokenRevoked) || errors.Is(err, utils.ErrInvalidToken) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp = dto.IntrospectionResponse{
+			Active:      true,
+			TokenType:   "access_token",
+			Sub:         claims.Subject,
+			ClientID:    claims.ClientID,
+			Scope:       claims.Scope,
+			Roles:       claims.Roles,
+			Permissions: claims.Permissions,
+			Exp:         claims.ExpiresAt.Unix(),
+			Iss:         claims.Issuer,
+			Jti:         claims.ID,
+		}
+		if claims.IssuedAt != nil {
+			resp.Iat = claims.IssuedAt.Unix()
+		}
+		return nil
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidClient):
+			oauthInvalidClient(c, basicAuth)
+		default:
+			oauthError(c, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// clientCredentials reads the client from HTTP Basic authentication or, failing
+// that, from the client_id and client_secret form fields (RFC 6749 2.3.1).
+func clientCredentials(c *gin.Context) (string, string, bool) {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// Basic credentials are form encoded before they are base64 encoded.
+		if decoded, err := url.QueryUnescape(id); err == nil {
+			id = decoded
+		}
+		if decoded, err := url.QueryUnescape(secret); err == nil {
+			secret = decoded
+		}
+		return id, secret, true
+	}
+
+	return c.PostForm("client_id"), c.PostForm("client_secret"), false
+}
+
+func oauthInvalidClient(c *gin.Context, basicAuth bool) {
+	if basicAuth {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+}
+
+func oauthError(c *gin.Context, status int, code string, description string) {
+	c.JSON(status, dto.OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: description,
+	})
+}
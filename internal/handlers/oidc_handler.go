@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OIDCHandler serves the OpenID Connect discovery document and the userinfo
+// endpoint. Both live at the root of the issuer, not under /auth.
+type OIDCHandler struct {
+	uow                 uows.UnitOfWork[stores.Store]
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	userService         *services.UserService
+	configuration       dto.OpenIDConfiguration
+}
+
+func NewOIDCHandler(
+	uow uows.UnitOfWork[stores.Store],
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	userService *services.UserService,
+	configuration dto.OpenIDConfiguration,
+) *OIDCHandler {
+	return &OIDCHandler{
+		uow:                 uow,
+		accessTokenVerifier: accessTokenVerifier,
+		userService:         userService,
+		configuration:       configuration,
+	}
+}
+
+func (h *OIDCHandler) BindRoutes(r *gin.RouterGroup) {
+	r.GET("/.well-known/openid-configuration", h.Configuration)
+	r.GET("/userinfo", h.accessTokenVerifier.Handle, h.UserInfo)
+	r.POST("/userinfo", h.accessTokenVerifier.Handle, h.UserInfo)
+}
+
+func (h *OIDCHandler) Configuration(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.configuration)
+}
+
+// UserInfo answers with bare claims instead of dto.APIResponse, as OpenID
+// Connect clients expect.
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	userID := currentUserID(c)
+
+	var user *domain.User
+	err := h.uow.Do(func(store stores.Store) error {
+		var err error
+		user, err = h.userService.GetByID(store, userID)
+		return err
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewUserInfoResponse(user))
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestOIDCHandler(store stores.Store, jwtHelper utils.JWTHelper) *OIDCHandler {
+	mockUow := new(mocks.UnitOfWorkMock[stores.Store])
+	mockUow.On("Do", mock.Anything).Return(func(fn func(stores.Store) error) error {
+		return fn(store)
+	})
+
+	return NewOIDCHandler(
+		mockUow,
+		middlewares.NewAccessTokenVerifier(jwtHelper, denylists.NewMemoryDenylist(), false),
+		services.NewUserService(nil, nil, false),
+		dto.OpenIDConfiguration{
+			Issuer:                           "https://auth.example.com",
+			JwksURI:                          "https://auth.example.com/auth/.well-known/jwks.json",
+			IDTokenSigningAlgValuesSupported: []string{"RS256", "ES256"},
+		},
+	)
+}
+
+func TestOIDCHandler_Configuration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	newTestOIDCHandler(nil, nil).BindRoutes(&r.RouterGroup)
+
+	w := performRequest(r, "GET", "/.well-known/openid-configuration", "", nil)
+
+	var doc dto.OpenIDConfiguration
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "https://auth.example.com", doc.Issuer)
+	assert.Equal(t, []string{"RS256", "ES256"}, doc.IDTokenSigningAlgValuesSupported)
+}
+
+func TestOIDCHandler_UserInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Missing access token", func(t *testing.T) {
+		r := gin.New()
+		newTestOIDCHandler(nil, nil).BindRoutes(&r.RouterGroup)
+
+		w := performRequest(r, "GET", "/userinfo", "", nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockJwtHelper := new(mocks.JWTHelperMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockJwtHelper.On("ParseAccessToken", "access").Return(&utils.Claims{UserID: userID().String()}, nil)
+		mockUserRepo.On("GetByID", userID()).Return(&domain.User{
+			ID:      userID(),
+			Email:   "john@example.com",
+			Name:    "John",
+			Surname: "Doe",
+		}, nil)
+
+		r := gin.New()
+		newTestOIDCHandler(mockStore, mockJwtHelper).BindRoutes(&r.RouterGroup)
+
+		w := performRequest(r, "GET", "/userinfo", "", map[string]string{"Authorization": "Bearer access"})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"sub": "11111111-1111-1111-1111-111111111111",
+			"email": "john@example.com",
+			"given_name": "John",
+			"family_name": "Doe"
+		}`, w.Body.String())
+	})
+}
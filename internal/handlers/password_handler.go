@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"app/internal/validators"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordHandler struct {
+	uow                  uows.UnitOfWork[stores.Store]
+	requestValidator     *middlewares.RequestValidator
+	accessTokenVerifier  *middlewares.AccessTokenVerifier
+	rateLimiter          *middlewares.RateLimiter
+	userService          *services.UserService
+	tokenService         *services.TokenService
+	passwordResetService *services.PasswordResetService
+	outboxService        *services.UserTokenOutboxService
+}
+
+func NewPasswordHandler(
+	uow uows.UnitOfWork[stores.Store],
+	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+	userService *services.UserService,
+	tokenService *services.TokenService,
+	passwordResetService *services.PasswordResetService,
+	outboxService *services.UserTokenOutboxService,
+) *PasswordHandler {
+	return &PasswordHandler{
+		uow:                  uow,
+		requestValidator:     requestValidator,
+		accessTokenVerifier:  accessTokenVerifier,
+		rateLimiter:          rateLimiter,
+		userService:          userService,
+		tokenService:         tokenService,
+		passwordResetService: passwordResetService,
+		outboxService:        outboxService,
+	}
+}
+
+func (h *PasswordHandler) BindRoutes(r *gin.RouterGroup) {
+	r.POST("/password/forgot", h.rateLimiter.Limit("forgot-password"), h.ForgotPassword)
+	r.POST("/password/reset", h.rateLimiter.Limit("reset-password"), h.ResetPassword)
+	r.POST("/password/change", h.accessTokenVerifier.Handle, h.rateLimiter.Limit("change-password"), h.ChangePassword)
+}
+
+// ForgotPassword answers the same way whether or not the email belongs to an
+// account, so it cannot be used to probe for accounts.
+func (h *PasswordHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		reset, err := h.passwordResetService.RequestReset(txStore, req.Email)
+		if err != nil || reset == nil {
+			return err
+		}
+
+		return h.outboxService.SavePasswordResetRequestedEvent(txStore, reset)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	if err != nil {
+		resp.Errors["error"] = "ERR_INTERNAL"
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *PasswordHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var user *domain.User
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		var err error
+		user, err = h.passwordResetService.ResetPassword(txStore, req.Token, req.Password)
+		if err != nil {
+			return err
+		}
+
+		return h.outboxService.SavePasswordResetEvent(txStore, user)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			resp.Errors["error"] = "ERR_INVALID_TOKEN"
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrPasswordContainsPersonalInfo):
+			resp.Errors["password"] = req.FieldErrorCode("password", validators.PasswordRulePersonal)
+			status = http.StatusBadRequest
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.userService.ChangePassword(txStore, currentUserID(c), req.CurrentPassword, req.NewPassword)
+		if err != nil {
+			return err
+		}
+
+		if req.RevokeOtherSessions {
+			if err := h.tokenService.RevokeOtherSessions(txStore, user.ID, req.RefreshToken); err != nil {
+				return err
+			}
+		}
+
+		return h.outboxService.SavePasswordChangedEvent(txStore, services.PasswordChangedPayload{
+			UserID:               user.ID,
+			OtherSessionsRevoked: req.RevokeOtherSessions,
+		})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidCredentials):
+			resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
+			status = http.StatusUnauthorized
+		case errors.Is(err, services.ErrPasswordContainsPersonalInfo):
+			resp.Errors["newpassword"] = req.FieldErrorCode("newpassword", validators.PasswordRulePersonal)
+			status = http.StatusBadRequest
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
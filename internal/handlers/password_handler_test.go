@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/middlewares"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPasswordHandler(store stores.Store, hasher utils.PasswordHasher, jwtHelper utils.JWTHelper) *PasswordHandler {
+	return NewPasswordHandler(
+		newTxUow(store),
+		newTestRequestValidator(),
+		middlewares.NewAccessTokenVerifier(jwtHelper, denylists.NewMemoryDenylist(), false),
+		nil,
+		services.NewUserService(hasher, nil, false),
+		services.NewTokenService(utils.NewTokenGenerator(), jwtHelper, denylists.NewMemoryDenylist()),
+		services.NewPasswordResetService(hasher, utils.NewTokenGenerator(), nil, time.Hour),
+		services.NewOutboxService(),
+	)
+}
+
+func TestPasswordHandler_ForgotPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockResetRepo := new(mocks.PasswordResetTokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("PasswordResetTokens").Return(mockResetRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(&domain.User{ID: userID(), Email: "john@example.com"}, nil)
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, nil)
+	mockResetRepo.On("DeleteByUser", userID()).Return(nil)
+	mockResetRepo.On("Save", mock.Anything).Return(nil)
+	mockEventRepo.On("Save", services.PasswordResetRequested, mock.MatchedBy(
+		func(payload *services.PasswordResetRequestedPayload) bool {
+			return payload.Email == "john@example.com" && payload.Token != ""
+		},
+	)).Return(nil).Once()
+
+	r := gin.New()
+	newTestPasswordHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
+
+	known := performRequest(r, "POST", "/auth/password/forgot", `{"email": "john@example.com"}`, nil)
+	unknown := performRequest(r, "POST", "/auth/password/forgot", `{"email": "nobody@example.com"}`, nil)
+
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Code, unknown.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	mockEventRepo.AssertExpectations(t)
+}
+
+func TestPasswordHandler_ResetPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+		mockResetRepo := new(mocks.PasswordResetTokenRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+		mockHasher := new(mocks.PasswordHasherMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("PasswordResetTokens").Return(mockResetRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+		mockResetRepo.On("GetByHash", utils.HashToken("reset-token")).
+			Return(&domain.PasswordResetToken{UserID: userID(), ExpiresAt: time.Now().Add(time.Minute)}, nil)
+		mockResetRepo.On("Save", mock.Anything).Return(nil)
+		mockUserRepo.On("GetByID", userID()).Return(&domain.User{ID: userID()}, nil)
+		mockUserRepo.On("Save", mock.MatchedBy(func(u *domain.User) bool {
+			return u.Password == "new_hash"
+		})).Return(nil)
+		mockTokenRepo.On("DeleteByUser", userID()).Return(nil)
+		mockEventRepo.On("Save", services.PasswordReset, mock.Anything).Return(nil)
+		mockHasher.On("Hash", "new-password").Return("new_hash", nil)
+
+		r := gin.New()
+		newTestPasswordHandler(mockStore, mockHasher, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/reset",
+			`{"token": "reset-token", "password": "new-password"}`, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUserRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockResetRepo := new(mocks.PasswordResetTokenRepositoryMock)
+
+		mockStore.On("PasswordResetTokens").Return(mockResetRepo)
+		mockResetRepo.On("GetByHash", mock.Anything).Return(nil, nil)
+
+		r := gin.New()
+		newTestPasswordHandler(mockStore, nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/reset",
+			`{"token": "unknown", "password": "new-password"}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+}
+
+func TestPasswordHandler_ChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	currentSession := uuid.New()
+	otherSession := uuid.New()
+	auth := map[string]string{"Authorization": "Bearer access"}
+
+	newStore := func() (*mocks.StoreMock, *mocks.UserRepositoryMock, *mocks.TokenRepositoryMock, *mocks.EventRepositoryMock) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+		mockUserRepo.On("GetByID", userID()).Return(&domain.User{ID: userID(), Password: "old_hash"}, nil)
+
+		return mockStore, mockUserRepo, mockTokenRepo, mockEventRepo
+	}
+
+	newHasher := func() *mocks.PasswordHasherMock {
+		mockHasher := new(mocks.PasswordHasherMock)
+		mockHasher.On("Verify", "old-password", "old_hash").Return(true)
+		mockHasher.On("Verify", mock.Anything, "old_hash").Return(false)
+		mockHasher.On("Hash", "new-password").Return("new_hash", nil)
+		return mockHasher
+	}
+
+	newJwtHelper := func() *mocks.JWTHelperMock {
+		mockJwtHelper := new(mocks.JWTHelperMock)
+		mockJwtHelper.On("ParseAccessToken", "access").Return(&utils.Claims{UserID: userID().String()}, nil)
+		return mockJwtHelper
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore, mockUserRepo, mockTokenRepo, mockEventRepo := newStore()
+		mockUserRepo.On("Save", mock.MatchedBy(func(u *domain.User) bool {
+			return u.Password == "new_hash"
+		})).Return(nil)
+		mockEventRepo.On("Save", services.PasswordChanged, services.PasswordChangedPayload{UserID: userID()}).Return(nil)
+
+		r := gin.New()
+		newTestPasswordHandler(mockStore, newHasher(), newJwtHelper()).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "old-password", "new_password": "new-password"}`, auth)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUserRepo.AssertExpectations(t)
+		mockEventRepo.AssertExpectations(t)
+		mockTokenRepo.AssertNotCalled(t, "RevokeSession", mock.Anything)
+	})
+
+	t.Run("Revokes other sessions", func(t *testing.T) {
+		mockStore, mockUserRepo, mockTokenRepo, mockEventRepo := newStore()
+		mockUserRepo.On("Save", mock.Anything).Return(nil)
+		mockTokenRepo.On("GetByHash", utils.HashToken("refresh")).
+			Return(&domain.Token{UserID: userID(), SessionID: currentSession}, nil)
+		mockTokenRepo.On("ListByUserID", userID()).Return([]domain.Token{
+			{UserID: userID(), SessionID: currentSession},
+			{UserID: userID(), SessionID: otherSession},
+		}, nil)
+		mockTokenRepo.On("RevokeSession", otherSession).Return(nil)
+		mockEventRepo.On("Save", services.PasswordChanged, services.PasswordChangedPayload{
+			UserID:               userID(),
+			OtherSessionsRevoked: true,
+		}).Return(nil)
+
+		r := gin.New()
+		newTestPasswordHandler(mockStore, newHasher(), newJwtHelper()).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "old-password", "new_password": "new-password",
+			"revoke_other_sessions": true, "refresh_token": "refresh"}`, auth)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockTokenRepo.AssertExpectations(t)
+		mockTokenRepo.AssertNotCalled(t, "RevokeSession", currentSession)
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong current password", func(t *testing.T) {
+		mockStore, mockUserRepo, _, _ := newStore()
+
+		r := gin.New()
+		newTestPasswordHandler(mockStore, newHasher(), newJwtHelper()).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "wrong", "new_password": "new-password"}`, auth)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_CREDENTIALS")
+		mockUserRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("New password contains the email", func(t *testing.T) {
+		mockStore, mockUserRepo, _, _ := newStore()
+		mockUserRepo.ExpectedCalls = nil
+		mockUserRepo.On("GetByID", userID()).
+			Return(&domain.User{ID: userID(), Email: "john@example.com", Password: "old_hash"}, nil)
+
+		r := gin.New()
+		handler := NewPasswordHandler(
+			newTxUow(mockStore),
+			newTestRequestValidator(),
+			middlewares.NewAccessTokenVerifier(newJwtHelper(), denylists.NewMemoryDenylist(), false),
+			nil,
+			services.NewUserService(newHasher(), testPasswordPolicy, false),
+			nil,
+			nil,
+			services.NewOutboxService(),
+		)
+		handler.BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "old-password", "new_password": "john-2026"}`, auth)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"newpassword":"ERR_PASSWORD_PERSONAL_INFO"`)
+		mockUserRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("New password too short", func(t *testing.T) {
+		r := gin.New()
+		newTestPasswordHandler(nil, nil, newJwtHelper()).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "old-password", "new_password": "short"}`, auth)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_PASSWORD_SHORT")
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		r := gin.New()
+		newTestPasswordHandler(nil, nil, newJwtHelper()).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/password/change",
+			`{"current_password": "old-password", "new_password": "new-password"}`, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SMSHandler lets users confirm a phone and then log in with codes sent to
+// it by SMS.
+type SMSHandler struct {
+	uow                 uows.UnitOfWork[stores.Store]
+	requestValidator    *middlewares.RequestValidator
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	rateLimiter         *middlewares.RateLimiter
+	users               *services.UserService
+	tokens              *services.TokenService
+	lockouts            *services.LockoutService
+	mfa                 *services.MFAService
+	smsCodes            *services.SMSCodeService
+	outbox              *services.UserTokenOutboxService
+}
+
+func NewSMSHandler(
+	uow uows.UnitOfWork[stores.Store],
+	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+	users *services.UserService,
+	tokens *services.TokenService,
+	lockouts *services.LockoutService,
+	mfa *services.MFAService,
+	smsCodes *services.SMSCodeService,
+	outbox *services.UserTokenOutboxService,
+) *SMSHandler {
+	return &SMSHandler{
+		uow:                 uow,
+		requestValidator:    requestValidator,
+		accessTokenVerifier: accessTokenVerifier,
+		rateLimiter:         rateLimiter,
+		users:               users,
+		tokens:              tokens,
+		lockouts:            lockouts,
+		mfa:                 mfa,
+		smsCodes:            smsCodes,
+		outbox:              outbox,
+	}
+}
+
+func (h *SMSHandler) BindRoutes(r *gin.RouterGroup) {
+	phone := r.Group("/phone", h.accessTokenVerifier.Handle, h.rateLimiter.Limit("phone"))
+	phone.POST("", h.RequestPhoneVerification)
+	phone.POST("/verify", h.VerifyPhone)
+
+	r.POST("/sms/send", h.rateLimiter.Limit("sms-send"), h.SendLoginCode)
+	r.POST("/sms/login", h.rateLimiter.Limit("sms-login"), h.Login)
+}
+
+// RequestPhoneVerification sends a code to the phone the user wants to add.
+func (h *SMSHandler) RequestPhoneVerification(c *gin.Context) {
+	var req dto.PhoneRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var code string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.users.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		code, err = h.smsCodes.RequestPhoneVerification(txStore, user, req.Phone)
+		return err
+	})
+	if err == nil {
+		err = h.smsCodes.Send(c.Request.Context(), req.Phone, code)
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setSMSError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+// VerifyPhone sets the phone of the user once the code sent to it is
+// confirmed.
+func (h *SMSHandler) VerifyPhone(c *gin.Context) {
+	var req dto.PhoneCodeRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var codeErr error
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.users.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		err = h.smsCodes.ConfirmPhone(txStore, user, req.Phone, req.Code)
+		if errors.Is(err, services.ErrInvalidSMSCode) {
+			// Commit, so the wrong attempt is counted.
+			codeErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SavePhoneVerifiedEvent(txStore, services.PhoneVerifiedPayload{
+			UserID: user.ID,
+			Phone:  req.Phone,
+		})
+	})
+	if err == nil {
+		err = codeErr
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setSMSError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(status, resp)
+}
+
+// SendLoginCode answers the same way whether or not the phone belongs to an
+// account, so it cannot be used to probe for accounts.
+func (h *SMSHandler) SendLoginCode(c *gin.Context) {
+	var req dto.PhoneRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var code string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		var err error
+		code, err = h.smsCodes.RequestLogin(txStore, req.Phone)
+		return err
+	})
+	if err == nil && code != "" {
+		if sendErr := h.smsCodes.Send(c.Request.Context(), req.Phone, code); sendErr != nil {
+			log.Printf("failed to send login code: %v", sendErr)
+		}
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	if err != nil {
+		resp.Errors["error"] = "ERR_INTERNAL"
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(http.StatusOK, resp)
+}
+
+// Login exchanges a code sent to a verified phone for tokens. The code only
+// replaces the password: users with two-factor authentication get an MFA
+// challenge, as from the password login.
+func (h *SMSHandler) Login(c *gin.Context) {
+	var req dto.PhoneCodeRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var accessToken string
+	var refreshToken string
+	var mfaToken string
+	var codeErr error
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.smsCodes.Login(txStore, req.Phone, req.Code)
+		if errors.Is(err, services.ErrInvalidSMSCode) {
+			// Commit, so the wrong attempt is counted.
+			codeErr = err
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := h.lockouts.Check(txStore, user.Email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		mfaEnabled, err := h.mfa.Enabled(txStore, user.ID)
+		if err != nil {
+			return err
+		}
+		if mfaEnabled {
+			mfaToken, err = h.mfa.Challenge(user)
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(txStore, user, clientInfo(c))
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedInEvent(txStore, user)
+	})
+	if err == nil {
+		err = codeErr
+	}
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setSMSError(resp, err)
+		if errors.Is(err, services.ErrAccountLocked) {
+			setRetryAfter(c, err)
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	if mfaToken != "" {
+		resp.Data = dto.MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+	} else {
+		resp.Data = dto.TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		}
+	}
+
+	c.JSON(status, resp)
+}
+
+// setSMSError reports err in resp and returns the status to answer with.
+func setSMSError(resp dto.APIResponse, err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidSMSCode):
+		resp.Errors["error"] = "ERR_INVALID_SMS_CODE"
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrPhoneTaken):
+		resp.Errors["error"] = "ERR_PHONE_TAKEN"
+		return http.StatusConflict
+	case errors.Is(err, services.ErrAccountLocked):
+		resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+		return http.StatusTooManyRequests
+	default:
+		resp.Errors["error"] = "ERR_INTERNAL"
+		return http.StatusInternalServerError
+	}
+}
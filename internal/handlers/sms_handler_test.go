@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/utils"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSMSHandler returns a router with an SMSHandler whose codes are kept by
+// the store mock and whose messages end up in sent.
+func newTestSMSHandler(mockStore *mocks.StoreMock, mockJwtHelper *mocks.JWTHelperMock, sent *[]string) *gin.Engine {
+	var saved *domain.SMSCode
+	mockCodeRepo := new(mocks.SMSCodeRepositoryMock)
+	mockStore.On("SMSCodes").Return(mockCodeRepo)
+	mockCodeRepo.On("DeleteByPhone", mock.Anything, mock.Anything).Return(nil)
+	mockCodeRepo.On("Save", mock.AnythingOfType("*domain.SMSCode")).Return(func(code *domain.SMSCode) error {
+		saved = code
+		return nil
+	})
+	mockCodeRepo.On("GetLatest", mock.Anything, mock.Anything).Return(func(phone, purpose string) (*domain.SMSCode, error) {
+		if saved == nil || saved.Phone != phone || saved.Purpose != purpose {
+			return nil, nil
+		}
+		return saved, nil
+	})
+
+	mockSender := new(mocks.SMSSenderMock)
+	mockSender.On("Send", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			message := args.String(2)
+			*sent = append(*sent, message[strings.LastIndex(message, " ")+1:])
+		}).
+		Return(nil)
+
+	r := gin.New()
+	NewSMSHandler(
+		newTxUow(mockStore),
+		newTestRequestValidator(),
+		middlewares.NewAccessTokenVerifier(mockJwtHelper, denylists.NewMemoryDenylist(), false),
+		nil,
+		services.NewUserService(new(mocks.PasswordHasherMock), nil, false),
+		services.NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		services.NewMFAService(utils.NewTOTP(), testTokenSigner, "Shop", 5*time.Minute),
+		services.NewSMSCodeService(utils.NewBcryptHasher(), mockSender, 5*time.Minute, 3),
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+	return r
+}
+
+func TestSMSHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	phone := "+998901234567"
+	user := &domain.User{ID: userID(), Email: "john@example.com", Phone: &phone}
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockUserRepo.On("GetByPhone", phone).Return(user, nil)
+	mockUserRepo.On("GetByPhone", "+998907654321").Return(nil, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockCredentialRepo.On("Get", user.ID).Return(nil, nil)
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil).Once()
+
+	var sent []string
+	r := newTestSMSHandler(mockStore, mockJwtHelper, &sent)
+
+	t.Run("Invalid phone", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/sms/send", `{"phone": "901234567"}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_PHONE")
+	})
+
+	known := performRequest(r, "POST", "/auth/sms/send", `{"phone": "+998901234567"}`, nil)
+	unknown := performRequest(r, "POST", "/auth/sms/send", `{"phone": "+998907654321"}`, nil)
+
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	require.Len(t, sent, 1)
+	code := sent[0]
+
+	t.Run("Wrong code", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/sms/login",
+			`{"phone": "+998901234567", "code": "`+wrongSMSCode(code)+`"}`, nil)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_SMS_CODE")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/sms/login", `{"phone": "+998901234567", "code": "`+code+`"}`, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data dto.TokenResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "jwt_token", body.Data.AccessToken)
+		assert.NotEmpty(t, body.Data.RefreshToken)
+		mockEventRepo.AssertExpectations(t)
+	})
+}
+
+func TestSMSHandler_VerifyPhone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	auth := map[string]string{"Authorization": "Bearer access"}
+	user := &domain.User{ID: userID(), Email: "john@example.com"}
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockUserRepo.On("GetByPhone", "+998901234567").Return(nil, nil)
+	mockUserRepo.On("Save", user).Return(nil)
+	mockEventRepo.On("Save", services.PhoneVerified, services.PhoneVerifiedPayload{
+		UserID: user.ID,
+		Phone:  "+998901234567",
+	}).Return(nil).Once()
+	mockJwtHelper.On("ParseAccessToken", "access").Return(&utils.Claims{UserID: user.ID.String()}, nil)
+
+	var sent []string
+	r := newTestSMSHandler(mockStore, mockJwtHelper, &sent)
+
+	w := performRequest(r, "POST", "/auth/phone", `{"phone": "+998901234567"}`, auth)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, sent, 1)
+
+	w = performRequest(r, "POST", "/auth/phone/verify", `{"phone": "+998901234567", "code": "`+sent[0]+`"}`, auth)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, user.Phone)
+	assert.Equal(t, "+998901234567", *user.Phone)
+	assert.NotNil(t, user.PhoneVerifiedAt)
+	mockEventRepo.AssertExpectations(t)
+}
+
+func wrongSMSCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
@@ -14,30 +14,44 @@ import (
 )
 
 type UserHandler struct {
-	uow              uows.UnitOfWork[*stores.UserTokenOutboxStore]
-	requestValidator *middlewares.RequestValidator
-	userService      *services.UserService
-	outboxService    *services.UserTokenOutboxService
+	uow                 uows.UnitOfWork[stores.Store]
+	requestValidator    *middlewares.RequestValidator
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	rateLimiter         *middlewares.RateLimiter
+	userService         *services.UserService
+	tokenService        *services.TokenService
+	verificationService *services.EmailVerificationService
+	outboxService       *services.UserTokenOutboxService
 }
 
 func NewUserHandler(
-	uow uows.UnitOfWork[*stores.UserTokenOutboxStore],
+	uow uows.UnitOfWork[stores.Store],
 	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
 	userService *services.UserService,
+	tokenService *services.TokenService,
+	verificationService *services.EmailVerificationService,
 	outboxService *services.UserTokenOutboxService,
 ) *UserHandler {
 	return &UserHandler{
-		requestValidator: requestValidator,
-		uow:              uow,
-		userService:      userService,
-		outboxService:    outboxService,
+		requestValidator:    requestValidator,
+		accessTokenVerifier: accessTokenVerifier,
+		rateLimiter:         rateLimiter,
+		uow:                 uow,
+		userService:         userService,
+		tokenService:        tokenService,
+		verificationService: verificationService,
+		outboxService:       outboxService,
 	}
 }
 
 func (h *UserHandler) BindRoutes(r *gin.RouterGroup) {
-	r.POST("/register", h.Register)
-	r.POST("/promote-to-seller", h.PromoteToSeller)
-	r.GET("/me", h.GetMe)
+	r.POST("/register", h.rateLimiter.Limit("register"), h.Register)
+	r.POST("/verify-email", h.rateLimiter.Limit("verify-email"), h.VerifyEmail)
+	r.POST("/resend-verification", h.rateLimiter.Limit("resend-verification"), h.ResendVerification)
+	r.POST("/promote-to-seller", h.accessTokenVerifier.Handle, h.PromoteToSeller)
+	r.GET("/me", h.accessTokenVerifier.Handle, h.GetMe)
 }
 
 func (h *UserHandler) Register(c *gin.Context) {
@@ -47,7 +61,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 	var user *domain.User
 	var err error
-	err = h.uow.DoTransaction(func(txStore *stores.UserTokenOutboxStore) error {
+	err = h.uow.DoTransaction(func(txStore stores.Store) error {
 		user, err = h.userService.Register(
 			txStore,
 			req.Name,
@@ -59,7 +73,16 @@ func (h *UserHandler) Register(c *gin.Context) {
 			return err
 		}
 
-		return h.outboxService.SaveUserRegisteredEvent(txStore, user)
+		if err = h.outboxService.SaveUserRegisteredEvent(txStore, user); err != nil {
+			return err
+		}
+
+		verification, err := h.verificationService.RequestVerification(user)
+		if err != nil {
+			return err
+		}
+
+		return h.outboxService.SaveVerificationRequestedEvent(txStore, verification)
 	})
 
 	resp := dto.APIResponse{
@@ -88,11 +111,83 @@ func (h *UserHandler) Register(c *gin.Context) {
 	c.JSON(status, resp)
 }
 
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var user *domain.User
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		var err error
+		user, err = h.verificationService.VerifyEmail(txStore, req.Token)
+		if err != nil {
+			return err
+		}
+
+		return h.outboxService.SaveEmailVerifiedEvent(txStore, user)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			resp.Errors["error"] = "ERR_INVALID_TOKEN"
+			status = http.StatusBadRequest
+		default:
+			resp.Errors["error"] = "ERR_INTERNAL"
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.UserResponse{
+		User: user,
+	}
+
+	c.JSON(status, resp)
+}
+
+// ResendVerification answers the same way whether or not the email belongs to
+// an unverified account, so it cannot be used to probe for accounts.
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	var req dto.ResendVerificationRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		verification, err := h.verificationService.ResendVerification(txStore, req.Email)
+		if err != nil || verification == nil {
+			return err
+		}
+
+		return h.outboxService.SaveVerificationRequestedEvent(txStore, verification)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+
+	if err != nil {
+		resp.Errors["error"] = "ERR_INTERNAL"
+		c.JSON(http.StatusInternalServerError, resp)
+		return
+	}
+
+	resp.Success = true
+	c.JSON(http.StatusOK, resp)
+}
+
 func (h *UserHandler) PromoteToSeller(c *gin.Context) {
-	userID := c.GetHeader("X-User-Id")
+	userID := currentUserID(c)
 	var user *domain.User
 	var err error
-	err = h.uow.DoTransaction(func(txStore *stores.UserTokenOutboxStore) error {
+	err = h.uow.DoTransaction(func(txStore stores.Store) error {
 		user, err = h.userService.PromoteToSeller(
 			txStore,
 			userID,
@@ -103,6 +198,10 @@ func (h *UserHandler) PromoteToSeller(c *gin.Context) {
 
 		return err
 	})
+	if err == nil {
+		// The token still lists the old roles; make the client refresh it.
+		err = revokeCurrentAccessToken(c, h.tokenService)
+	}
 
 	resp := dto.APIResponse{
 		Errors: make(map[string]string),
@@ -131,11 +230,11 @@ func (h *UserHandler) PromoteToSeller(c *gin.Context) {
 }
 
 func (h *UserHandler) GetMe(c *gin.Context) {
-	userID := c.GetHeader("X-User-Id")
+	userID := currentUserID(c)
 
 	var user *domain.User
 	var err error
-	err = h.uow.DoTransaction(func(txStore *stores.UserTokenOutboxStore) error {
+	err = h.uow.DoTransaction(func(txStore stores.Store) error {
 		user, err = h.userService.GetByID(
 			txStore,
 			userID,
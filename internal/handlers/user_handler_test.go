@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testTokenSigner = utils.NewHMACTokenSigner([]byte("secret"))
+
+func newTestUserHandler(store stores.Store, hasher utils.PasswordHasher) *UserHandler {
+	return NewUserHandler(
+		newTxUow(store),
+		newTestRequestValidator(),
+		nil,
+		nil,
+		services.NewUserService(hasher, nil, false),
+		nil,
+		services.NewEmailVerificationService(testTokenSigner, time.Hour),
+		services.NewOutboxService(),
+	)
+}
+
+func TestUserHandler_Register(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		r := gin.New()
+		handler := newTestUserHandler(nil, nil)
+		handler.BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/register", `{"email": "john@example.com"`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"success":false`)
+	})
+
+	t.Run("User already exists", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockHasher := new(mocks.PasswordHasherMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockUserRepo.On("GetByEmail", "john@example.com").Return(&domain.User{}, nil)
+
+		r := gin.New()
+		handler := newTestUserHandler(mockStore, mockHasher)
+		handler.BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/register",
+			`{"email": "john@example.com", "password": "correct-horse-42", "name": "Jamol", "surname": "Jackson"}`, nil)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_USER_EXISTS")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+		mockHasher := new(mocks.PasswordHasherMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+
+		mockUserRepo.On("GetByEmail", "john@example.com").Return(nil, nil)
+		mockUserRepo.On("Save", mock.Anything).Return(nil)
+		mockEventRepo.On("Save", services.UserRegistered, mock.Anything).Return(nil)
+		mockEventRepo.On("Save", services.VerificationRequested, mock.MatchedBy(
+			func(payload *services.VerificationRequestedPayload) bool {
+				return payload.Email == "john@example.com" && payload.Token != ""
+			},
+		)).Return(nil)
+		mockHasher.On("Hash", "correct-horse-42").Return("hashed_password", nil)
+
+		r := gin.New()
+		handler := newTestUserHandler(mockStore, mockHasher)
+		handler.BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/register",
+			`{"email": "john@example.com", "password": "correct-horse-42", "name": "Jamol", "surname": "Jackson"}`, nil)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "john@example.com")
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Password policy", func(t *testing.T) {
+		tests := []struct {
+			password string
+			code     string
+		}{
+			{password: "s3cret", code: "ERR_PASSWORD_SHORT"},
+			{password: strings.Repeat("ab1", 25), code: "ERR_PASSWORD_LONG"},
+			{password: "onlyletters", code: "ERR_PASSWORD_WEAK"},
+			{password: "password123", code: "ERR_PASSWORD_BREACHED"},
+			{password: "john-is-great", code: "ERR_PASSWORD_PERSONAL_INFO"},
+			{password: "Jackson-2026", code: "ERR_PASSWORD_PERSONAL_INFO"},
+		}
+
+		r := gin.New()
+		newTestUserHandler(nil, nil).BindRoutes(r.Group("/auth"))
+
+		for _, tt := range tests {
+			w := performRequest(r, "POST", "/auth/register",
+				`{"email": "john@example.com", "password": "`+tt.password+`", "name": "Jamol", "surname": "Jackson"}`, nil)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, tt.password)
+			assert.Contains(t, w.Body.String(), `"password":"`+tt.code+`"`, tt.password)
+		}
+	})
+}
+
+func TestUserHandler_VerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newUser := func() *domain.User {
+		return &domain.User{ID: userID(), Email: "john@example.com"}
+	}
+	verification, err := services.NewEmailVerificationService(testTokenSigner, time.Hour).
+		RequestVerification(newUser())
+	assert.NoError(t, err)
+	body := `{"token": "` + verification.Token + `"}`
+
+	t.Run("Success", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockEventRepo := new(mocks.EventRepositoryMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Outbox").Return(mockEventRepo)
+		mockUserRepo.On("GetByID", userID()).Return(newUser(), nil)
+		mockUserRepo.On("Save", mock.MatchedBy(func(u *domain.User) bool {
+			return u.EmailVerifiedAt != nil
+		})).Return(nil)
+		mockEventRepo.On("Save", services.EmailVerified, mock.Anything).Return(nil)
+
+		r := gin.New()
+		newTestUserHandler(mockStore, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/verify-email", body, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already verified", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+
+		verified := newUser()
+		verifiedAt := time.Now()
+		verified.EmailVerifiedAt = &verifiedAt
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockUserRepo.On("GetByID", userID()).Return(verified, nil)
+
+		r := gin.New()
+		newTestUserHandler(mockStore, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/verify-email", body, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+
+	t.Run("Email changed since the token was sent", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+
+		changed := newUser()
+		changed.Email = "jane@example.com"
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockUserRepo.On("GetByID", userID()).Return(changed, nil)
+
+		r := gin.New()
+		newTestUserHandler(mockStore, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/verify-email", body, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+
+	t.Run("Forged token", func(t *testing.T) {
+		r := gin.New()
+		newTestUserHandler(nil, nil).BindRoutes(r.Group("/auth"))
+
+		w := performRequest(r, "POST", "/auth/verify-email", `{"token": "forged.token"}`, nil)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+}
+
+func TestUserHandler_ResendVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(&domain.User{ID: userID(), Email: "john@example.com"}, nil)
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, nil)
+	mockEventRepo.On("Save", services.VerificationRequested, mock.Anything).Return(nil).Once()
+
+	r := gin.New()
+	newTestUserHandler(mockStore, nil).BindRoutes(r.Group("/auth"))
+
+	known := performRequest(r, "POST", "/auth/resend-verification", `{"email": "john@example.com"}`, nil)
+	unknown := performRequest(r, "POST", "/auth/resend-verification", `{"email": "nobody@example.com"}`, nil)
+
+	assert.Equal(t, http.StatusOK, known.Code)
+	assert.Equal(t, known.Code, unknown.Code)
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	mockEventRepo.AssertExpectations(t)
+}
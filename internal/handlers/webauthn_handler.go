@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/middlewares"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/uows"
+	"app/internal/webauthn"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebAuthnHandler runs the ceremonies that register passkeys and log in with
+// them. Each ceremony takes two requests: the options request returns a
+// session token that the finishing request sends back.
+type WebAuthnHandler struct {
+	uow                 uows.UnitOfWork[stores.Store]
+	requestValidator    *middlewares.RequestValidator
+	accessTokenVerifier *middlewares.AccessTokenVerifier
+	rateLimiter         *middlewares.RateLimiter
+	users               *services.UserService
+	tokens              *services.TokenService
+	lockouts            *services.LockoutService
+	webAuthn            *services.WebAuthnService
+	outbox              *services.UserTokenOutboxService
+}
+
+func NewWebAuthnHandler(
+	uow uows.UnitOfWork[stores.Store],
+	requestValidator *middlewares.RequestValidator,
+	accessTokenVerifier *middlewares.AccessTokenVerifier,
+	rateLimiter *middlewares.RateLimiter,
+	users *services.UserService,
+	tokens *services.TokenService,
+	lockouts *services.LockoutService,
+	webAuthn *services.WebAuthnService,
+	outbox *services.UserTokenOutboxService,
+) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		uow:                 uow,
+		requestValidator:    requestValidator,
+		accessTokenVerifier: accessTokenVerifier,
+		rateLimiter:         rateLimiter,
+		users:               users,
+		tokens:              tokens,
+		lockouts:            lockouts,
+		webAuthn:            webAuthn,
+		outbox:              outbox,
+	}
+}
+
+func (h *WebAuthnHandler) BindRoutes(r *gin.RouterGroup) {
+	webAuthn := r.Group("/webauthn")
+
+	register := webAuthn.Group("/register", h.accessTokenVerifier.Handle)
+	register.POST("/options", h.RegistrationOptions)
+	register.POST("/finish", h.FinishRegistration)
+
+	login := webAuthn.Group("/login", h.rateLimiter.Limit("webauthn-login"))
+	login.POST("/options", h.LoginOptions)
+	login.POST("/finish", h.FinishLogin)
+}
+
+func (h *WebAuthnHandler) RegistrationOptions(c *gin.Context) {
+	var options *webauthn.CreationOptions
+	var session string
+	err := h.uow.Do(func(store stores.Store) error {
+		user, err := h.users.GetByID(store, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		options, session, err = h.webAuthn.BeginRegistration(store, user)
+		return err
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setWebAuthnError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.WebAuthnOptionsResponse{
+		PublicKey: options,
+		Session:   session,
+	}
+
+	c.JSON(status, resp)
+}
+
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	var req dto.WebAuthnRegistrationRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var credential *domain.WebAuthnCredential
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.users.GetByID(txStore, currentUserID(c))
+		if err != nil {
+			return err
+		}
+
+		credential, err = h.webAuthn.FinishRegistration(txStore, user, req.Session, req.Name, req.Credential)
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveWebAuthnCredentialRegisteredEvent(txStore, services.WebAuthnCredentialRegisteredPayload{
+			UserID:       user.ID,
+			CredentialID: credential.ID,
+			Name:         credential.Name,
+		})
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setWebAuthnError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.WebAuthnCredentialResponse{
+		ID:   credential.ID,
+		Name: credential.Name,
+	}
+
+	c.JSON(status, resp)
+}
+
+func (h *WebAuthnHandler) LoginOptions(c *gin.Context) {
+	var options *webauthn.RequestOptions
+	var session string
+	err := h.uow.Do(func(store stores.Store) error {
+		var err error
+		options, session, err = h.webAuthn.BeginLogin(store)
+		return err
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setWebAuthnError(resp, err)
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.WebAuthnOptionsResponse{
+		PublicKey: options,
+		Session:   session,
+	}
+
+	c.JSON(status, resp)
+}
+
+// FinishLogin verifies the assertion and issues tokens. A passkey that
+// verified the user counts as both factors, so no MFA challenge follows.
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	var req dto.WebAuthnLoginRequest
+	if !h.requestValidator.ValidateRequest(c, &req) {
+		return
+	}
+	var accessToken string
+	var refreshToken string
+	err := h.uow.DoTransaction(func(txStore stores.Store) error {
+		user, err := h.webAuthn.FinishLogin(txStore, req.Session, req.Credential)
+		if err != nil {
+			return err
+		}
+
+		if err := h.lockouts.Check(txStore, user.Email, c.ClientIP()); err != nil {
+			return err
+		}
+
+		accessToken, refreshToken, err = h.tokens.IssueTokenForUser(txStore, user, clientInfo(c))
+		if err != nil {
+			return err
+		}
+
+		return h.outbox.SaveUserLoggedInEvent(txStore, user)
+	})
+
+	resp := dto.APIResponse{
+		Errors: make(map[string]string),
+	}
+	status := http.StatusOK
+
+	if err != nil {
+		status = setWebAuthnError(resp, err)
+		if errors.Is(err, services.ErrAccountLocked) {
+			setRetryAfter(c, err)
+		}
+		c.JSON(status, resp)
+		return
+	}
+
+	resp.Success = true
+	resp.Data = dto.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+
+	c.JSON(status, resp)
+}
+
+// setWebAuthnError reports err in resp and returns the status to answer with.
+func setWebAuthnError(resp dto.APIResponse, err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidToken):
+		resp.Errors["error"] = "ERR_INVALID_TOKEN"
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrInvalidWebAuthnResponse):
+		resp.Errors["error"] = "ERR_INVALID_WEBAUTHN_RESPONSE"
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrWebAuthnCredentialExists):
+		resp.Errors["error"] = "ERR_WEBAUTHN_CREDENTIAL_EXISTS"
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidCredentials):
+		resp.Errors["error"] = "ERR_INVALID_CREDENTIALS"
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrAccountLocked):
+		resp.Errors["error"] = "ERR_ACCOUNT_LOCKED"
+		return http.StatusTooManyRequests
+	default:
+		resp.Errors["error"] = "ERR_INTERNAL"
+		return http.StatusInternalServerError
+	}
+}
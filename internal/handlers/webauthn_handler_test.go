@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/dto"
+	"app/internal/mocks"
+	"app/internal/services"
+	"app/internal/stores"
+	"app/internal/utils"
+	"app/internal/webauthn"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnHandler_Login(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The assertion was recorded for the rp id "localhost" by a passkey whose
+	// user handle is userID().
+	data, err := os.ReadFile(filepath.Join("..", "webauthn", "testdata", "assertion_packed_self.json"))
+	require.NoError(t, err)
+	var fixture struct {
+		Challenge  string          `json:"challenge"`
+		PublicKey  string          `json:"public_key"`
+		SignCount  uint32          `json:"sign_count"`
+		Credential json.RawMessage `json:"credential"`
+	}
+	require.NoError(t, json.Unmarshal(data, &fixture))
+	var credential webauthn.AssertionCredential
+	require.NoError(t, json.Unmarshal(fixture.Credential, &credential))
+	publicKey, err := base64.RawURLEncoding.DecodeString(fixture.PublicKey)
+	require.NoError(t, err)
+
+	user := &domain.User{ID: userID(), Email: "john@example.com"}
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockCredentialRepo := new(mocks.WebAuthnCredentialRepositoryMock)
+	mockChallengeRepo := new(mocks.WebAuthnChallengeRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockStore.On("Outbox").Return(mockEventRepo)
+	mockStore.On("WebAuthnCredentials").Return(mockCredentialRepo)
+	mockStore.On("WebAuthnChallenges").Return(mockChallengeRepo)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(func([]byte) (*domain.WebAuthnCredential, error) {
+		return &domain.WebAuthnCredential{
+			UserID: user.ID, CredentialID: credential.RawID, PublicKey: publicKey, SignCount: fixture.SignCount,
+		}, nil
+	})
+	mockCredentialRepo.On("Save", mock.AnythingOfType("*domain.WebAuthnCredential")).Return(nil)
+	mockChallengeRepo.On("DeleteExpired", mock.Anything).Return(nil)
+	mockChallengeRepo.On("Save", mock.AnythingOfType("*domain.WebAuthnChallenge")).Return(nil)
+	// Only the recorded challenge is known, and only until it is consumed.
+	recorded := &domain.WebAuthnChallenge{
+		ID:            1,
+		ChallengeHash: utils.HashToken(fixture.Challenge),
+		Purpose:       "webauthn_login",
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+	mockChallengeRepo.On("GetByHash", recorded.ChallengeHash).Return(recorded, nil).Once()
+	mockChallengeRepo.On("GetByHash", mock.Anything).Return(nil, nil)
+	mockChallengeRepo.On("Delete", recorded.ID).Return(nil).Once()
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), mock.Anything, mock.Anything).Return("jwt_token", nil)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Return(nil)
+	mockEventRepo.On("Save", services.UserLoggedIn, user).Return(nil).Once()
+
+	mockUow := newTxUow(mockStore)
+	mockUow.On("Do", mock.Anything).Return(func(fn func(stores.Store) error) error {
+		return fn(mockStore)
+	})
+
+	rp := &webauthn.RelyingParty{
+		ID:                      "localhost",
+		Name:                    "Shop",
+		Origins:                 []string{"http://localhost:8080"},
+		RequireUserVerification: true,
+	}
+	r := gin.New()
+	NewWebAuthnHandler(
+		mockUow,
+		newTestRequestValidator(),
+		nil,
+		nil,
+		services.NewUserService(new(mocks.PasswordHasherMock), nil, false),
+		services.NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist()),
+		services.NewLockoutService(services.LockoutPolicy{}),
+		services.NewWebAuthnService(rp, testTokenSigner, 5*time.Minute),
+		services.NewOutboxService(),
+	).BindRoutes(r.Group("/auth"))
+
+	t.Run("Options", func(t *testing.T) {
+		w := performRequest(r, "POST", "/auth/webauthn/login/options", "", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data struct {
+				PublicKey webauthn.RequestOptions `json:"publicKey"`
+				Session   string                  `json:"session"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "localhost", body.Data.PublicKey.RPID)
+		assert.Equal(t, "required", body.Data.PublicKey.UserVerification)
+		assert.Empty(t, body.Data.PublicKey.AllowCredentials)
+		assert.NotEmpty(t, body.Data.Session)
+	})
+
+	finish := func(session string) *httptest.ResponseRecorder {
+		req, err := json.Marshal(map[string]interface{}{
+			"session":    session,
+			"credential": fixture.Credential,
+		})
+		require.NoError(t, err)
+		return performRequest(r, "POST", "/auth/webauthn/login/finish", string(req), nil)
+	}
+
+	t.Run("Invalid session", func(t *testing.T) {
+		w := finish("forged")
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		session, err := testTokenSigner.Sign(utils.SignedTokenClaims{
+			Purpose:   "webauthn_login",
+			Nonce:     fixture.Challenge,
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		})
+		require.NoError(t, err)
+
+		w := finish(session)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data dto.TokenResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "jwt_token", body.Data.AccessToken)
+		assert.NotEmpty(t, body.Data.RefreshToken)
+		mockEventRepo.AssertExpectations(t)
+	})
+
+	t.Run("Replayed session", func(t *testing.T) {
+		session, err := testTokenSigner.Sign(utils.SignedTokenClaims{
+			Purpose:   "webauthn_login",
+			Nonce:     fixture.Challenge,
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		})
+		require.NoError(t, err)
+
+		w := finish(session)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "ERR_INVALID_TOKEN")
+		mockChallengeRepo.AssertExpectations(t)
+	})
+}
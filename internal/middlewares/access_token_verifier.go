@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"app/internal/denylists"
+	"app/internal/dto"
+	"app/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jamoliddin05/auth-service/pkg/authz"
+)
+
+// claimsKey is shared with authz.RequirePermission, which reads the claims
+// stored here.
+const claimsKey = authz.ClaimsKey
+
+// AccessTokenVerifier authenticates requests with the bearer tokens issued by
+// utils.JWTManager and stores the parsed claims in the gin context. Tokens whose
+// jti is on the denylist, or issued before the denylist entry of their subject,
+// are rejected even if they have not expired yet.
+//
+// When trustUserHeader is enabled, requests without a bearer token fall back to
+// the X-User-Id header. That mode is only meant for deployments where a gateway
+// already verified the token and nothing else can reach the service.
+type AccessTokenVerifier struct {
+	jwt             utils.JWTHelper
+	denylist        denylists.Denylist
+	trustUserHeader bool
+}
+
+func NewAccessTokenVerifier(
+	jwt utils.JWTHelper,
+	denylist denylists.Denylist,
+	trustUserHeader bool,
+) *AccessTokenVerifier {
+	return &AccessTokenVerifier{jwt: jwt, denylist: denylist, trustUserHeader: trustUserHeader}
+}
+
+func (v *AccessTokenVerifier) Handle(c *gin.Context) {
+	var errResp dto.APIResponse
+	errResp.Success = false
+	errResp.Errors = make(map[string]string)
+
+	tokenString, ok := bearerToken(c)
+	if !ok {
+		if userID := c.GetHeader("X-User-Id"); v.trustUserHeader && userID != "" {
+			c.Set(claimsKey, &utils.Claims{UserID: userID})
+			c.Next()
+			return
+		}
+
+		errResp.Errors["error"] = "ERR_UNAUTHORIZED"
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	claims, err := v.jwt.ParseAccessToken(tokenString)
+	if err != nil {
+		errResp.Errors["error"] = "ERR_INVALID_TOKEN"
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	revoked, err := denylists.Revoked(c.Request.Context(), v.denylist, claims)
+	if err != nil {
+		errResp.Errors["error"] = "ERR_INTERNAL"
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errResp)
+		return
+	}
+	if revoked {
+		errResp.Errors["error"] = "ERR_INVALID_TOKEN"
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errResp)
+		return
+	}
+
+	c.Set(claimsKey, claims)
+	c.Next()
+}
+
+// Optional stores the claims of a valid bearer token like Handle, but lets
+// requests without one, or with an invalid one, through unauthenticated.
+func (v *AccessTokenVerifier) Optional(c *gin.Context) {
+	tokenString, ok := bearerToken(c)
+	if !ok {
+		c.Next()
+		return
+	}
+
+	claims, err := v.jwt.ParseAccessToken(tokenString)
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	if revoked, err := denylists.Revoked(c.Request.Context(), v.denylist, claims); err == nil && !revoked {
+		c.Set(claimsKey, claims)
+	}
+	c.Next()
+}
+
+// ClaimsFromContext returns the claims stored by AccessTokenVerifier.
+func ClaimsFromContext(c *gin.Context) (*utils.Claims, bool) {
+	value, ok := c.Get(claimsKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := value.(*utils.Claims)
+	return claims, ok
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	header := c.GetHeader("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
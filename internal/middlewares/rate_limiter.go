@@ -0,0 +1,201 @@
+package middlewares
+
+import (
+	"app/internal/dto"
+	"app/internal/ratelimits"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey is what a rate limit rule counts requests by.
+type RateLimitKey string
+
+const (
+	RateLimitByIP    RateLimitKey = "ip"
+	RateLimitByEmail RateLimitKey = "email"
+	RateLimitByUser  RateLimitKey = "user"
+	RateLimitByPhone RateLimitKey = "phone"
+)
+
+// RateLimitRule allows Limit requests per Window for every value of Key.
+type RateLimitRule struct {
+	Key    RateLimitKey
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimiter limits the requests of the routes it has rules for. A request
+// must pass every rule of its route.
+type RateLimiter struct {
+	store ratelimits.Store
+	rules map[string][]RateLimitRule
+}
+
+func NewRateLimiter(store ratelimits.Store, rules map[string][]RateLimitRule) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		rules: rules,
+	}
+}
+
+// Limit returns the middleware for the rules of route. Routes without rules,
+// or a nil RateLimiter, are not limited. Rules keyed by user must run after
+// AccessTokenVerifier.Handle.
+func (l *RateLimiter) Limit(route string) gin.HandlerFunc {
+	if l == nil || len(l.rules[route]) == 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	rules := l.rules[route]
+
+	return func(c *gin.Context) {
+		var reported *ratelimits.Result
+		for _, rule := range rules {
+			value := rateLimitKeyValue(c, rule.Key)
+			if value == "" {
+				continue
+			}
+
+			key := fmt.Sprintf("%s:%s:%s", route, rule.Key, value)
+			result, err := l.store.Hit(c.Request.Context(), key, rule.Limit, rule.Window)
+			if err != nil {
+				log.Printf("rate limiter: failed to count %s: %v", key, err)
+				continue
+			}
+
+			// Report the rule closest to its limit, or the first one hit.
+			if reported == nil || (reported.Allowed && (!result.Allowed || result.Remaining < reported.Remaining)) {
+				reported = &result
+			}
+		}
+
+		if reported == nil {
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(reported.Reset.Seconds())))
+		c.Header("RateLimit-Limit", strconv.Itoa(reported.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(reported.Remaining))
+		c.Header("RateLimit-Reset", reset)
+
+		if !reported.Allowed {
+			c.Header("Retry-After", reset)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, dto.APIResponse{
+				Errors: map[string]string{"error": "ERR_RATE_LIMITED"},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func rateLimitKeyValue(c *gin.Context, key RateLimitKey) string {
+	switch key {
+	case RateLimitByIP:
+		return c.ClientIP()
+	case RateLimitByEmail:
+		return strings.ToLower(strings.TrimSpace(requestField(c, "email")))
+	case RateLimitByPhone:
+		return strings.TrimSpace(requestField(c, "phone"))
+	case RateLimitByUser:
+		if claims, ok := ClaimsFromContext(c); ok {
+			return claims.UserID
+		}
+	}
+	return ""
+}
+
+// maxKeyedBodySize caps the body requestField reads. Limited routes take small
+// JSON bodies, larger ones are cut and fail to bind in the handler.
+const maxKeyedBodySize = 64 << 10
+
+// requestField reads a string field of a JSON body and puts the body back for
+// the handler.
+func requestField(c *gin.Context, name string) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxKeyedBodySize))
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var req map[string]json.RawMessage
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	var value string
+	if err := json.Unmarshal(req[name], &value); err != nil {
+		return ""
+	}
+	return value
+}
+
+// ParseRateLimitRules parses rules written as
+// "login=ip:20/1m,email:5/1m;register=ip:5/1h": the rules of every route,
+// separated by semicolons, each one being key:limit/window.
+func ParseRateLimitRules(spec string) (map[string][]RateLimitRule, error) {
+	rules := make(map[string][]RateLimitRule)
+	for _, routeSpec := range strings.Split(spec, ";") {
+		routeSpec = strings.TrimSpace(routeSpec)
+		if routeSpec == "" {
+			continue
+		}
+
+		route, ruleSpecs, ok := strings.Cut(routeSpec, "=")
+		route = strings.TrimSpace(route)
+		if !ok || route == "" {
+			return nil, fmt.Errorf("invalid rate limit %q: expected route=rules", routeSpec)
+		}
+
+		for _, ruleSpec := range strings.Split(ruleSpecs, ",") {
+			rule, err := parseRateLimitRule(strings.TrimSpace(ruleSpec))
+			if err != nil {
+				return nil, fmt.Errorf("invalid rate limit for %s: %w", route, err)
+			}
+			rules[route] = append(rules[route], rule)
+		}
+	}
+	return rules, nil
+}
+
+func parseRateLimitRule(spec string) (RateLimitRule, error) {
+	key, rate, ok := strings.Cut(spec, ":")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("%q: expected key:limit/window", spec)
+	}
+
+	rule := RateLimitRule{Key: RateLimitKey(key)}
+	switch rule.Key {
+	case RateLimitByIP, RateLimitByEmail, RateLimitByUser, RateLimitByPhone:
+	default:
+		return RateLimitRule{}, fmt.Errorf("%q: unknown key %q", spec, key)
+	}
+
+	limit, window, ok := strings.Cut(rate, "/")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("%q: expected key:limit/window", spec)
+	}
+
+	var err error
+	if rule.Limit, err = strconv.Atoi(limit); err != nil || rule.Limit <= 0 {
+		return RateLimitRule{}, fmt.Errorf("%q: invalid limit %q", spec, limit)
+	}
+	if rule.Window, err = time.ParseDuration(window); err != nil || rule.Window <= 0 {
+		return RateLimitRule{}, fmt.Errorf("%q: invalid window %q", spec, window)
+	}
+	return rule, nil
+}
@@ -0,0 +1,219 @@
+package middlewares
+
+import (
+	"app/internal/ratelimits"
+	"app/internal/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimitRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		rules map[string][]RateLimitRule
+		err   bool
+	}{
+		{
+			name: "Several routes and rules",
+			spec: "login=ip:20/1m,email:5/1m;register=ip:5/1h",
+			rules: map[string][]RateLimitRule{
+				"login": {
+					{Key: RateLimitByIP, Limit: 20, Window: time.Minute},
+					{Key: RateLimitByEmail, Limit: 5, Window: time.Minute},
+				},
+				"register": {{Key: RateLimitByIP, Limit: 5, Window: time.Hour}},
+			},
+		},
+		{
+			name: "Spaces and empty routes",
+			spec: " mfa = user:10/15m ;; ",
+			rules: map[string][]RateLimitRule{
+				"mfa": {{Key: RateLimitByUser, Limit: 10, Window: 15 * time.Minute}},
+			},
+		},
+		{name: "Empty", spec: "", rules: map[string][]RateLimitRule{}},
+		{name: "Missing rules", spec: "login", err: true},
+		{name: "Missing route", spec: "=ip:5/1m", err: true},
+		{name: "Unknown key", spec: "login=cookie:5/1m", err: true},
+		{name: "Missing window", spec: "login=ip:5", err: true},
+		{name: "Zero limit", spec: "login=ip:0/1m", err: true},
+		{name: "Invalid window", spec: "login=ip:5/minute", err: true},
+		{name: "Negative window", spec: "login=ip:5/-1m", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRateLimitRules(tt.spec)
+
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
+
+func TestRateLimiter_Limit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rules, err := ParseRateLimitRules("login=ip:3/1m,email:1/1m;mfa=user:1/1m")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		route    string
+		requests []string
+		claims   *utils.Claims
+		codes    []int
+	}{
+		{
+			name:     "Route without rules",
+			route:    "register",
+			requests: []string{`{}`, `{}`, `{}`, `{}`},
+			codes:    []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "Strictest rule wins",
+			route:    "login",
+			requests: []string{`{"email": "john@example.com"}`, `{"email": "john@example.com"}`},
+			codes:    []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "Emails are counted case-insensitively",
+			route:    "login",
+			requests: []string{`{"email": "john@example.com"}`, `{"email": " John@Example.com "}`},
+			codes:    []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:  "Every rule must pass",
+			route: "login",
+			requests: []string{
+				`{"email": "a@example.com"}`,
+				`{"email": "b@example.com"}`,
+				`{"email": "c@example.com"}`,
+				`{"email": "d@example.com"}`,
+			},
+			codes: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:     "Requests without the keyed field skip its rule",
+			route:    "login",
+			requests: []string{`{}`, `not json`, `{"email": 42}`},
+			codes:    []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "User rules without claims",
+			route:    "mfa",
+			requests: []string{`{}`, `{}`},
+			codes:    []int{http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "User rules",
+			route:    "mfa",
+			requests: []string{`{}`, `{}`},
+			claims:   &utils.Claims{UserID: "11111111-1111-1111-1111-111111111111"},
+			codes:    []int{http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(ratelimits.NewMemoryStore(), rules)
+
+			r := gin.New()
+			r.POST("/", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set(claimsKey, tt.claims)
+				}
+			}, limiter.Limit(tt.route), func(c *gin.Context) {
+				// The handler still gets the whole body.
+				body, _ := io.ReadAll(c.Request.Body)
+				c.String(http.StatusOK, string(body))
+			})
+
+			for i, body := range tt.requests {
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+
+				assert.Equal(t, tt.codes[i], w.Code, "request %d", i+1)
+				if w.Code == http.StatusOK {
+					assert.Equal(t, body, w.Body.String())
+				} else {
+					assert.NotEmpty(t, w.Header().Get("Retry-After"))
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_NilLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var limiter *RateLimiter
+	r := gin.New()
+	r.POST("/", limiter.Limit("login"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestRateLimiter_LargeBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(ratelimits.NewMemoryStore(), map[string][]RateLimitRule{
+		"login": {{Key: RateLimitByEmail, Limit: 1, Window: time.Minute}},
+	})
+
+	var read int
+	r := gin.New()
+	r.POST("/login", limiter.Limit("login"), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		read = len(body)
+		c.Status(http.StatusNoContent)
+	})
+
+	body := `{"email": "john@example.com", "padding": "` + strings.Repeat("a", 2*maxKeyedBodySize) + `"}`
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/login", strings.NewReader(body)))
+
+	// The body is cut at the cap, so it is neither buffered whole nor parsed.
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, maxKeyedBodySize, read)
+}
+
+func TestRateLimiter_ForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(ratelimits.NewMemoryStore(), map[string][]RateLimitRule{
+		"login": {{Key: RateLimitByIP, Limit: 1, Window: time.Minute}},
+	})
+
+	r := gin.New()
+	assert.NoError(t, r.SetTrustedProxies(nil))
+	r.POST("/login", limiter.Limit("login"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	login := func(forwardedFor string) int {
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.RemoteAddr = "203.0.113.7:4711"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, login("198.51.100.1"))
+	// A new X-Forwarded-For from the same peer still counts against its IP.
+	assert.Equal(t, http.StatusTooManyRequests, login("198.51.100.2"))
+}
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuthorizationCodeRepositoryMock is an autogenerated mock type for the AuthorizationCodeRepository type
+type AuthorizationCodeRepositoryMock struct {
+	mock.Mock
+}
+
+// GetByHash provides a mock function with given fields: hash
+func (_m *AuthorizationCodeRepositoryMock) GetByHash(hash string) (*domain.AuthorizationCode, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *domain.AuthorizationCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.AuthorizationCode, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.AuthorizationCode); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.AuthorizationCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: code
+func (_m *AuthorizationCodeRepositoryMock) Save(code *domain.AuthorizationCode) error {
+	ret := _m.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.AuthorizationCode) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAuthorizationCodeRepositoryMock creates a new instance of AuthorizationCodeRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuthorizationCodeRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuthorizationCodeRepositoryMock {
+	mock := &AuthorizationCodeRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,76 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ClientRepositoryMock is an autogenerated mock type for the ClientRepository type
+type ClientRepositoryMock struct {
+	mock.Mock
+}
+
+// GetByClientID provides a mock function with given fields: clientID
+func (_m *ClientRepositoryMock) GetByClientID(clientID string) (*domain.Client, error) {
+	ret := _m.Called(clientID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByClientID")
+	}
+
+	var r0 *domain.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.Client, error)); ok {
+		return rf(clientID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.Client); ok {
+		r0 = rf(clientID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(clientID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: client
+func (_m *ClientRepositoryMock) Save(client *domain.Client) error {
+	ret := _m.Called(client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.Client) error); ok {
+		r0 = rf(client)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewClientRepositoryMock creates a new instance of ClientRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewClientRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ClientRepositoryMock {
+	mock := &ClientRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,122 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// DenylistMock is an autogenerated mock type for the Denylist type
+type DenylistMock struct {
+	mock.Mock
+}
+
+// Add provides a mock function with given fields: ctx, jti, ttl
+func (_m *DenylistMock) Add(ctx context.Context, jti string, ttl time.Duration) error {
+	ret := _m.Called(ctx, jti, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = rf(ctx, jti, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AddSubject provides a mock function with given fields: ctx, subject, issuedBefore, ttl
+func (_m *DenylistMock) AddSubject(ctx context.Context, subject string, issuedBefore time.Time, ttl time.Duration) error {
+	ret := _m.Called(ctx, subject, issuedBefore, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSubject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Duration) error); ok {
+		r0 = rf(ctx, subject, issuedBefore, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Contains provides a mock function with given fields: ctx, jti
+func (_m *DenylistMock) Contains(ctx context.Context, jti string) (bool, error) {
+	ret := _m.Called(ctx, jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Contains")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ContainsSubject provides a mock function with given fields: ctx, subject, issuedAt
+func (_m *DenylistMock) ContainsSubject(ctx context.Context, subject string, issuedAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, subject, issuedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ContainsSubject")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, subject, issuedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, subject, issuedAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, subject, issuedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDenylistMock creates a new instance of DenylistMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDenylistMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DenylistMock {
+	mock := &DenylistMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -2,16 +2,40 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+
+	utils "app/internal/utils"
+)
 
 // JWTHelperMock is an autogenerated mock type for the JWTHelper type
 type JWTHelperMock struct {
 	mock.Mock
 }
 
-// GenerateAccessToken provides a mock function with given fields: userID, roles
-func (_m *JWTHelperMock) GenerateAccessToken(userID string, roles []string) (string, error) {
-	ret := _m.Called(userID, roles)
+// AccessTokenTTL provides a mock function with no fields
+func (_m *JWTHelperMock) AccessTokenTTL() time.Duration {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AccessTokenTTL")
+	}
+
+	var r0 time.Duration
+	if rf, ok := ret.Get(0).(func() time.Duration); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+
+	return r0
+}
+
+// GenerateAccessToken provides a mock function with given fields: userID, roles, permissions
+func (_m *JWTHelperMock) GenerateAccessToken(userID string, roles []string, permissions []string) (string, error) {
+	ret := _m.Called(userID, roles, permissions)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateAccessToken")
@@ -19,17 +43,103 @@ func (_m *JWTHelperMock) GenerateAccessToken(userID string, roles []string) (str
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, []string) (string, error)); ok {
-		return rf(userID, roles)
+	if rf, ok := ret.Get(0).(func(string, []string, []string) (string, error)); ok {
+		return rf(userID, roles, permissions)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string, []string) string); ok {
+		r0 = rf(userID, roles, permissions)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string, []string) error); ok {
+		r1 = rf(userID, roles, permissions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateClientAccessToken provides a mock function with given fields: clientID, scope
+func (_m *JWTHelperMock) GenerateClientAccessToken(clientID string, scope string) (string, error) {
+	ret := _m.Called(clientID, scope)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateClientAccessToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(clientID, scope)
 	}
-	if rf, ok := ret.Get(0).(func(string, []string) string); ok {
-		r0 = rf(userID, roles)
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(clientID, scope)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
-		r1 = rf(userID, roles)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(clientID, scope)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateDelegatedAccessToken provides a mock function with given fields: userID, roles, permissions, clientID, scope
+func (_m *JWTHelperMock) GenerateDelegatedAccessToken(userID string, roles []string, permissions []string, clientID string, scope string) (string, error) {
+	ret := _m.Called(userID, roles, permissions, clientID, scope)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateDelegatedAccessToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string, []string, string, string) (string, error)); ok {
+		return rf(userID, roles, permissions, clientID, scope)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string, []string, string, string) string); ok {
+		r0 = rf(userID, roles, permissions, clientID, scope)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string, []string, string, string) error); ok {
+		r1 = rf(userID, roles, permissions, clientID, scope)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseAccessToken provides a mock function with given fields: tokenString
+func (_m *JWTHelperMock) ParseAccessToken(tokenString string) (*utils.Claims, error) {
+	ret := _m.Called(tokenString)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ParseAccessToken")
+	}
+
+	var r0 *utils.Claims
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*utils.Claims, error)); ok {
+		return rf(tokenString)
+	}
+	if rf, ok := ret.Get(0).(func(string) *utils.Claims); ok {
+		r0 = rf(tokenString)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*utils.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tokenString)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,94 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// LoginLockoutRepositoryMock is an autogenerated mock type for the LoginLockoutRepository type
+type LoginLockoutRepositoryMock struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: key
+func (_m *LoginLockoutRepositoryMock) Delete(key string) error {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: key
+func (_m *LoginLockoutRepositoryMock) Get(key string) (*domain.LoginLockout, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.LoginLockout
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.LoginLockout, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.LoginLockout); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.LoginLockout)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: lockout
+func (_m *LoginLockoutRepositoryMock) Save(lockout *domain.LoginLockout) error {
+	ret := _m.Called(lockout)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.LoginLockout) error); ok {
+		r0 = rf(lockout)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewLoginLockoutRepositoryMock creates a new instance of LoginLockoutRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginLockoutRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginLockoutRepositoryMock {
+	mock := &LoginLockoutRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
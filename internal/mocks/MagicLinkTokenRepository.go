@@ -0,0 +1,96 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// MagicLinkTokenRepositoryMock is an autogenerated mock type for the MagicLinkTokenRepository type
+type MagicLinkTokenRepositoryMock struct {
+	mock.Mock
+}
+
+// DeleteByUser provides a mock function with given fields: userID
+func (_m *MagicLinkTokenRepositoryMock) DeleteByUser(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetBySelector provides a mock function with given fields: selector
+func (_m *MagicLinkTokenRepositoryMock) GetBySelector(selector string) (*domain.MagicLinkToken, error) {
+	ret := _m.Called(selector)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySelector")
+	}
+
+	var r0 *domain.MagicLinkToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.MagicLinkToken, error)); ok {
+		return rf(selector)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.MagicLinkToken); ok {
+		r0 = rf(selector)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.MagicLinkToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(selector)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: token
+func (_m *MagicLinkTokenRepositoryMock) Save(token *domain.MagicLinkToken) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.MagicLinkToken) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewMagicLinkTokenRepositoryMock creates a new instance of MagicLinkTokenRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMagicLinkTokenRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MagicLinkTokenRepositoryMock {
+	mock := &MagicLinkTokenRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -10,7 +10,7 @@ type PasswordHasherMock struct {
 }
 
 // Hash provides a mock function with given fields: password
-func (_m *PasswordHasherMock) Hash(password string) string {
+func (_m *PasswordHasherMock) Hash(password string) (string, error) {
 	ret := _m.Called(password)
 
 	if len(ret) == 0 {
@@ -18,12 +18,40 @@ func (_m *PasswordHasherMock) Hash(password string) string {
 	}
 
 	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(password)
+	}
 	if rf, ok := ret.Get(0).(func(string) string); ok {
 		r0 = rf(password)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NeedsRehash provides a mock function with given fields: hash
+func (_m *PasswordHasherMock) NeedsRehash(hash string) bool {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NeedsRehash")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
 	return r0
 }
 
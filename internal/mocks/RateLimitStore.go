@@ -0,0 +1,59 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	ratelimits "app/internal/ratelimits"
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// RateLimitStoreMock is an autogenerated mock type for the Store type
+type RateLimitStoreMock struct {
+	mock.Mock
+}
+
+// Hit provides a mock function with given fields: ctx, key, limit, window
+func (_m *RateLimitStoreMock) Hit(ctx context.Context, key string, limit int, window time.Duration) (ratelimits.Result, error) {
+	ret := _m.Called(ctx, key, limit, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Hit")
+	}
+
+	var r0 ratelimits.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) (ratelimits.Result, error)); ok {
+		return rf(ctx, key, limit, window)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Duration) ratelimits.Result); ok {
+		r0 = rf(ctx, key, limit, window)
+	} else {
+		r0 = ret.Get(0).(ratelimits.Result)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, time.Duration) error); ok {
+		r1 = rf(ctx, key, limit, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRateLimitStoreMock creates a new instance of RateLimitStoreMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRateLimitStoreMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RateLimitStoreMock {
+	mock := &RateLimitStoreMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
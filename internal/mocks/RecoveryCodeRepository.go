@@ -0,0 +1,114 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// RecoveryCodeRepositoryMock is an autogenerated mock type for the RecoveryCodeRepository type
+type RecoveryCodeRepositoryMock struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: codes
+func (_m *RecoveryCodeRepositoryMock) Create(codes []domain.RecoveryCode) error {
+	ret := _m.Called(codes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]domain.RecoveryCode) error); ok {
+		r0 = rf(codes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteByUser provides a mock function with given fields: userID
+func (_m *RecoveryCodeRepositoryMock) DeleteByUser(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetUnused provides a mock function with given fields: userID, hash
+func (_m *RecoveryCodeRepositoryMock) GetUnused(userID uuid.UUID, hash string) (*domain.RecoveryCode, error) {
+	ret := _m.Called(userID, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUnused")
+	}
+
+	var r0 *domain.RecoveryCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) (*domain.RecoveryCode, error)); ok {
+		return rf(userID, hash)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) *domain.RecoveryCode); ok {
+		r0 = rf(userID, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.RecoveryCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, string) error); ok {
+		r1 = rf(userID, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: code
+func (_m *RecoveryCodeRepositoryMock) Save(code *domain.RecoveryCode) error {
+	ret := _m.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.RecoveryCode) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRecoveryCodeRepositoryMock creates a new instance of RecoveryCodeRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRecoveryCodeRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RecoveryCodeRepositoryMock {
+	mock := &RecoveryCodeRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,54 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// RoleRepositoryMock is an autogenerated mock type for the RoleRepository type
+type RoleRepositoryMock struct {
+	mock.Mock
+}
+
+// PermissionsOf provides a mock function with given fields: roles
+func (_m *RoleRepositoryMock) PermissionsOf(roles []string) ([]string, error) {
+	ret := _m.Called(roles)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PermissionsOf")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]string, error)); ok {
+		return rf(roles)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []string); ok {
+		r0 = rf(roles)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(roles)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRoleRepositoryMock creates a new instance of RoleRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRoleRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RoleRepositoryMock {
+	mock := &RoleRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,94 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SMSCodeRepositoryMock is an autogenerated mock type for the SMSCodeRepository type
+type SMSCodeRepositoryMock struct {
+	mock.Mock
+}
+
+// DeleteByPhone provides a mock function with given fields: phone, purpose
+func (_m *SMSCodeRepositoryMock) DeleteByPhone(phone string, purpose string) error {
+	ret := _m.Called(phone, purpose)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByPhone")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(phone, purpose)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetLatest provides a mock function with given fields: phone, purpose
+func (_m *SMSCodeRepositoryMock) GetLatest(phone string, purpose string) (*domain.SMSCode, error) {
+	ret := _m.Called(phone, purpose)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatest")
+	}
+
+	var r0 *domain.SMSCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*domain.SMSCode, error)); ok {
+		return rf(phone, purpose)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *domain.SMSCode); ok {
+		r0 = rf(phone, purpose)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.SMSCode)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(phone, purpose)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: code
+func (_m *SMSCodeRepositoryMock) Save(code *domain.SMSCode) error {
+	ret := _m.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.SMSCode) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSMSCodeRepositoryMock creates a new instance of SMSCodeRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSMSCodeRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SMSCodeRepositoryMock {
+	mock := &SMSCodeRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,46 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SMSSenderMock is an autogenerated mock type for the SMSSender type
+type SMSSenderMock struct {
+	mock.Mock
+}
+
+// Send provides a mock function with given fields: ctx, phone, message
+func (_m *SMSSenderMock) Send(ctx context.Context, phone string, message string) error {
+	ret := _m.Called(ctx, phone, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, phone, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewSMSSenderMock creates a new instance of SMSSenderMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSMSSenderMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SMSSenderMock {
+	mock := &SMSSenderMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -13,6 +13,86 @@ type StoreMock struct {
 	mock.Mock
 }
 
+// AuthorizationCodes provides a mock function with no fields
+func (_m *StoreMock) AuthorizationCodes() repositories.AuthorizationCodeRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizationCodes")
+	}
+
+	var r0 repositories.AuthorizationCodeRepository
+	if rf, ok := ret.Get(0).(func() repositories.AuthorizationCodeRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.AuthorizationCodeRepository)
+		}
+	}
+
+	return r0
+}
+
+// Clients provides a mock function with no fields
+func (_m *StoreMock) Clients() repositories.ClientRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Clients")
+	}
+
+	var r0 repositories.ClientRepository
+	if rf, ok := ret.Get(0).(func() repositories.ClientRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.ClientRepository)
+		}
+	}
+
+	return r0
+}
+
+// LoginLockouts provides a mock function with no fields
+func (_m *StoreMock) LoginLockouts() repositories.LoginLockoutRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginLockouts")
+	}
+
+	var r0 repositories.LoginLockoutRepository
+	if rf, ok := ret.Get(0).(func() repositories.LoginLockoutRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.LoginLockoutRepository)
+		}
+	}
+
+	return r0
+}
+
+// MagicLinkTokens provides a mock function with no fields
+func (_m *StoreMock) MagicLinkTokens() repositories.MagicLinkTokenRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MagicLinkTokens")
+	}
+
+	var r0 repositories.MagicLinkTokenRepository
+	if rf, ok := ret.Get(0).(func() repositories.MagicLinkTokenRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.MagicLinkTokenRepository)
+		}
+	}
+
+	return r0
+}
+
 // Outbox provides a mock function with no fields
 func (_m *StoreMock) Outbox() repositories.EventRepository {
 	ret := _m.Called()
@@ -33,6 +113,106 @@ func (_m *StoreMock) Outbox() repositories.EventRepository {
 	return r0
 }
 
+// PasswordResetTokens provides a mock function with no fields
+func (_m *StoreMock) PasswordResetTokens() repositories.PasswordResetTokenRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PasswordResetTokens")
+	}
+
+	var r0 repositories.PasswordResetTokenRepository
+	if rf, ok := ret.Get(0).(func() repositories.PasswordResetTokenRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.PasswordResetTokenRepository)
+		}
+	}
+
+	return r0
+}
+
+// RecoveryCodes provides a mock function with no fields
+func (_m *StoreMock) RecoveryCodes() repositories.RecoveryCodeRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecoveryCodes")
+	}
+
+	var r0 repositories.RecoveryCodeRepository
+	if rf, ok := ret.Get(0).(func() repositories.RecoveryCodeRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.RecoveryCodeRepository)
+		}
+	}
+
+	return r0
+}
+
+// Roles provides a mock function with no fields
+func (_m *StoreMock) Roles() repositories.RoleRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Roles")
+	}
+
+	var r0 repositories.RoleRepository
+	if rf, ok := ret.Get(0).(func() repositories.RoleRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.RoleRepository)
+		}
+	}
+
+	return r0
+}
+
+// SMSCodes provides a mock function with no fields
+func (_m *StoreMock) SMSCodes() repositories.SMSCodeRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SMSCodes")
+	}
+
+	var r0 repositories.SMSCodeRepository
+	if rf, ok := ret.Get(0).(func() repositories.SMSCodeRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.SMSCodeRepository)
+		}
+	}
+
+	return r0
+}
+
+// TOTPCredentials provides a mock function with no fields
+func (_m *StoreMock) TOTPCredentials() repositories.TOTPCredentialRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TOTPCredentials")
+	}
+
+	var r0 repositories.TOTPCredentialRepository
+	if rf, ok := ret.Get(0).(func() repositories.TOTPCredentialRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.TOTPCredentialRepository)
+		}
+	}
+
+	return r0
+}
+
 // Tokens provides a mock function with no fields
 func (_m *StoreMock) Tokens() repositories.TokenRepository {
 	ret := _m.Called()
@@ -53,6 +233,26 @@ func (_m *StoreMock) Tokens() repositories.TokenRepository {
 	return r0
 }
 
+// UserIdentities provides a mock function with no fields
+func (_m *StoreMock) UserIdentities() repositories.UserIdentityRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserIdentities")
+	}
+
+	var r0 repositories.UserIdentityRepository
+	if rf, ok := ret.Get(0).(func() repositories.UserIdentityRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.UserIdentityRepository)
+		}
+	}
+
+	return r0
+}
+
 // Users provides a mock function with no fields
 func (_m *StoreMock) Users() repositories.UserRepository {
 	ret := _m.Called()
@@ -73,6 +273,46 @@ func (_m *StoreMock) Users() repositories.UserRepository {
 	return r0
 }
 
+// WebAuthnChallenges provides a mock function with no fields
+func (_m *StoreMock) WebAuthnChallenges() repositories.WebAuthnChallengeRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for WebAuthnChallenges")
+	}
+
+	var r0 repositories.WebAuthnChallengeRepository
+	if rf, ok := ret.Get(0).(func() repositories.WebAuthnChallengeRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.WebAuthnChallengeRepository)
+		}
+	}
+
+	return r0
+}
+
+// WebAuthnCredentials provides a mock function with no fields
+func (_m *StoreMock) WebAuthnCredentials() repositories.WebAuthnCredentialRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for WebAuthnCredentials")
+	}
+
+	var r0 repositories.WebAuthnCredentialRepository
+	if rf, ok := ret.Get(0).(func() repositories.WebAuthnCredentialRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(repositories.WebAuthnCredentialRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewStoreMock creates a new instance of StoreMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStoreMock(t interface {
@@ -0,0 +1,96 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// TOTPCredentialRepositoryMock is an autogenerated mock type for the TOTPCredentialRepository type
+type TOTPCredentialRepositoryMock struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: userID
+func (_m *TOTPCredentialRepositoryMock) Delete(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Get provides a mock function with given fields: userID
+func (_m *TOTPCredentialRepositoryMock) Get(userID uuid.UUID) (*domain.TOTPCredential, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *domain.TOTPCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*domain.TOTPCredential, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *domain.TOTPCredential); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.TOTPCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: credential
+func (_m *TOTPCredentialRepositoryMock) Save(credential *domain.TOTPCredential) error {
+	ret := _m.Called(credential)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.TOTPCredential) error); ok {
+		r0 = rf(credential)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewTOTPCredentialRepositoryMock creates a new instance of TOTPCredentialRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTOTPCredentialRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TOTPCredentialRepositoryMock {
+	mock := &TOTPCredentialRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -6,6 +6,8 @@ import (
 	domain "app/internal/domain"
 
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // TokenRepositoryMock is an autogenerated mock type for the TokenRepository type
@@ -32,7 +34,7 @@ func (_m *TokenRepositoryMock) Delete(id uint) error {
 }
 
 // DeleteByUser provides a mock function with given fields: userID
-func (_m *TokenRepositoryMock) DeleteByUser(userID string) error {
+func (_m *TokenRepositoryMock) DeleteByUser(userID uuid.UUID) error {
 	ret := _m.Called(userID)
 
 	if len(ret) == 0 {
@@ -40,7 +42,7 @@ func (_m *TokenRepositoryMock) DeleteByUser(userID string) error {
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
 		r0 = rf(userID)
 	} else {
 		r0 = ret.Error(0)
@@ -109,28 +111,58 @@ func (_m *TokenRepositoryMock) GetByID(id uint) (*domain.Token, error) {
 	return r0, r1
 }
 
-// GetByUserID provides a mock function with given fields: userID
-func (_m *TokenRepositoryMock) GetByUserID(userID string) ([]*domain.Token, error) {
+// GetBySessionID provides a mock function with given fields: sessionID
+func (_m *TokenRepositoryMock) GetBySessionID(sessionID uuid.UUID) (*domain.Token, error) {
+	ret := _m.Called(sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBySessionID")
+	}
+
+	var r0 *domain.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) (*domain.Token, error)); ok {
+		return rf(sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) *domain.Token); ok {
+		r0 = rf(sessionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByUserID provides a mock function with given fields: userID
+func (_m *TokenRepositoryMock) ListByUserID(userID uuid.UUID) ([]domain.Token, error) {
 	ret := _m.Called(userID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetByUserID")
+		panic("no return value specified for ListByUserID")
 	}
 
-	var r0 []*domain.Token
+	var r0 []domain.Token
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*domain.Token, error)); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]domain.Token, error)); ok {
 		return rf(userID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*domain.Token); ok {
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []domain.Token); ok {
 		r0 = rf(userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*domain.Token)
+			r0 = ret.Get(0).([]domain.Token)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
 		r1 = rf(userID)
 	} else {
 		r1 = ret.Error(1)
@@ -139,6 +171,42 @@ func (_m *TokenRepositoryMock) GetByUserID(userID string) ([]*domain.Token, erro
 	return r0, r1
 }
 
+// RevokeByUser provides a mock function with given fields: userID
+func (_m *TokenRepositoryMock) RevokeByUser(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeSession provides a mock function with given fields: sessionID
+func (_m *TokenRepositoryMock) RevokeSession(sessionID uuid.UUID) error {
+	ret := _m.Called(sessionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(sessionID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: token
 func (_m *TokenRepositoryMock) Save(token *domain.Token) error {
 	ret := _m.Called(token)
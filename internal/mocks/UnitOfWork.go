@@ -2,29 +2,23 @@
 
 package mocks
 
-import (
-	repositories "app/internal/repositories"
-
-	mock "github.com/stretchr/testify/mock"
-
-	stores "app/internal/stores"
-)
+import mock "github.com/stretchr/testify/mock"
 
 // UnitOfWorkMock is an autogenerated mock type for the UnitOfWork type
-type UnitOfWorkMock struct {
+type UnitOfWorkMock[T interface{}] struct {
 	mock.Mock
 }
 
-// DoLogin provides a mock function with given fields: fn
-func (_m *UnitOfWorkMock) DoLogin(fn func(repositories.TokenRepository, repositories.EventRepository) error) error {
+// Do provides a mock function with given fields: fn
+func (_m *UnitOfWorkMock[T]) Do(fn func(T) error) error {
 	ret := _m.Called(fn)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DoLogin")
+		panic("no return value specified for Do")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(func(repositories.TokenRepository, repositories.EventRepository) error) error); ok {
+	if rf, ok := ret.Get(0).(func(func(T) error) error); ok {
 		r0 = rf(fn)
 	} else {
 		r0 = ret.Error(0)
@@ -33,16 +27,16 @@ func (_m *UnitOfWorkMock) DoLogin(fn func(repositories.TokenRepository, reposito
 	return r0
 }
 
-// DoRegistration provides a mock function with given fields: fn
-func (_m *UnitOfWorkMock) DoRegistration(fn func(repositories.UserRepository, repositories.EventRepository) error) error {
+// DoTransaction provides a mock function with given fields: fn
+func (_m *UnitOfWorkMock[T]) DoTransaction(fn func(T) error) error {
 	ret := _m.Called(fn)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DoRegistration")
+		panic("no return value specified for DoTransaction")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(func(repositories.UserRepository, repositories.EventRepository) error) error); ok {
+	if rf, ok := ret.Get(0).(func(func(T) error) error); ok {
 		r0 = rf(fn)
 	} else {
 		r0 = ret.Error(0)
@@ -51,33 +45,13 @@ func (_m *UnitOfWorkMock) DoRegistration(fn func(repositories.UserRepository, re
 	return r0
 }
 
-// Store provides a mock function with no fields
-func (_m *UnitOfWorkMock) Store() stores.Store {
-	ret := _m.Called()
-
-	if len(ret) == 0 {
-		panic("no return value specified for Store")
-	}
-
-	var r0 stores.Store
-	if rf, ok := ret.Get(0).(func() stores.Store); ok {
-		r0 = rf()
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(stores.Store)
-		}
-	}
-
-	return r0
-}
-
 // NewUnitOfWorkMock creates a new instance of UnitOfWorkMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewUnitOfWorkMock(t interface {
+func NewUnitOfWorkMock[T interface{}](t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *UnitOfWorkMock {
-	mock := &UnitOfWorkMock{}
+}) *UnitOfWorkMock[T] {
+	mock := &UnitOfWorkMock[T]{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
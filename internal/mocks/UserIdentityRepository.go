@@ -0,0 +1,96 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// UserIdentityRepositoryMock is an autogenerated mock type for the UserIdentityRepository type
+type UserIdentityRepositoryMock struct {
+	mock.Mock
+}
+
+// DeleteByUser provides a mock function with given fields: userID
+func (_m *UserIdentityRepositoryMock) DeleteByUser(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByProviderSubject provides a mock function with given fields: provider, subject
+func (_m *UserIdentityRepositoryMock) GetByProviderSubject(provider string, subject string) (*domain.UserIdentity, error) {
+	ret := _m.Called(provider, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByProviderSubject")
+	}
+
+	var r0 *domain.UserIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*domain.UserIdentity, error)); ok {
+		return rf(provider, subject)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *domain.UserIdentity); ok {
+		r0 = rf(provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.UserIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: identity
+func (_m *UserIdentityRepositoryMock) Save(identity *domain.UserIdentity) error {
+	ret := _m.Called(identity)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.UserIdentity) error); ok {
+		r0 = rf(identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewUserIdentityRepositoryMock creates a new instance of UserIdentityRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserIdentityRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserIdentityRepositoryMock {
+	mock := &UserIdentityRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
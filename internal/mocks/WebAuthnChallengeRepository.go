@@ -0,0 +1,114 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WebAuthnChallengeRepositoryMock is an autogenerated mock type for the WebAuthnChallengeRepository type
+type WebAuthnChallengeRepositoryMock struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *WebAuthnChallengeRepositoryMock) Delete(id uint) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpired provides a mock function with given fields: now
+func (_m *WebAuthnChallengeRepositoryMock) DeleteExpired(now time.Time) error {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(time.Time) error); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByHash provides a mock function with given fields: challengeHash
+func (_m *WebAuthnChallengeRepositoryMock) GetByHash(challengeHash string) (*domain.WebAuthnChallenge, error) {
+	ret := _m.Called(challengeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *domain.WebAuthnChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*domain.WebAuthnChallenge, error)); ok {
+		return rf(challengeHash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *domain.WebAuthnChallenge); ok {
+		r0 = rf(challengeHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WebAuthnChallenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(challengeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: challenge
+func (_m *WebAuthnChallengeRepositoryMock) Save(challenge *domain.WebAuthnChallenge) error {
+	ret := _m.Called(challenge)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.WebAuthnChallenge) error); ok {
+		r0 = rf(challenge)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebAuthnChallengeRepositoryMock creates a new instance of WebAuthnChallengeRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebAuthnChallengeRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebAuthnChallengeRepositoryMock {
+	mock := &WebAuthnChallengeRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
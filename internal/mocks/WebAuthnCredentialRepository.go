@@ -0,0 +1,126 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	domain "app/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// WebAuthnCredentialRepositoryMock is an autogenerated mock type for the WebAuthnCredentialRepository type
+type WebAuthnCredentialRepositoryMock struct {
+	mock.Mock
+}
+
+// DeleteByUser provides a mock function with given fields: userID
+func (_m *WebAuthnCredentialRepositoryMock) DeleteByUser(userID uuid.UUID) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByCredentialID provides a mock function with given fields: credentialID
+func (_m *WebAuthnCredentialRepositoryMock) GetByCredentialID(credentialID []byte) (*domain.WebAuthnCredential, error) {
+	ret := _m.Called(credentialID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCredentialID")
+	}
+
+	var r0 *domain.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]byte) (*domain.WebAuthnCredential, error)); ok {
+		return rf(credentialID)
+	}
+	if rf, ok := ret.Get(0).(func([]byte) *domain.WebAuthnCredential); ok {
+		r0 = rf(credentialID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]byte) error); ok {
+		r1 = rf(credentialID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListByUser provides a mock function with given fields: userID
+func (_m *WebAuthnCredentialRepositoryMock) ListByUser(userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []domain.WebAuthnCredential
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) ([]domain.WebAuthnCredential, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID) []domain.WebAuthnCredential); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.WebAuthnCredential)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Save provides a mock function with given fields: credential
+func (_m *WebAuthnCredentialRepositoryMock) Save(credential *domain.WebAuthnCredential) error {
+	ret := _m.Called(credential)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*domain.WebAuthnCredential) error); ok {
+		r0 = rf(credential)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebAuthnCredentialRepositoryMock creates a new instance of WebAuthnCredentialRepositoryMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebAuthnCredentialRepositoryMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebAuthnCredentialRepositoryMock {
+	mock := &WebAuthnCredentialRepositoryMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,35 @@
+package publishers
+
+import (
+	"app/internal/domain"
+	"context"
+	"sync"
+)
+
+// MemoryPublisher keeps published events in memory. It is meant for local
+// development and tests where no broker is available.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []domain.Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event domain.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []domain.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make([]domain.Event, len(p.events))
+	copy(events, p.events)
+	return events
+}
@@ -0,0 +1,11 @@
+package publishers
+
+import (
+	"app/internal/domain"
+	"context"
+)
+
+//go:generate mockery --name=Publisher --output=../mocks --structname=PublisherMock
+type Publisher interface {
+	Publish(ctx context.Context, event domain.Event) error
+}
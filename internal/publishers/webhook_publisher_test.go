@@ -0,0 +1,50 @@
+package publishers
+
+import (
+	"app/internal/domain"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookPublisher_Publish_Success(t *testing.T) {
+	var received webhookMessage
+	var eventType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		eventType = r.Header.Get("X-Event-Type")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	publisher := NewWebhookPublisher(srv.URL, srv.Client())
+	err := publisher.Publish(context.Background(), domain.Event{
+		ID:      42,
+		Type:    "UserRegistered",
+		Payload: `{"email":"john@example.com"}`,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "UserRegistered", eventType)
+	assert.Equal(t, int64(42), received.ID)
+	assert.JSONEq(t, `{"email":"john@example.com"}`, string(received.Payload))
+}
+
+func TestWebhookPublisher_Publish_Non2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	publisher := NewWebhookPublisher(srv.URL, srv.Client())
+	err := publisher.Publish(context.Background(), domain.Event{ID: 1, Type: "UserLoggedIn", Payload: `{}`})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
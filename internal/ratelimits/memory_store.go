@@ -0,0 +1,54 @@
+package ratelimits
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the counters in memory. They are not shared between
+// instances, so it is only meant for single instance deployments and tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	now     func() time.Time
+}
+
+type memoryWindow struct {
+	start    time.Time
+	length   time.Duration
+	previous int
+	current  int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		windows: make(map[string]*memoryWindow),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Hit(_ context.Context, key string, limit int, window time.Duration) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, w := range s.windows {
+		if !w.start.Add(2 * w.length).After(now) {
+			delete(s.windows, k)
+		}
+	}
+
+	start := now.Truncate(window)
+	w, ok := s.windows[key]
+	switch {
+	case !ok || w.length != window:
+		w = &memoryWindow{start: start, length: window}
+		s.windows[key] = w
+	case start.After(w.start):
+		w.start, w.previous, w.current = start, w.current, 0
+	}
+	w.current++
+
+	return evaluate(w.previous, w.current, start, now, limit, window), nil
+}
@@ -0,0 +1,50 @@
+package ratelimits
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	for i := 1; i <= 3; i++ {
+		result, err := store.Hit(ctx, "ip:1.2.3.4", 3, time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3-i, result.Remaining)
+		assert.Equal(t, time.Minute, result.Reset)
+	}
+
+	result, _ := store.Hit(ctx, "ip:1.2.3.4", 3, time.Minute)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// Other keys have their own counters.
+	result, _ = store.Hit(ctx, "ip:5.6.7.8", 3, time.Minute)
+	assert.True(t, result.Allowed)
+
+	// Halfway through the next window half of the previous hits still count.
+	now = now.Add(90 * time.Second)
+	result, _ = store.Hit(ctx, "ip:1.2.3.4", 3, time.Minute)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 30*time.Second, result.Reset)
+
+	result, _ = store.Hit(ctx, "ip:1.2.3.4", 3, time.Minute)
+	assert.False(t, result.Allowed)
+
+	// Windows that no longer weigh in are dropped.
+	now = now.Add(2 * time.Minute)
+	result, _ = store.Hit(ctx, "ip:1.2.3.4", 3, time.Minute)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.NotContains(t, store.windows, "ip:5.6.7.8")
+}
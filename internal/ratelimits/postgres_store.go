@@ -0,0 +1,62 @@
+package ratelimits
+
+import (
+	"app/internal/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore keeps the counters in the rate_limit_windows table, so every
+// instance shares the same windows. Purge deletes the windows that no longer
+// weigh in; run it periodically with a workers.PurgeWorker.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{
+		db: db,
+	}
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, limit int, window time.Duration) (Result, error) {
+	now := time.Now().UTC()
+	start := now.Truncate(window)
+
+	var current int
+	err := s.db.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_windows (key, window_start, count, expires_at)
+		VALUES (?, ?, 1, ?)
+		ON CONFLICT (key, window_start) DO UPDATE SET count = rate_limit_windows.count + 1
+		RETURNING count`,
+		key, start, start.Add(2*window),
+	).Scan(&current).Error
+	if err != nil {
+		return Result{}, err
+	}
+
+	var previous []int
+	err = s.db.WithContext(ctx).
+		Model(&domain.RateLimitWindow{}).
+		Where("key = ? AND window_start = ?", key, start.Add(-window)).
+		Pluck("count", &previous).Error
+	if err != nil {
+		return Result{}, err
+	}
+
+	var prev int
+	if len(previous) > 0 {
+		prev = previous[0]
+	}
+
+	return evaluate(prev, current, start, now, limit, window), nil
+}
+
+// Purge deletes the windows that no longer weigh in by now.
+func (s *PostgresStore) Purge(ctx context.Context) error {
+	return s.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now().UTC()).
+		Delete(&domain.RateLimitWindow{}).Error
+}
@@ -0,0 +1,44 @@
+package ratelimits
+
+import (
+	"context"
+	"time"
+)
+
+// Store counts hits per key in a sliding window. The window is approximated
+// from two fixed windows: the hits of the previous one are weighted by how much
+// of it still overlaps the sliding window.
+//
+//go:generate mockery --name=Store --output=../mocks --structname=RateLimitStoreMock --filename=RateLimitStore.go
+type Store interface {
+	// Hit counts a hit for key and reports whether it is within limit.
+	Hit(ctx context.Context, key string, limit int, window time.Duration) (Result, error)
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the current fixed window ends.
+	Reset time.Duration
+}
+
+// evaluate weighs the hits of the previous and current fixed window at now.
+// current already includes the hit being evaluated.
+func evaluate(previous, current int, windowStart, now time.Time, limit int, window time.Duration) Result {
+	elapsed := now.Sub(windowStart)
+	weight := float64(window-elapsed) / float64(window)
+	count := int(float64(previous)*weight) + current
+
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     window - elapsed,
+	}
+}
@@ -0,0 +1,134 @@
+package relays
+
+import (
+	"app/internal/publishers"
+	"app/internal/repositories"
+	"app/internal/uows"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// OutboxRelay drains the events table in batches and hands every event to a
+// Publisher. Events are marked as processed only after they were published, so
+// delivery is at-least-once.
+type OutboxRelay struct {
+	uow          uows.UnitOfWork[repositories.EventRepository]
+	publisher    publishers.Publisher
+	pollInterval time.Duration
+	batchSize    int
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewOutboxRelay(
+	uow uows.UnitOfWork[repositories.EventRepository],
+	publisher publishers.Publisher,
+	pollInterval time.Duration,
+	batchSize int,
+) *OutboxRelay {
+	return &OutboxRelay{
+		uow:          uow,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+	}
+}
+
+func (r *OutboxRelay) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done != nil {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+func (r *OutboxRelay) Close(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *OutboxRelay) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		published, err := r.RelayBatch(ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("outbox relay: %v", err)
+		}
+
+		// A full batch means there is probably more work waiting, so poll again
+		// right away instead of sleeping.
+		if err == nil && published == r.batchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(r.pollInterval)
+		}
+	}
+}
+
+// RelayBatch publishes a single batch of unprocessed events and returns how
+// many of them were published. Publishing stops at the first failure so that
+// events keep their order.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	published := 0
+	var publishErr error
+	err := r.uow.DoTransaction(func(events repositories.EventRepository) error {
+		batch, err := events.ListUnprocessed(r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(batch))
+		for _, event := range batch {
+			if publishErr = r.publisher.Publish(ctx, event); publishErr != nil {
+				break
+			}
+			ids = append(ids, event.ID)
+		}
+
+		// Events published before a failure are still committed as processed.
+		if err := events.MarkProcessedBatch(ids); err != nil {
+			return err
+		}
+		published = len(ids)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
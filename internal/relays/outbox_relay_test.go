@@ -0,0 +1,90 @@
+package relays
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/publishers"
+	"app/internal/repositories"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTxUow(events repositories.EventRepository) *mocks.UnitOfWorkMock[repositories.EventRepository] {
+	mockUow := new(mocks.UnitOfWorkMock[repositories.EventRepository])
+	mockUow.On("DoTransaction", mock.Anything).Return(func(fn func(repositories.EventRepository) error) error {
+		return fn(events)
+	})
+	return mockUow
+}
+
+func TestOutboxRelay_RelayBatch_PublishesAndMarksProcessed(t *testing.T) {
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	publisher := publishers.NewMemoryPublisher()
+
+	batch := []domain.Event{
+		{ID: 1, Type: "UserRegistered", Payload: `{}`},
+		{ID: 2, Type: "UserLoggedIn", Payload: `{}`},
+	}
+	mockEventRepo.On("ListUnprocessed", 10).Return(batch, nil)
+	mockEventRepo.On("MarkProcessedBatch", []int64{1, 2}).Return(nil)
+
+	relay := NewOutboxRelay(newTxUow(mockEventRepo), publisher, time.Second, 10)
+	published, err := relay.RelayBatch(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Equal(t, batch, publisher.Events())
+	mockEventRepo.AssertExpectations(t)
+}
+
+func TestOutboxRelay_RelayBatch_StopsAtFirstFailure(t *testing.T) {
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	mockPublisher := new(mocks.PublisherMock)
+
+	batch := []domain.Event{
+		{ID: 1, Type: "UserRegistered", Payload: `{}`},
+		{ID: 2, Type: "UserLoggedIn", Payload: `{}`},
+		{ID: 3, Type: "UserLoggedIn", Payload: `{}`},
+	}
+	publishErr := errors.New("broker unavailable")
+
+	mockEventRepo.On("ListUnprocessed", 10).Return(batch, nil)
+	mockEventRepo.On("MarkProcessedBatch", []int64{1}).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, batch[0]).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, batch[1]).Return(publishErr)
+
+	relay := NewOutboxRelay(newTxUow(mockEventRepo), mockPublisher, time.Second, 10)
+	published, err := relay.RelayBatch(context.Background())
+
+	assert.ErrorIs(t, err, publishErr)
+	assert.Equal(t, 1, published)
+	mockEventRepo.AssertExpectations(t)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, batch[2])
+}
+
+func TestOutboxRelay_StartAndClose(t *testing.T) {
+	mockEventRepo := new(mocks.EventRepositoryMock)
+	publisher := publishers.NewMemoryPublisher()
+
+	event := domain.Event{ID: 7, Type: "UserRegistered", Payload: `{}`}
+	mockEventRepo.On("ListUnprocessed", 10).Return([]domain.Event{event}, nil).Once()
+	mockEventRepo.On("ListUnprocessed", 10).Return([]domain.Event{}, nil)
+	mockEventRepo.On("MarkProcessedBatch", mock.Anything).Return(nil)
+
+	relay := NewOutboxRelay(newTxUow(mockEventRepo), publisher, 10*time.Millisecond, 10)
+	relay.Start(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.Events()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, relay.Close(ctx))
+	assert.Equal(t, []domain.Event{event}, publisher.Events())
+}
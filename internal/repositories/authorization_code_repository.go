@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=AuthorizationCodeRepository --output=../mocks --structname=AuthorizationCodeRepositoryMock
+type AuthorizationCodeRepository interface {
+	Save(code *domain.AuthorizationCode) error
+	GetByHash(hash string) (*domain.AuthorizationCode, error)
+}
+
+type AuthorizationCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewAuthorizationCodeRepository(db *gorm.DB) AuthorizationCodeRepository {
+	return &AuthorizationCodeRepositoryImpl{db: db}
+}
+
+func (r *AuthorizationCodeRepositoryImpl) Save(code *domain.AuthorizationCode) error {
+	return r.db.Save(code).Error
+}
+
+// GetByHash locks the row, so two concurrent exchanges of the same code cannot
+// both see it unused.
+func (r *AuthorizationCodeRepositoryImpl) GetByHash(hash string) (*domain.AuthorizationCode, error) {
+	var code domain.AuthorizationCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("code_hash = ?", hash).
+		First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
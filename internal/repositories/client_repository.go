@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=ClientRepository --output=../mocks --structname=ClientRepositoryMock
+type ClientRepository interface {
+	Save(client *domain.Client) error
+	GetByClientID(clientID string) (*domain.Client, error)
+}
+
+type ClientRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewClientRepository(db *gorm.DB) ClientRepository {
+	return &ClientRepositoryImpl{db: db}
+}
+
+func (r *ClientRepositoryImpl) Save(client *domain.Client) error {
+	return r.db.Save(client).Error
+}
+
+func (r *ClientRepositoryImpl) GetByClientID(clientID string) (*domain.Client, error) {
+	var client domain.Client
+	err := r.db.Where("client_id = ?", clientID).First(&client).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
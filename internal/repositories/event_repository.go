@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 //go:generate mockery --name=EventRepository --output=../mocks --structname=EventRepositoryMock
@@ -52,9 +53,13 @@ func (r *EventRepositoryImpl) GetByID(id int64) (*domain.Event, error) {
 	return &event, nil
 }
 
+// ListUnprocessed locks the returned rows with SKIP LOCKED, so concurrent relays
+// running inside a transaction never pick up the same events.
 func (r *EventRepositoryImpl) ListUnprocessed(limit int) ([]domain.Event, error) {
 	var events []domain.Event
-	query := r.db.Where("processed = ?", false).Order("id ASC")
+	query := r.db.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("processed = ?", false).
+		Order("id ASC")
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
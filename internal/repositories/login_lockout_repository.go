@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=LoginLockoutRepository --output=../mocks --structname=LoginLockoutRepositoryMock
+type LoginLockoutRepository interface {
+	Get(key string) (*domain.LoginLockout, error)
+	Save(lockout *domain.LoginLockout) error
+	Delete(key string) error
+}
+
+type LoginLockoutRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewLoginLockoutRepository(db *gorm.DB) LoginLockoutRepository {
+	return &LoginLockoutRepositoryImpl{db: db}
+}
+
+// Get locks the row, so concurrent failures are all counted.
+func (r *LoginLockoutRepositoryImpl) Get(key string) (*domain.LoginLockout, error) {
+	var lockout domain.LoginLockout
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("key = ?", key).
+		First(&lockout).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &lockout, nil
+}
+
+// Save upserts, since two first failures for a key can race to insert it.
+func (r *LoginLockoutRepositoryImpl) Save(lockout *domain.LoginLockout) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(lockout).Error
+}
+
+func (r *LoginLockoutRepositoryImpl) Delete(key string) error {
+	return r.db.Where("key = ?", key).Delete(&domain.LoginLockout{}).Error
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=MagicLinkTokenRepository --output=../mocks --structname=MagicLinkTokenRepositoryMock
+type MagicLinkTokenRepository interface {
+	Save(token *domain.MagicLinkToken) error
+	GetBySelector(selector string) (*domain.MagicLinkToken, error)
+	DeleteByUser(userID uuid.UUID) error
+}
+
+type MagicLinkTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkTokenRepository(db *gorm.DB) MagicLinkTokenRepository {
+	return &MagicLinkTokenRepositoryImpl{db: db}
+}
+
+func (r *MagicLinkTokenRepositoryImpl) Save(token *domain.MagicLinkToken) error {
+	return r.db.Save(token).Error
+}
+
+// GetBySelector locks the row, so a token cannot be consumed twice
+// concurrently.
+func (r *MagicLinkTokenRepositoryImpl) GetBySelector(selector string) (*domain.MagicLinkToken, error) {
+	var token domain.MagicLinkToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("selector = ?", selector).
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *MagicLinkTokenRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.MagicLinkToken{}).Error
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=PasswordResetTokenRepository --output=../mocks --structname=PasswordResetTokenRepositoryMock
+type PasswordResetTokenRepository interface {
+	Save(token *domain.PasswordResetToken) error
+	GetByHash(hash string) (*domain.PasswordResetToken, error)
+	DeleteByUser(userID uuid.UUID) error
+}
+
+type PasswordResetTokenRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) PasswordResetTokenRepository {
+	return &PasswordResetTokenRepositoryImpl{db: db}
+}
+
+func (r *PasswordResetTokenRepositoryImpl) Save(token *domain.PasswordResetToken) error {
+	return r.db.Save(token).Error
+}
+
+// GetByHash locks the row, so a token cannot be consumed twice concurrently.
+func (r *PasswordResetTokenRepositoryImpl) GetByHash(hash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hash).
+		First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *PasswordResetTokenRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.PasswordResetToken{}).Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=RecoveryCodeRepository --output=../mocks --structname=RecoveryCodeRepositoryMock
+type RecoveryCodeRepository interface {
+	Create(codes []domain.RecoveryCode) error
+	Save(code *domain.RecoveryCode) error
+	GetUnused(userID uuid.UUID, hash string) (*domain.RecoveryCode, error)
+	DeleteByUser(userID uuid.UUID) error
+}
+
+type RecoveryCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &RecoveryCodeRepositoryImpl{db: db}
+}
+
+func (r *RecoveryCodeRepositoryImpl) Create(codes []domain.RecoveryCode) error {
+	return r.db.Create(&codes).Error
+}
+
+func (r *RecoveryCodeRepositoryImpl) Save(code *domain.RecoveryCode) error {
+	return r.db.Save(code).Error
+}
+
+// GetUnused locks the row, so a code cannot be consumed twice concurrently.
+func (r *RecoveryCodeRepositoryImpl) GetUnused(userID uuid.UUID, hash string) (*domain.RecoveryCode, error) {
+	var code domain.RecoveryCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (r *RecoveryCodeRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=RoleRepository --output=../mocks --structname=RoleRepositoryMock
+type RoleRepository interface {
+	// PermissionsOf returns the permissions granted by any of roles, sorted
+	// and without duplicates.
+	PermissionsOf(roles []string) ([]string, error)
+}
+
+type RoleRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &RoleRepositoryImpl{db: db}
+}
+
+func (r *RoleRepositoryImpl) PermissionsOf(roles []string) ([]string, error) {
+	if len(roles) == 0 {
+		return nil, nil
+	}
+
+	var permissions []string
+	err := r.db.Table("role_permissions").
+		Distinct("permission").
+		Where("role IN ?", roles).
+		Order("permission").
+		Pluck("permission", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=SMSCodeRepository --output=../mocks --structname=SMSCodeRepositoryMock
+type SMSCodeRepository interface {
+	Save(code *domain.SMSCode) error
+	GetLatest(phone, purpose string) (*domain.SMSCode, error)
+	DeleteByPhone(phone, purpose string) error
+}
+
+type SMSCodeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewSMSCodeRepository(db *gorm.DB) SMSCodeRepository {
+	return &SMSCodeRepositoryImpl{db: db}
+}
+
+func (r *SMSCodeRepositoryImpl) Save(code *domain.SMSCode) error {
+	return r.db.Save(code).Error
+}
+
+// GetLatest returns the last code sent to phone for purpose and locks it, so
+// concurrent attempts are counted one after the other.
+func (r *SMSCodeRepositoryImpl) GetLatest(phone, purpose string) (*domain.SMSCode, error) {
+	var code domain.SMSCode
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("phone = ? AND purpose = ?", phone, purpose).
+		Order("id DESC").
+		First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+func (r *SMSCodeRepositoryImpl) DeleteByPhone(phone, purpose string) error {
+	return r.db.Where("phone = ? AND purpose = ?", phone, purpose).Delete(&domain.SMSCode{}).Error
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
 )
 
 //go:generate mockery --name=TokenRepository --output=../mocks --structname=TokenRepositoryMock
@@ -12,9 +14,12 @@ type TokenRepository interface {
 	Save(token *domain.Token) error
 	GetByID(id uint) (*domain.Token, error)
 	GetByHash(hash string) (*domain.Token, error)
+	GetBySessionID(sessionID uuid.UUID) (*domain.Token, error)
+	ListByUserID(userID uuid.UUID) ([]domain.Token, error)
+	RevokeSession(sessionID uuid.UUID) error
+	RevokeByUser(userID uuid.UUID) error
 	Delete(id uint) error
 	DeleteByUser(userID uuid.UUID) error
-	GetByUserID(userID uuid.UUID) (*domain.Token, error)
 }
 
 type TokenRepositoryImpl struct {
@@ -42,9 +47,13 @@ func (r *TokenRepositoryImpl) GetByID(id uint) (*domain.Token, error) {
 	return &token, nil
 }
 
+// GetByHash locks the row, so two concurrent refreshes with the same token
+// cannot both rotate it and fork the family past reuse detection.
 func (r *TokenRepositoryImpl) GetByHash(hash string) (*domain.Token, error) {
 	var token domain.Token
-	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", hash).
+		First(&token).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -55,17 +64,10 @@ func (r *TokenRepositoryImpl) GetByHash(hash string) (*domain.Token, error) {
 	return &token, nil
 }
 
-func (r *TokenRepositoryImpl) Delete(id uint) error {
-	return r.db.Delete(&domain.Token{}, id).Error
-}
-
-func (r *TokenRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
-	return r.db.Where("user_id = ?", userID).Delete(&domain.Token{}).Error
-}
-
-func (r *TokenRepositoryImpl) GetByUserID(userID uuid.UUID) (*domain.Token, error) {
+// GetBySessionID returns the current, not yet rotated token of a session.
+func (r *TokenRepositoryImpl) GetBySessionID(sessionID uuid.UUID) (*domain.Token, error) {
 	var token domain.Token
-	err := r.db.Where("user_id = ?", userID).First(&token).Error
+	err := r.db.Where("session_id = ? AND rotated_at IS NULL", sessionID).First(&token).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -75,3 +77,39 @@ func (r *TokenRepositoryImpl) GetByUserID(userID uuid.UUID) (*domain.Token, erro
 
 	return &token, nil
 }
+
+// ListByUserID returns the current token of every active session of the user.
+func (r *TokenRepositoryImpl) ListByUserID(userID uuid.UUID) ([]domain.Token, error) {
+	var tokens []domain.Token
+	err := r.db.Where("user_id = ? AND rotated_at IS NULL AND revoked_at IS NULL", userID).
+		Order("last_used_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// RevokeSession revokes every token of the session, including rotated ones.
+func (r *TokenRepositoryImpl) RevokeSession(sessionID uuid.UUID) error {
+	return r.db.Model(&domain.Token{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeByUser revokes every token of the user, keeping the rows so reuse of
+// a rotated token is still detected.
+func (r *TokenRepositoryImpl) RevokeByUser(userID uuid.UUID) error {
+	return r.db.Model(&domain.Token{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *TokenRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&domain.Token{}, id).Error
+}
+
+func (r *TokenRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.Token{}).Error
+}
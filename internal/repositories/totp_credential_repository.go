@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=TOTPCredentialRepository --output=../mocks --structname=TOTPCredentialRepositoryMock
+type TOTPCredentialRepository interface {
+	Get(userID uuid.UUID) (*domain.TOTPCredential, error)
+	Save(credential *domain.TOTPCredential) error
+	Delete(userID uuid.UUID) error
+}
+
+type TOTPCredentialRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewTOTPCredentialRepository(db *gorm.DB) TOTPCredentialRepository {
+	return &TOTPCredentialRepositoryImpl{db: db}
+}
+
+// Get locks the row, so a code cannot be accepted twice concurrently.
+func (r *TOTPCredentialRepositoryImpl) Get(userID uuid.UUID) (*domain.TOTPCredential, error) {
+	var credential domain.TOTPCredential
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+func (r *TOTPCredentialRepositoryImpl) Save(credential *domain.TOTPCredential) error {
+	return r.db.Save(credential).Error
+}
+
+func (r *TOTPCredentialRepositoryImpl) Delete(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.TOTPCredential{}).Error
+}
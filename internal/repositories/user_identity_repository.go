@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+//go:generate mockery --name=UserIdentityRepository --output=../mocks --structname=UserIdentityRepositoryMock
+type UserIdentityRepository interface {
+	Save(identity *domain.UserIdentity) error
+	GetByProviderSubject(provider, subject string) (*domain.UserIdentity, error)
+	DeleteByUser(userID uuid.UUID) error
+}
+
+type UserIdentityRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &UserIdentityRepositoryImpl{db: db}
+}
+
+func (r *UserIdentityRepositoryImpl) Save(identity *domain.UserIdentity) error {
+	return r.db.Save(identity).Error
+}
+
+func (r *UserIdentityRepositoryImpl) GetByProviderSubject(provider, subject string) (*domain.UserIdentity, error) {
+	var identity domain.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+func (r *UserIdentityRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.UserIdentity{}).Error
+}
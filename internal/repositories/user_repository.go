@@ -12,6 +12,7 @@ type UserRepository interface {
 	Save(u *domain.User) error
 	GetByID(id uuid.UUID) (*domain.User, error)
 	GetByEmail(email string) (*domain.User, error)
+	GetByPhone(phone string) (*domain.User, error)
 }
 
 type UserRepositoryImpl struct {
@@ -51,3 +52,16 @@ func (r *UserRepositoryImpl) GetByEmail(email string) (*domain.User, error) {
 
 	return &user, nil
 }
+
+func (r *UserRepositoryImpl) GetByPhone(phone string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Preload("Roles").First(&user, "phone = ?", phone).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
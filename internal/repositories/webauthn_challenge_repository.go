@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+//go:generate mockery --name=WebAuthnChallengeRepository --output=../mocks --structname=WebAuthnChallengeRepositoryMock
+type WebAuthnChallengeRepository interface {
+	Save(challenge *domain.WebAuthnChallenge) error
+	GetByHash(challengeHash string) (*domain.WebAuthnChallenge, error)
+	Delete(id uint) error
+	DeleteExpired(now time.Time) error
+}
+
+type WebAuthnChallengeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnChallengeRepository(db *gorm.DB) WebAuthnChallengeRepository {
+	return &WebAuthnChallengeRepositoryImpl{db: db}
+}
+
+func (r *WebAuthnChallengeRepositoryImpl) Save(challenge *domain.WebAuthnChallenge) error {
+	return r.db.Save(challenge).Error
+}
+
+// GetByHash locks the row, so a challenge cannot be consumed twice
+// concurrently.
+func (r *WebAuthnChallengeRepositoryImpl) GetByHash(challengeHash string) (*domain.WebAuthnChallenge, error) {
+	var challenge domain.WebAuthnChallenge
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("challenge_hash = ?", challengeHash).
+		First(&challenge).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+func (r *WebAuthnChallengeRepositoryImpl) Delete(id uint) error {
+	return r.db.Delete(&domain.WebAuthnChallenge{}, id).Error
+}
+
+// DeleteExpired deletes the challenges of ceremonies that were never finished.
+func (r *WebAuthnChallengeRepositoryImpl) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&domain.WebAuthnChallenge{}).Error
+}
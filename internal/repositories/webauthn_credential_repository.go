@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"app/internal/domain"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+//go:generate mockery --name=WebAuthnCredentialRepository --output=../mocks --structname=WebAuthnCredentialRepositoryMock
+type WebAuthnCredentialRepository interface {
+	Save(credential *domain.WebAuthnCredential) error
+	GetByCredentialID(credentialID []byte) (*domain.WebAuthnCredential, error)
+	ListByUser(userID uuid.UUID) ([]domain.WebAuthnCredential, error)
+	DeleteByUser(userID uuid.UUID) error
+}
+
+type WebAuthnCredentialRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepositoryImpl{db: db}
+}
+
+func (r *WebAuthnCredentialRepositoryImpl) Save(credential *domain.WebAuthnCredential) error {
+	return r.db.Save(credential).Error
+}
+
+// GetByCredentialID locks the row, so concurrent logins see each other's
+// signature counter.
+func (r *WebAuthnCredentialRepositoryImpl) GetByCredentialID(credentialID []byte) (*domain.WebAuthnCredential, error) {
+	var credential domain.WebAuthnCredential
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("credential_id = ?", credentialID).
+		First(&credential).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+func (r *WebAuthnCredentialRepositoryImpl) ListByUser(userID uuid.UUID) ([]domain.WebAuthnCredential, error) {
+	var credentials []domain.WebAuthnCredential
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&credentials).Error
+	return credentials, err
+}
+
+func (r *WebAuthnCredentialRepositoryImpl) DeleteByUser(userID uuid.UUID) error {
+	return r.db.Where("user_id = ?", userID).Delete(&domain.WebAuthnCredential{}).Error
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"time"
+)
+
+const (
+	authorizationCodeTTL = time.Minute
+	authorizeFormTTL     = 15 * time.Minute
+	authorizeFormPurpose = "oauth_authorize"
+)
+
+type AuthorizationService struct {
+	tokenGenerator utils.TokenGenerator
+	signer         utils.TokenSigner
+}
+
+func NewAuthorizationService(tokenGenerator utils.TokenGenerator, signer utils.TokenSigner) *AuthorizationService {
+	return &AuthorizationService{tokenGenerator: tokenGenerator, signer: signer}
+}
+
+// IssueFormToken returns the CSRF token of a login form and the nonce it is
+// bound to. The nonce is kept by the browser in a cookie, request identifies
+// the authorization request the form was shown for.
+func (s *AuthorizationService) IssueFormToken(request string) (string, string, error) {
+	nonce, err := s.tokenGenerator.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := s.signer.Sign(utils.SignedTokenClaims{
+		Purpose:   authorizeFormPurpose,
+		Subject:   request,
+		Nonce:     utils.HashToken(nonce),
+		ExpiresAt: time.Now().Add(authorizeFormTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, nonce, nil
+}
+
+// VerifyFormToken checks a posted CSRF token against the nonce of the browser
+// and the authorization request. Any mismatch fails with ErrInvalidToken.
+func (s *AuthorizationService) VerifyFormToken(token string, nonce string, request string) error {
+	claims, err := s.signer.Verify(token, authorizeFormPurpose)
+	if err != nil || nonce == "" {
+		return ErrInvalidToken
+	}
+
+	if claims.Nonce != utils.HashToken(nonce) || claims.Subject != request {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// IssueCode stores a single-use authorization code for the user and returns
+// it. The code is bound to the client, the redirect URI and the S256 PKCE
+// challenge it was requested with.
+func (s *AuthorizationService) IssueCode(
+	store stores.Store,
+	client *domain.Client,
+	user *domain.User,
+	redirectURI string,
+	scope string,
+	codeChallenge string,
+) (string, error) {
+	code, err := s.tokenGenerator.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = store.AuthorizationCodes().Save(&domain.AuthorizationCode{
+		CodeHash:            utils.HashToken(code),
+		ClientID:            client.ClientID,
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		Scope:               scope,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: utils.PKCEMethodS256,
+		ExpiresAt:           time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// ExchangeCode marks the code as used and returns it. Unknown, expired or
+// already used codes, a different client or redirect URI and a wrong code
+// verifier all fail with ErrInvalidCredentials.
+func (s *AuthorizationService) ExchangeCode(
+	store stores.Store,
+	client *domain.Client,
+	code string,
+	redirectURI string,
+	codeVerifier string,
+) (*domain.AuthorizationCode, error) {
+	authCode, err := store.AuthorizationCodes().GetByHash(utils.HashToken(code))
+	if err != nil {
+		return nil, err
+	}
+
+	if authCode == nil || authCode.UsedAt != nil || authCode.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if authCode.ClientID != client.ClientID || authCode.RedirectURI != redirectURI {
+		return nil, ErrInvalidCredentials
+	}
+
+	if !utils.VerifyPKCE(codeVerifier, authCode.CodeChallenge) {
+		return nil, ErrInvalidCredentials
+	}
+
+	usedAt := time.Now()
+	authCode.UsedAt = &usedAt
+	if err := store.AuthorizationCodes().Save(authCode); err != nil {
+		return nil, err
+	}
+
+	return authCode, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type ClientService struct {
+	hasher         utils.PasswordHasher
+	tokenGenerator utils.TokenGenerator
+}
+
+func NewClientService(hasher utils.PasswordHasher, tokenGenerator utils.TokenGenerator) *ClientService {
+	return &ClientService{
+		hasher:         hasher,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+// Register adds a client to the registry and returns it together with its
+// secret. Only the hash of the secret is stored, so it is shown exactly once.
+// Public clients get no secret and cannot use the client_credentials grant.
+func (s *ClientService) Register(
+	store stores.Store,
+	name string,
+	grantTypes []string,
+	scopes []string,
+	redirectURIs []string,
+	public bool,
+) (*domain.Client, string, error) {
+	for _, grantType := range grantTypes {
+		switch grantType {
+		case domain.GrantTypePassword, domain.GrantTypeRefreshToken:
+		case domain.GrantTypeClientCredentials:
+			if public {
+				return nil, "", fmt.Errorf("public clients cannot use grant type %q", grantType)
+			}
+		case domain.GrantTypeAuthorizationCode:
+			if len(redirectURIs) == 0 {
+				return nil, "", fmt.Errorf("grant type %q needs at least one redirect URI", grantType)
+			}
+		default:
+			return nil, "", fmt.Errorf("unsupported grant type %q", grantType)
+		}
+	}
+
+	for _, redirectURI := range redirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || !u.IsAbs() || u.Fragment != "" {
+			return nil, "", fmt.Errorf("invalid redirect URI %q", redirectURI)
+		}
+	}
+
+	clientID, err := s.tokenGenerator.GenerateSecureToken(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := &domain.Client{
+		ClientID:     clientID,
+		Name:         name,
+		GrantTypes:   strings.Join(grantTypes, " "),
+		Scopes:       strings.Join(scopes, " "),
+		RedirectURIs: strings.Join(redirectURIs, " "),
+	}
+
+	var secret string
+	if !public {
+		secret, err = s.tokenGenerator.GenerateSecureToken(32)
+		if err != nil {
+			return nil, "", err
+		}
+		client.SecretHash, err = s.hasher.Hash(secret)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := store.Clients().Save(client); err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// GetByClientID returns the client registered under clientID.
+func (s *ClientService) GetByClientID(
+	store stores.Store,
+	clientID string,
+) (*domain.Client, error) {
+	client, err := store.Clients().GetByClientID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// Authenticate looks up a client and checks its secret. Public clients must not
+// present a secret, confidential clients must present the right one.
+func (s *ClientService) Authenticate(
+	store stores.Store,
+	clientID string,
+	secret string,
+) (*domain.Client, error) {
+	client, err := s.GetByClientID(store, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.IsPublic() {
+		if secret != "" {
+			return nil, ErrInvalidClient
+		}
+		return client, nil
+	}
+
+	if secret == "" || !s.hasher.Verify(secret, client.SecretHash) {
+		return nil, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// Authorize checks that the client may use the grant type and the requested
+// scope. It returns the granted scope, which defaults to every scope of the
+// client when none was requested.
+func (s *ClientService) Authorize(
+	client *domain.Client,
+	grantType string,
+	scope string,
+) (string, error) {
+	if !client.AllowsGrant(grantType) {
+		return "", ErrUnauthorizedClient
+	}
+
+	if !client.AllowsScopes(scope) {
+		return "", ErrInvalidScope
+	}
+
+	if strings.TrimSpace(scope) == "" {
+		return client.Scopes, nil
+	}
+
+	return strings.Join(strings.Fields(scope), " "), nil
+}
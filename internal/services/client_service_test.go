@@ -0,0 +1,110 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestClientService_Register(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockClientRepo := new(mocks.ClientRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Clients").Return(mockClientRepo)
+	mockHasher.On("Hash", mock.AnythingOfType("string")).Return("hashed_secret", nil)
+	mockClientRepo.On("Save", mock.AnythingOfType("*domain.Client")).Return(nil)
+
+	clientSvc := NewClientService(mockHasher, utils.NewTokenGenerator())
+	client, secret, err := clientSvc.Register(mockStore, "billing",
+		[]string{domain.GrantTypeClientCredentials}, []string{"orders:read", "orders:write"}, nil, false)
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, client.ClientID)
+	assert.NotEmpty(t, secret)
+	assert.Equal(t, "hashed_secret", client.SecretHash)
+	assert.Equal(t, "orders:read orders:write", client.Scopes)
+
+	_, _, err = clientSvc.Register(mockStore, "billing", []string{"implicit"}, nil, nil, false)
+	assert.Error(t, err)
+
+	_, _, err = clientSvc.Register(mockStore, "spa", []string{domain.GrantTypeAuthorizationCode}, nil, nil, true)
+	assert.Error(t, err)
+
+	_, _, err = clientSvc.Register(mockStore, "cli", []string{domain.GrantTypeClientCredentials}, nil, nil, true)
+	assert.Error(t, err)
+
+	_, _, err = clientSvc.Register(mockStore, "spa",
+		[]string{domain.GrantTypeAuthorizationCode}, nil, []string{"/callback"}, true)
+	assert.Error(t, err)
+
+	client, secret, err = clientSvc.Register(mockStore, "spa",
+		[]string{domain.GrantTypeAuthorizationCode}, nil, []string{"https://app.example.com/callback"}, true)
+	assert.NoError(t, err)
+	assert.Empty(t, secret)
+	assert.True(t, client.IsPublic())
+	assert.Equal(t, "https://app.example.com/callback", client.RedirectURIs)
+}
+
+func TestClientService_Authenticate(t *testing.T) {
+	confidential := &domain.Client{ClientID: "billing", SecretHash: "hashed_secret"}
+	public := &domain.Client{ClientID: "spa"}
+
+	mockStore := new(mocks.StoreMock)
+	mockClientRepo := new(mocks.ClientRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Clients").Return(mockClientRepo)
+	mockClientRepo.On("GetByClientID", "billing").Return(confidential, nil)
+	mockClientRepo.On("GetByClientID", "spa").Return(public, nil)
+	mockClientRepo.On("GetByClientID", "unknown").Return(nil, nil)
+	mockHasher.On("Verify", "secret", "hashed_secret").Return(true)
+	mockHasher.On("Verify", "wrong", "hashed_secret").Return(false)
+
+	clientSvc := NewClientService(mockHasher, nil)
+
+	client, err := clientSvc.Authenticate(mockStore, "billing", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, confidential, client)
+
+	client, err = clientSvc.Authenticate(mockStore, "spa", "")
+	assert.NoError(t, err)
+	assert.Equal(t, public, client)
+
+	for _, tc := range []struct{ clientID, secret string }{
+		{"billing", "wrong"},
+		{"billing", ""},
+		{"spa", "secret"},
+		{"unknown", "secret"},
+	} {
+		_, err := clientSvc.Authenticate(mockStore, tc.clientID, tc.secret)
+		assert.ErrorIs(t, err, ErrInvalidClient, tc.clientID)
+	}
+}
+
+func TestClientService_Authorize(t *testing.T) {
+	client := &domain.Client{
+		ClientID:   "billing",
+		GrantTypes: domain.GrantTypeClientCredentials,
+		Scopes:     "orders:read orders:write",
+	}
+	clientSvc := NewClientService(nil, nil)
+
+	scope, err := clientSvc.Authorize(client, domain.GrantTypeClientCredentials, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders:read orders:write", scope)
+
+	scope, err = clientSvc.Authorize(client, domain.GrantTypeClientCredentials, " orders:read ")
+	assert.NoError(t, err)
+	assert.Equal(t, "orders:read", scope)
+
+	_, err = clientSvc.Authorize(client, domain.GrantTypeClientCredentials, "orders:delete")
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, err = clientSvc.Authorize(client, domain.GrantTypePassword, "")
+	assert.ErrorIs(t, err, ErrUnauthorizedClient)
+}
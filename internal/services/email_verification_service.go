@@ -0,0 +1,105 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	VerificationRequested = "VerificationRequested"
+	EmailVerified         = "EmailVerified"
+)
+
+const emailVerificationPurpose = "verify_email"
+
+// EmailVerificationService issues and checks email verification tokens. The
+// tokens are signed rather than stored; they name the user and the address
+// they were sent to and stop working once the address is verified, so each
+// one can be used once.
+type EmailVerificationService struct {
+	signer utils.TokenSigner
+	ttl    time.Duration
+}
+
+func NewEmailVerificationService(signer utils.TokenSigner, ttl time.Duration) *EmailVerificationService {
+	return &EmailVerificationService{signer: signer, ttl: ttl}
+}
+
+// RequestVerification signs a token for the current email of the user. The
+// returned payload is meant for the VerificationRequested event.
+func (s *EmailVerificationService) RequestVerification(user *domain.User) (*VerificationRequestedPayload, error) {
+	expiresAt := time.Now().Add(s.ttl)
+	token, err := s.signer.Sign(utils.SignedTokenClaims{
+		Purpose:   emailVerificationPurpose,
+		Subject:   user.ID.String(),
+		Email:     user.Email,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerificationRequestedPayload{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResendVerification signs a new token for the user registered under email.
+// It returns nil when there is nobody to send it to, which callers must not
+// reveal.
+func (s *EmailVerificationService) ResendVerification(
+	store stores.Store,
+	email string,
+) (*VerificationRequestedPayload, error) {
+	user, err := store.Users().GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.EmailVerifiedAt != nil {
+		return nil, nil
+	}
+
+	return s.RequestVerification(user)
+}
+
+// VerifyEmail marks the email of the user the token was issued to as verified.
+func (s *EmailVerificationService) VerifyEmail(
+	store stores.Store,
+	token string,
+) (*domain.User, error) {
+	claims, err := s.signer.Verify(token, emailVerificationPurpose)
+	if errors.Is(err, utils.ErrInvalidToken) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := store.Users().GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Email != claims.Email || user.EmailVerifiedAt != nil {
+		return nil, ErrInvalidToken
+	}
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	if err := store.Users().Save(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -5,4 +5,26 @@ import "errors"
 var (
 	ErrUserAlreadyExists  = errors.New("user already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	ErrInvalidClient      = errors.New("invalid client")
+	ErrUnauthorizedClient = errors.New("client is not allowed to use this grant")
+	ErrInvalidScope       = errors.New("invalid scope")
+	ErrAccessTokenRevoked = errors.New("access token revoked")
+	ErrEmailNotVerified   = errors.New("email not verified")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrAccountLocked      = errors.New("account temporarily locked")
+	ErrMFARequired        = errors.New("two-factor authentication required")
+	ErrMFANotEnabled      = errors.New("two-factor authentication not enabled")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication already enabled")
+	ErrInvalidMFACode     = errors.New("invalid two-factor authentication code")
+
+	ErrInvalidWebAuthnResponse  = errors.New("invalid webauthn response")
+	ErrWebAuthnCredentialExists = errors.New("webauthn credential already registered")
+
+	ErrInvalidSMSCode = errors.New("invalid or expired sms code")
+	ErrPhoneTaken     = errors.New("phone already in use")
+
+	ErrIdentityEmailNotVerified = errors.New("identity provider did not verify the email")
+
+	ErrPasswordContainsPersonalInfo = errors.New("password contains personal info")
 )
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/federation"
+	"app/internal/stores"
+	"app/internal/utils"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+)
+
+var IdentityLinked = "IdentityLinked"
+
+const federationLoginPurpose = "federation_login"
+
+// FederatedLogin is the outcome of a login at an external identity provider.
+type FederatedLogin struct {
+	User *domain.User
+	// Linked is set when the identity was linked to the user by this login,
+	// and Created when the user was created for it. Reclaimed is set when the
+	// user had never verified the email, so everything whoever registered the
+	// account set up was dropped; the caller must revoke their access tokens.
+	Linked    bool
+	Created   bool
+	Reclaimed bool
+}
+
+// FederationService runs the login at the external identity providers of
+// the registry. The state, nonce and PKCE verifier of a login travel in a
+// signed state token, which the caller keeps in a cookie until the callback.
+type FederationService struct {
+	registry        *federation.Registry
+	signer          utils.TokenSigner
+	tokenGenerator  utils.TokenGenerator
+	callbackBaseURL string
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+// NewFederationService creates a FederationService. The callback of a
+// provider is callbackBaseURL followed by "/<provider>/callback".
+func NewFederationService(
+	registry *federation.Registry,
+	signer utils.TokenSigner,
+	tokenGenerator utils.TokenGenerator,
+	callbackBaseURL string,
+	ttl time.Duration,
+) *FederationService {
+	return &FederationService{
+		registry:        registry,
+		signer:          signer,
+		tokenGenerator:  tokenGenerator,
+		callbackBaseURL: callbackBaseURL,
+		ttl:             ttl,
+		now:             time.Now,
+	}
+}
+
+// Begin returns the URL that sends the user to the provider and the state
+// token the callback must carry.
+func (s *FederationService) Begin(ctx context.Context, providerName string) (string, string, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		if secrets[i], err = s.tokenGenerator.GenerateSecureToken(32); err != nil {
+			return "", "", err
+		}
+	}
+	state, nonce, codeVerifier := secrets[0], secrets[1], secrets[2]
+
+	stateToken, err := s.signer.Sign(utils.SignedTokenClaims{
+		Purpose:      federationLoginPurpose,
+		Subject:      providerName,
+		Nonce:        nonce,
+		State:        state,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    s.now().Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, s.redirectURI(providerName), state, nonce, utils.PKCEChallenge(codeVerifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, stateToken, nil
+}
+
+// Complete checks the callback against the state token, redeems the code and
+// returns the identity the ID token tells about.
+func (s *FederationService) Complete(
+	ctx context.Context,
+	providerName string,
+	stateToken string,
+	state string,
+	code string,
+) (*federation.Identity, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.signer.Verify(stateToken, federationLoginPurpose)
+	if errors.Is(err, utils.ErrInvalidToken) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject != providerName || subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, s.redirectURI(providerName), claims.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.VerifyIDToken(ctx, rawIDToken, claims.Nonce)
+}
+
+// Login returns the user linked to the identity. An identity seen for the
+// first time is linked to the user registered under its email, or to a new
+// customer, but only when the provider verified the email.
+func (s *FederationService) Login(
+	store stores.Store,
+	providerName string,
+	identity *federation.Identity,
+) (*FederatedLogin, error) {
+	now := s.now()
+
+	linked, err := store.UserIdentities().GetByProviderSubject(providerName, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		user, err := store.Users().GetByID(linked.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrInvalidCredentials
+		}
+
+		linked.LastLoginAt = &now
+		if err := store.UserIdentities().Save(linked); err != nil {
+			return nil, err
+		}
+		return &FederatedLogin{User: user}, nil
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrIdentityEmailNotVerified
+	}
+
+	login := &FederatedLogin{Linked: true}
+	login.User, err = store.Users().GetByEmail(identity.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case login.User == nil:
+		login.Created = true
+		login.User = &domain.User{
+			Email:           identity.Email,
+			Name:            identity.GivenName,
+			Surname:         identity.FamilyName,
+			EmailVerifiedAt: &now,
+			Roles: []domain.UserRole{
+				{Role: domain.RoleCustomer},
+			},
+		}
+		if err := store.Users().Save(login.User); err != nil {
+			return nil, err
+		}
+	case login.User.EmailVerifiedAt == nil:
+		// Whoever registered the account never proved owning the email, which
+		// the provider just did.
+		login.Reclaimed = true
+		login.User.EmailVerifiedAt = &now
+		if err := s.reclaim(store, login.User); err != nil {
+			return nil, err
+		}
+	}
+
+	err = store.UserIdentities().Save(&domain.UserIdentity{
+		UserID:      login.User.ID,
+		Provider:    providerName,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return login, nil
+}
+
+// reclaim drops every way into the account that whoever registered it ahead
+// of the owner could have set up: the password, the verified phone, passkeys,
+// TOTP with its recovery codes, other linked identities and the sessions. The
+// access tokens already issued are left to the caller.
+func (s *FederationService) reclaim(store stores.Store, user *domain.User) error {
+	user.Password = ""
+	user.Phone = nil
+	user.PhoneVerifiedAt = nil
+	if err := store.Users().Save(user); err != nil {
+		return err
+	}
+
+	if err := store.WebAuthnCredentials().DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	if err := store.TOTPCredentials().Delete(user.ID); err != nil {
+		return err
+	}
+	if err := store.RecoveryCodes().DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	if err := store.UserIdentities().DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	return store.Tokens().RevokeByUser(user.ID)
+}
+
+func (s *FederationService) redirectURI(providerName string) string {
+	return s.callbackBaseURL + "/" + providerName + "/callback"
+}
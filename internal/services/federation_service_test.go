@@ -0,0 +1,169 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/federation"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFederationService() *FederationService {
+	return NewFederationService(
+		federation.NewRegistry(), utils.NewHMACTokenSigner([]byte("secret")), utils.NewTokenGenerator(),
+		"https://auth.example.com/auth/federation", 10*time.Minute,
+	)
+}
+
+func TestFederationService_Login(t *testing.T) {
+	verifiedAt := time.Now().Add(-time.Hour)
+	identity := &federation.Identity{
+		Subject: "subject-1", Email: "jane@example.com", EmailVerified: true, GivenName: "Jane", FamilyName: "Doe",
+	}
+
+	t.Run("Linked identity", func(t *testing.T) {
+		user := &domain.User{ID: uuid.New(), Email: "jane@example.com"}
+		linked := &domain.UserIdentity{ID: 1, UserID: user.ID, Provider: "google", Subject: "subject-1"}
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("UserIdentities").Return(mockIdentityRepo)
+		mockIdentityRepo.On("GetByProviderSubject", "google", "subject-1").Return(linked, nil)
+		mockIdentityRepo.On("Save", linked).Return(nil)
+		mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+		login, err := newTestFederationService().Login(mockStore, "google", identity)
+
+		require.NoError(t, err)
+		assert.Equal(t, &FederatedLogin{User: user}, login)
+		assert.NotNil(t, linked.LastLoginAt)
+	})
+
+	t.Run("New user", func(t *testing.T) {
+		var saved *domain.UserIdentity
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("UserIdentities").Return(mockIdentityRepo)
+		mockIdentityRepo.On("GetByProviderSubject", "google", "subject-1").Return(nil, nil)
+		mockIdentityRepo.On("Save", mock.AnythingOfType("*domain.UserIdentity")).
+			Run(func(args mock.Arguments) { saved = args.Get(0).(*domain.UserIdentity) }).
+			Return(nil)
+		mockUserRepo.On("GetByEmail", "jane@example.com").Return(nil, nil)
+		mockUserRepo.On("Save", mock.AnythingOfType("*domain.User")).
+			Run(func(args mock.Arguments) { args.Get(0).(*domain.User).ID = uuid.New() }).
+			Return(nil)
+
+		login, err := newTestFederationService().Login(mockStore, "google", identity)
+
+		require.NoError(t, err)
+		assert.True(t, login.Created)
+		assert.True(t, login.Linked)
+		assert.Equal(t, "Jane", login.User.Name)
+		assert.Equal(t, "Doe", login.User.Surname)
+		assert.Empty(t, login.User.Password)
+		assert.NotNil(t, login.User.EmailVerifiedAt)
+		assert.Equal(t, []string{domain.RoleCustomer}, login.User.RoleNames())
+		assert.Equal(t, login.User.ID, saved.UserID)
+		assert.Equal(t, "subject-1", saved.Subject)
+	})
+
+	t.Run("Existing verified user", func(t *testing.T) {
+		user := &domain.User{ID: uuid.New(), Email: "jane@example.com", Password: "hash", EmailVerifiedAt: &verifiedAt}
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("UserIdentities").Return(mockIdentityRepo)
+		mockIdentityRepo.On("GetByProviderSubject", "google", "subject-1").Return(nil, nil)
+		mockIdentityRepo.On("Save", mock.AnythingOfType("*domain.UserIdentity")).Return(nil)
+		mockUserRepo.On("GetByEmail", "jane@example.com").Return(user, nil)
+
+		login, err := newTestFederationService().Login(mockStore, "google", identity)
+
+		require.NoError(t, err)
+		assert.Equal(t, &FederatedLogin{User: user, Linked: true}, login)
+		assert.Equal(t, "hash", user.Password)
+		mockUserRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Account registered ahead of the owner", func(t *testing.T) {
+		phone := "+15550100"
+		user := &domain.User{
+			ID:              uuid.New(),
+			Email:           "jane@example.com",
+			Password:        "hash",
+			Phone:           &phone,
+			PhoneVerifiedAt: &verifiedAt,
+		}
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+		mockCredentialRepo := new(mocks.WebAuthnCredentialRepositoryMock)
+		mockTOTPRepo := new(mocks.TOTPCredentialRepositoryMock)
+		mockRecoveryRepo := new(mocks.RecoveryCodeRepositoryMock)
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("UserIdentities").Return(mockIdentityRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("WebAuthnCredentials").Return(mockCredentialRepo)
+		mockStore.On("TOTPCredentials").Return(mockTOTPRepo)
+		mockStore.On("RecoveryCodes").Return(mockRecoveryRepo)
+		mockIdentityRepo.On("GetByProviderSubject", "google", "subject-1").Return(nil, nil)
+		mockUserRepo.On("GetByEmail", "jane@example.com").Return(user, nil)
+		mockUserRepo.On("Save", user).Return(nil)
+
+		// Whoever registered first set up a passkey, TOTP and another
+		// identity, and all of it must go before the owner's identity is
+		// linked.
+		var dropped bool
+		mockCredentialRepo.On("DeleteByUser", user.ID).Return(nil).Once()
+		mockTOTPRepo.On("Delete", user.ID).Return(nil).Once()
+		mockRecoveryRepo.On("DeleteByUser", user.ID).Return(nil).Once()
+		mockIdentityRepo.On("DeleteByUser", user.ID).Run(func(mock.Arguments) { dropped = true }).Return(nil).Once()
+		mockTokenRepo.On("RevokeByUser", user.ID).Return(nil).Once()
+		mockIdentityRepo.On("Save", mock.MatchedBy(func(*domain.UserIdentity) bool { return dropped })).Return(nil).Once()
+
+		login, err := newTestFederationService().Login(mockStore, "google", identity)
+
+		require.NoError(t, err)
+		assert.True(t, login.Linked)
+		assert.True(t, login.Reclaimed)
+		assert.Empty(t, user.Password)
+		assert.Nil(t, user.Phone)
+		assert.Nil(t, user.PhoneVerifiedAt)
+		assert.NotNil(t, user.EmailVerifiedAt)
+		mockCredentialRepo.AssertExpectations(t)
+		mockTOTPRepo.AssertExpectations(t)
+		mockRecoveryRepo.AssertExpectations(t)
+		mockIdentityRepo.AssertExpectations(t)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unverified email", func(t *testing.T) {
+		mockStore := new(mocks.StoreMock)
+		mockIdentityRepo := new(mocks.UserIdentityRepositoryMock)
+		mockStore.On("UserIdentities").Return(mockIdentityRepo)
+		mockIdentityRepo.On("GetByProviderSubject", "google", "subject-2").Return(nil, nil)
+
+		_, err := newTestFederationService().Login(mockStore, "google", &federation.Identity{
+			Subject: "subject-2", Email: "jane@example.com",
+		})
+
+		assert.ErrorIs(t, err, ErrIdentityEmailNotVerified)
+	})
+}
+
+func TestFederationService_Begin_UnknownProvider(t *testing.T) {
+	_, _, err := newTestFederationService().Begin(t.Context(), "facebook")
+
+	assert.ErrorIs(t, err, federation.ErrUnknownProvider)
+}
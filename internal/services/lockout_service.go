@@ -0,0 +1,200 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+var (
+	AccountLocked   = "AccountLocked"
+	AccountUnlocked = "AccountUnlocked"
+)
+
+// LockedError is returned while logins for an account or an IP address are
+// blocked. It matches ErrAccountLocked.
+type LockedError struct {
+	Until time.Time
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s until %s", ErrAccountLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// RetryAfter is the time left until the lock ends, rounded up to a second.
+func (e *LockedError) RetryAfter() time.Duration {
+	return time.Until(e.Until).Truncate(time.Second) + time.Second
+}
+
+type LockoutPolicy struct {
+	// AccountThreshold and IPThreshold are the failed logins after which an
+	// account or an IP address is locked. Zero disables the lock.
+	AccountThreshold int
+	IPThreshold      int
+	// Cooldown is the length of the first lock. It doubles with every lock
+	// that follows without a successful login, up to MaxCooldown. Failures
+	// older than MaxCooldown are forgotten.
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+}
+
+// LockoutService counts failed logins per account and per IP address. The
+// counters must be saved in their own transaction, as the transaction of a
+// failed login is rolled back.
+type LockoutService struct {
+	policy LockoutPolicy
+}
+
+func NewLockoutService(policy LockoutPolicy) *LockoutService {
+	return &LockoutService{policy: policy}
+}
+
+// Check returns a *LockedError when logins for the email or the IP address are
+// blocked.
+func (s *LockoutService) Check(store stores.Store, email string, ip string) error {
+	now := time.Now()
+	var until time.Time
+	for _, key := range s.keys(email, ip) {
+		lockout, err := store.LoginLockouts().Get(key)
+		if err != nil {
+			return err
+		}
+		if lockout != nil && lockout.IsLocked(now) && lockout.LockedUntil.After(until) {
+			until = *lockout.LockedUntil
+		}
+	}
+
+	if until.IsZero() {
+		return nil
+	}
+	return &LockedError{Until: until}
+}
+
+// RecordFailure counts a failed login. locked is set when the failure starts
+// a lock, payload when that lock is on the account of a user and is meant for
+// the AccountLocked event.
+func (s *LockoutService) RecordFailure(
+	store stores.Store,
+	email string,
+	ip string,
+) (locked *LockedError, payload *AccountLockedPayload, err error) {
+	accountUntil, err := s.recordFailure(store, accountKey(email), s.policy.AccountThreshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	ipUntil, err := s.recordFailure(store, ipKey(ip), s.policy.IPThreshold)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, until := range []*time.Time{accountUntil, ipUntil} {
+		if until != nil && (locked == nil || until.After(locked.Until)) {
+			locked = &LockedError{Until: *until}
+		}
+	}
+	if accountUntil == nil {
+		return locked, nil, nil
+	}
+
+	user, err := store.Users().GetByEmail(email)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return locked, nil, nil
+	}
+
+	return locked, &AccountLockedPayload{
+		UserID:      user.ID,
+		Email:       user.Email,
+		IP:          ip,
+		LockedUntil: *accountUntil,
+	}, nil
+}
+
+// RecordSuccess forgets the failures of the account. The failures of the IP
+// address are kept, or one valid account would let an attacker reset them.
+func (s *LockoutService) RecordSuccess(store stores.Store, email string) error {
+	if s.policy.AccountThreshold <= 0 {
+		return nil
+	}
+	return store.LoginLockouts().Delete(accountKey(email))
+}
+
+// Unlock lifts the lock of the account of the user and forgets its failures.
+func (s *LockoutService) Unlock(store stores.Store, user *domain.User) error {
+	return store.LoginLockouts().Delete(accountKey(user.Email))
+}
+
+// recordFailure returns the end of the lock when the failure starts one.
+func (s *LockoutService) recordFailure(store stores.Store, key string, threshold int) (*time.Time, error) {
+	if key == "" || threshold <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	lockout, err := store.LoginLockouts().Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if lockout == nil || now.Sub(lockout.LastFailureAt) > s.policy.MaxCooldown {
+		lockout = &domain.LoginLockout{Key: key}
+	}
+
+	lockout.FailedAttempts++
+	lockout.LastFailureAt = now
+
+	var until *time.Time
+	if lockout.FailedAttempts >= threshold {
+		lockedUntil := now.Add(s.cooldown(lockout.Lockouts))
+		lockout.LockedUntil = &lockedUntil
+		lockout.Lockouts++
+		lockout.FailedAttempts = 0
+		until = &lockedUntil
+	}
+
+	if err := store.LoginLockouts().Save(lockout); err != nil {
+		return nil, err
+	}
+	return until, nil
+}
+
+func (s *LockoutService) cooldown(previousLockouts int) time.Duration {
+	cooldown := s.policy.Cooldown
+	for i := 0; i < previousLockouts && cooldown < s.policy.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	if s.policy.MaxCooldown > 0 && cooldown > s.policy.MaxCooldown {
+		cooldown = s.policy.MaxCooldown
+	}
+	return cooldown
+}
+
+func (s *LockoutService) keys(email string, ip string) []string {
+	var keys []string
+	if s.policy.AccountThreshold > 0 {
+		keys = append(keys, accountKey(email))
+	}
+	if s.policy.IPThreshold > 0 && ipKey(ip) != "" {
+		keys = append(keys, ipKey(ip))
+	}
+	return keys
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	if net.ParseIP(ip) == nil {
+		return ""
+	}
+	return "ip:" + ip
+}
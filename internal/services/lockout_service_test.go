@@ -0,0 +1,146 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newLockoutStore keeps the lockouts in a map.
+func newLockoutStore(user *domain.User) (*mocks.StoreMock, map[string]domain.LoginLockout) {
+	lockouts := make(map[string]domain.LoginLockout)
+
+	mockStore := new(mocks.StoreMock)
+	mockLockoutRepo := new(mocks.LoginLockoutRepositoryMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+
+	mockStore.On("LoginLockouts").Return(mockLockoutRepo)
+	mockStore.On("Users").Return(mockUserRepo)
+	mockUserRepo.On("GetByEmail", mock.Anything).Return(user, nil)
+	mockLockoutRepo.On("Get", mock.Anything).Return(func(key string) (*domain.LoginLockout, error) {
+		lockout, ok := lockouts[key]
+		if !ok {
+			return nil, nil
+		}
+		return &lockout, nil
+	})
+	mockLockoutRepo.On("Save", mock.Anything).Return(func(lockout *domain.LoginLockout) error {
+		lockouts[lockout.Key] = *lockout
+		return nil
+	})
+	mockLockoutRepo.On("Delete", mock.Anything).Return(func(key string) error {
+		delete(lockouts, key)
+		return nil
+	})
+
+	return mockStore, lockouts
+}
+
+func TestLockoutService_LocksAccountAfterThreshold(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com"}
+	store, _ := newLockoutStore(user)
+	lockoutSvc := NewLockoutService(LockoutPolicy{
+		AccountThreshold: 3,
+		IPThreshold:      100,
+		Cooldown:         time.Minute,
+		MaxCooldown:      time.Hour,
+	})
+
+	for i := 0; i < 2; i++ {
+		locked, payload, err := lockoutSvc.RecordFailure(store, "john@example.com", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Nil(t, locked)
+		assert.Nil(t, payload)
+	}
+	assert.NoError(t, lockoutSvc.Check(store, "john@example.com", "10.0.0.1"))
+
+	locked, payload, err := lockoutSvc.RecordFailure(store, "John@Example.com", "10.0.0.2")
+	require.NoError(t, err)
+	require.NotNil(t, locked)
+	require.NotNil(t, payload)
+	assert.Equal(t, user.ID, payload.UserID)
+	assert.Equal(t, "10.0.0.2", payload.IP)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), locked.Until, time.Second)
+
+	err = lockoutSvc.Check(store, "john@example.com", "10.0.0.3")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	var lockedErr *LockedError
+	require.ErrorAs(t, err, &lockedErr)
+	assert.InDelta(t, time.Minute.Seconds(), lockedErr.RetryAfter().Seconds(), 1)
+
+	assert.NoError(t, lockoutSvc.Unlock(store, user))
+	assert.NoError(t, lockoutSvc.Check(store, "john@example.com", "10.0.0.3"))
+}
+
+func TestLockoutService_CooldownDoubles(t *testing.T) {
+	store, lockouts := newLockoutStore(nil)
+	lockoutSvc := NewLockoutService(LockoutPolicy{
+		AccountThreshold: 1,
+		Cooldown:         time.Minute,
+		MaxCooldown:      3 * time.Minute,
+	})
+
+	var durations []time.Duration
+	for i := 0; i < 4; i++ {
+		start := time.Now()
+		locked, payload, err := lockoutSvc.RecordFailure(store, "nobody@example.com", "")
+		require.NoError(t, err)
+		require.NotNil(t, locked)
+		assert.Nil(t, payload, "there is no user to notify")
+		durations = append(durations, locked.Until.Sub(start).Round(time.Minute))
+	}
+
+	assert.Equal(t, []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute}, durations)
+	assert.Equal(t, 4, lockouts["account:nobody@example.com"].Lockouts)
+
+	assert.NoError(t, lockoutSvc.RecordSuccess(store, "nobody@example.com"))
+	assert.NotContains(t, lockouts, "account:nobody@example.com")
+}
+
+func TestLockoutService_LocksIP(t *testing.T) {
+	store, lockouts := newLockoutStore(nil)
+	lockoutSvc := NewLockoutService(LockoutPolicy{
+		AccountThreshold: 10,
+		IPThreshold:      2,
+		Cooldown:         time.Minute,
+		MaxCooldown:      time.Hour,
+	})
+
+	_, _, err := lockoutSvc.RecordFailure(store, "a@example.com", "10.0.0.1")
+	require.NoError(t, err)
+	locked, payload, err := lockoutSvc.RecordFailure(store, "b@example.com", "10.0.0.1")
+	require.NoError(t, err)
+	assert.NotNil(t, locked)
+	assert.Nil(t, payload)
+
+	assert.ErrorIs(t, lockoutSvc.Check(store, "c@example.com", "10.0.0.1"), ErrAccountLocked)
+	assert.NoError(t, lockoutSvc.Check(store, "c@example.com", "10.0.0.2"))
+
+	// A success does not forget the failures of the IP address.
+	assert.NoError(t, lockoutSvc.RecordSuccess(store, "a@example.com"))
+	assert.Contains(t, lockouts, "ip:10.0.0.1")
+}
+
+func TestLockoutService_ForgetsOldFailures(t *testing.T) {
+	store, lockouts := newLockoutStore(nil)
+	lockoutSvc := NewLockoutService(LockoutPolicy{AccountThreshold: 2, Cooldown: time.Minute, MaxCooldown: time.Hour})
+
+	lockouts["account:john@example.com"] = domain.LoginLockout{
+		Key:            "account:john@example.com",
+		FailedAttempts: 1,
+		Lockouts:       3,
+		LastFailureAt:  time.Now().Add(-2 * time.Hour),
+	}
+
+	locked, _, err := lockoutSvc.RecordFailure(store, "john@example.com", "")
+	require.NoError(t, err)
+	assert.Nil(t, locked)
+	assert.Equal(t, 1, lockouts["account:john@example.com"].FailedAttempts)
+	assert.Equal(t, 0, lockouts["account:john@example.com"].Lockouts)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"strings"
+	"time"
+)
+
+var MagicLinkRequested = "MagicLinkRequested"
+
+// MagicLinkService runs the passwordless login by email. Links carry a
+// single-use token made of a selector, which finds the stored token, and a
+// verifier, of which only the hash is stored. Requesting a new link
+// invalidates the ones sent before.
+type MagicLinkService struct {
+	hasher         utils.PasswordHasher
+	tokenGenerator utils.TokenGenerator
+	ttl            time.Duration
+}
+
+func NewMagicLinkService(
+	hasher utils.PasswordHasher,
+	tokenGenerator utils.TokenGenerator,
+	ttl time.Duration,
+) *MagicLinkService {
+	return &MagicLinkService{
+		hasher:         hasher,
+		tokenGenerator: tokenGenerator,
+		ttl:            ttl,
+	}
+}
+
+// RequestLink stores a login token for the user registered under email. It
+// returns nil when there is nobody to send it to, which callers must not
+// reveal.
+func (s *MagicLinkService) RequestLink(
+	store stores.Store,
+	email string,
+) (*MagicLinkRequestedPayload, error) {
+	user, err := store.Users().GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	selector, err := s.tokenGenerator.GenerateSecureToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := s.tokenGenerator.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	verifierHash, err := s.hasher.Hash(verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.MagicLinkTokens().DeleteByUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	err = store.MagicLinkTokens().Save(&domain.MagicLinkToken{
+		UserID:       user.ID,
+		Selector:     selector,
+		VerifierHash: verifierHash,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &MagicLinkRequestedPayload{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Token:     selector + "." + verifier,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Consume uses up the token and returns the user it was sent to.
+func (s *MagicLinkService) Consume(
+	store stores.Store,
+	token string,
+) (*domain.User, error) {
+	selector, verifier, ok := strings.Cut(token, ".")
+	if !ok || selector == "" || verifier == "" {
+		return nil, ErrInvalidToken
+	}
+
+	linkToken, err := store.MagicLinkTokens().GetBySelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if linkToken == nil || linkToken.UsedAt != nil || linkToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+	if !s.hasher.Verify(verifier, linkToken.VerifierHash) {
+		return nil, ErrInvalidToken
+	}
+
+	usedAt := time.Now()
+	linkToken.UsedAt = &usedAt
+	if err := store.MagicLinkTokens().Save(linkToken); err != nil {
+		return nil, err
+	}
+
+	user, err := store.Users().GetByID(linkToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	return user, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkService(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com"}
+
+	var saved *domain.MagicLinkToken
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockLinkRepo := new(mocks.MagicLinkTokenRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("MagicLinkTokens").Return(mockLinkRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, nil)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	mockLinkRepo.On("DeleteByUser", user.ID).Return(nil)
+	mockLinkRepo.On("Save", mock.AnythingOfType("*domain.MagicLinkToken")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*domain.MagicLinkToken) }).
+		Return(nil)
+	mockLinkRepo.On("GetBySelector", mock.Anything).Return(func(selector string) (*domain.MagicLinkToken, error) {
+		if saved == nil || saved.Selector != selector {
+			return nil, nil
+		}
+		return saved, nil
+	})
+
+	linkSvc := NewMagicLinkService(utils.NewBcryptHasher(), utils.NewTokenGenerator(), 15*time.Minute)
+
+	payload, err := linkSvc.RequestLink(mockStore, "nobody@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+
+	payload, err = linkSvc.RequestLink(mockStore, "john@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, payload.UserID)
+	assert.Equal(t, "john@example.com", payload.Email)
+	selector, verifier, ok := strings.Cut(payload.Token, ".")
+	require.True(t, ok)
+	assert.Equal(t, selector, saved.Selector)
+	assert.NotContains(t, saved.VerifierHash, verifier)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), saved.ExpiresAt, time.Minute)
+
+	t.Run("Wrong verifier", func(t *testing.T) {
+		_, err := linkSvc.Consume(mockStore, selector+".guess")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Malformed token", func(t *testing.T) {
+		_, err := linkSvc.Consume(mockStore, verifier)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		consumed, err := linkSvc.Consume(mockStore, payload.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user, consumed)
+		assert.NotNil(t, saved.UsedAt)
+	})
+
+	t.Run("Used twice", func(t *testing.T) {
+		_, err := linkSvc.Consume(mockStore, payload.Token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		payload, err := linkSvc.RequestLink(mockStore, "john@example.com")
+		require.NoError(t, err)
+		saved.ExpiresAt = time.Now().Add(-time.Second)
+
+		_, err = linkSvc.Consume(mockStore, payload.Token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+var (
+	MFAEnabled               = "MFAEnabled"
+	MFADisabled              = "MFADisabled"
+	RecoveryCodesRegenerated = "RecoveryCodesRegenerated"
+)
+
+const (
+	mfaChallengePurpose = "mfa_login"
+	recoveryCodeCount   = 10
+)
+
+// TOTPEnrollment is what the user needs to add the account to an
+// authenticator app, either by hand or by scanning URI as a QR code.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// MFAService manages TOTP two-factor authentication. A login of a user with a
+// confirmed TOTP credential stops at a challenge token, which is exchanged for
+// tokens together with a TOTP or recovery code.
+type MFAService struct {
+	totp         *utils.TOTP
+	signer       utils.TokenSigner
+	issuer       string
+	challengeTTL time.Duration
+	now          func() time.Time
+}
+
+func NewMFAService(
+	totp *utils.TOTP,
+	signer utils.TokenSigner,
+	issuer string,
+	challengeTTL time.Duration,
+) *MFAService {
+	return &MFAService{
+		totp:         totp,
+		signer:       signer,
+		issuer:       issuer,
+		challengeTTL: challengeTTL,
+		now:          time.Now,
+	}
+}
+
+// Enabled reports whether logins of the user need a second factor. A nil
+// MFAService has it disabled for everyone.
+func (s *MFAService) Enabled(store stores.Store, userID uuid.UUID) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+
+	credential, err := store.TOTPCredentials().Get(userID)
+	if err != nil {
+		return false, err
+	}
+	return credential != nil && credential.ConfirmedAt != nil, nil
+}
+
+// Enroll generates a new secret for the user. It replaces an enrollment that
+// was never confirmed, but not a confirmed one.
+func (s *MFAService) Enroll(store stores.Store, user *domain.User) (*TOTPEnrollment, error) {
+	credential, err := store.TOTPCredentials().Get(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credential != nil && credential.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if credential == nil {
+		credential = &domain.TOTPCredential{UserID: user.ID}
+	}
+	credential.Secret = secret
+	credential.LastUsedStep = 0
+	if err := store.TOTPCredentials().Save(credential); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    s.totp.URI(secret, s.issuer, user.Email),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the user proved the app is
+// set up with a first code. It returns the recovery codes, which are not
+// stored and cannot be shown again.
+func (s *MFAService) Confirm(store stores.Store, user *domain.User, code string) ([]string, error) {
+	credential, err := store.TOTPCredentials().Get(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if credential == nil {
+		return nil, ErrMFANotEnabled
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := s.verifyTOTP(store, credential, code); err != nil {
+		return nil, err
+	}
+
+	confirmedAt := s.now()
+	credential.ConfirmedAt = &confirmedAt
+	if err := store.TOTPCredentials().Save(credential); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(store, user.ID)
+}
+
+// Disable turns two-factor authentication off. It needs a current TOTP or
+// recovery code, so a stolen session alone cannot remove the second factor.
+func (s *MFAService) Disable(store stores.Store, user *domain.User, code string) error {
+	if err := s.VerifyCode(store, user.ID, code); err != nil {
+		return err
+	}
+
+	if err := store.RecoveryCodes().DeleteByUser(user.ID); err != nil {
+		return err
+	}
+	return store.TOTPCredentials().Delete(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the user.
+func (s *MFAService) RegenerateRecoveryCodes(store stores.Store, user *domain.User, code string) ([]string, error) {
+	if err := s.VerifyCode(store, user.ID, code); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(store, user.ID)
+}
+
+// Challenge signs the token a login hands out instead of tokens when the user
+// has two-factor authentication enabled.
+func (s *MFAService) Challenge(user *domain.User) (string, error) {
+	return s.signer.Sign(utils.SignedTokenClaims{
+		Purpose:   mfaChallengePurpose,
+		Subject:   user.ID.String(),
+		Email:     user.Email,
+		ExpiresAt: s.now().Add(s.challengeTTL).Unix(),
+	})
+}
+
+// ChallengeUser returns the user a challenge token was issued to.
+func (s *MFAService) ChallengeUser(store stores.Store, token string) (*domain.User, error) {
+	claims, err := s.signer.Verify(token, mfaChallengePurpose)
+	if errors.Is(err, utils.ErrInvalidToken) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := store.Users().GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Email != claims.Email {
+		return nil, ErrInvalidToken
+	}
+
+	return user, nil
+}
+
+// VerifyCode accepts a TOTP code or an unused recovery code of the user, and
+// uses it up.
+func (s *MFAService) VerifyCode(store stores.Store, userID uuid.UUID, code string) error {
+	credential, err := store.TOTPCredentials().Get(userID)
+	if err != nil {
+		return err
+	}
+	if credential == nil || credential.ConfirmedAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == s.totp.Digits {
+		return s.verifyTOTP(store, credential, code)
+	}
+
+	recoveryCode, err := store.RecoveryCodes().GetUnused(userID, hashRecoveryCode(code))
+	if err != nil {
+		return err
+	}
+	if recoveryCode == nil {
+		return ErrInvalidMFACode
+	}
+
+	usedAt := s.now()
+	recoveryCode.UsedAt = &usedAt
+	return store.RecoveryCodes().Save(recoveryCode)
+}
+
+func (s *MFAService) verifyTOTP(store stores.Store, credential *domain.TOTPCredential, code string) error {
+	step, ok := s.totp.Verify(credential.Secret, code, s.now(), credential.LastUsedStep)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	credential.LastUsedStep = step
+	return store.TOTPCredentials().Save(credential)
+}
+
+func (s *MFAService) replaceRecoveryCodes(store stores.Store, userID uuid.UUID) ([]string, error) {
+	if err := store.RecoveryCodes().DeleteByUser(userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	records := make([]domain.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		records[i] = domain.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)}
+	}
+
+	if err := store.RecoveryCodes().Create(records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns 80 random bits as four groups of base32
+// characters, such as "ABCD-EFGH-IJKL-MNOP".
+func generateRecoveryCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+
+	encoded := base32.StdEncoding.EncodeToString(raw)
+	return strings.Join([]string{encoded[0:4], encoded[4:8], encoded[8:12], encoded[12:16]}, "-"), nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which users mistype.
+func hashRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return utils.HashToken(code)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMFAStore returns a store whose TOTP credential and recovery codes live in
+// the returned pointers.
+func newMFAStore(user *domain.User) (*mocks.StoreMock, **domain.TOTPCredential, *[]domain.RecoveryCode) {
+	var credential *domain.TOTPCredential
+	var codes []domain.RecoveryCode
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockCredentialRepo := new(mocks.TOTPCredentialRepositoryMock)
+	mockCodeRepo := new(mocks.RecoveryCodeRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("TOTPCredentials").Return(mockCredentialRepo)
+	mockStore.On("RecoveryCodes").Return(mockCodeRepo)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	mockCredentialRepo.On("Get", user.ID).Return(func(uuid.UUID) (*domain.TOTPCredential, error) {
+		if credential == nil {
+			return nil, nil
+		}
+		copied := *credential
+		return &copied, nil
+	})
+	mockCredentialRepo.On("Save", mock.Anything).Return(func(c *domain.TOTPCredential) error {
+		copied := *c
+		credential = &copied
+		return nil
+	})
+	mockCredentialRepo.On("Delete", user.ID).Return(func(uuid.UUID) error {
+		credential = nil
+		return nil
+	})
+
+	mockCodeRepo.On("Create", mock.Anything).Return(func(created []domain.RecoveryCode) error {
+		codes = append(codes, created...)
+		return nil
+	})
+	mockCodeRepo.On("DeleteByUser", user.ID).Return(func(uuid.UUID) error {
+		codes = nil
+		return nil
+	})
+	mockCodeRepo.On("GetUnused", user.ID, mock.Anything).Return(func(_ uuid.UUID, hash string) (*domain.RecoveryCode, error) {
+		for i := range codes {
+			if codes[i].CodeHash == hash && codes[i].UsedAt == nil {
+				return &codes[i], nil
+			}
+		}
+		return nil, nil
+	})
+	mockCodeRepo.On("Save", mock.Anything).Return(nil)
+
+	return mockStore, &credential, &codes
+}
+
+func currentTOTPCode(t *testing.T, totp *utils.TOTP, secret string, at time.Time) string {
+	key, err := utils.DecodeTOTPSecret(secret)
+	require.NoError(t, err)
+	return totp.Code(key, totp.Step(at))
+}
+
+func TestMFAService(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com"}
+	mockStore, credential, codes := newMFAStore(user)
+
+	now := time.Unix(1_800_000_000, 0)
+	totp := utils.NewTOTP()
+	mfaSvc := NewMFAService(totp, utils.NewHMACTokenSigner([]byte("secret")), "Shop", 5*time.Minute)
+	mfaSvc.now = func() time.Time { return now }
+
+	enrollment, err := mfaSvc.Enroll(mockStore, user)
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/Shop:john@example.com")
+
+	enabled, _ := mfaSvc.Enabled(mockStore, user.ID)
+	assert.False(t, enabled, "enrollment is pending until confirmed")
+
+	_, err = mfaSvc.Confirm(mockStore, user, "000000")
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+	recoveryCodes, err := mfaSvc.Confirm(mockStore, user, currentTOTPCode(t, totp, enrollment.Secret, now))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	assert.Len(t, *codes, recoveryCodeCount)
+	assert.NotEqual(t, recoveryCodes[0], (*codes)[0].CodeHash)
+
+	enabled, _ = mfaSvc.Enabled(mockStore, user.ID)
+	assert.True(t, enabled)
+
+	_, err = mfaSvc.Enroll(mockStore, user)
+	assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+
+	t.Run("TOTP codes cannot be replayed", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+		code := currentTOTPCode(t, totp, enrollment.Secret, now)
+
+		assert.NoError(t, mfaSvc.VerifyCode(mockStore, user.ID, code))
+		assert.ErrorIs(t, mfaSvc.VerifyCode(mockStore, user.ID, code), ErrInvalidMFACode)
+		assert.Equal(t, totp.Step(now), (*credential).LastUsedStep)
+	})
+
+	t.Run("Recovery codes work once", func(t *testing.T) {
+		typed := " " + strings.ToLower(strings.ReplaceAll(recoveryCodes[0], "-", " ")) + "\n"
+
+		assert.NoError(t, mfaSvc.VerifyCode(mockStore, user.ID, typed))
+		assert.ErrorIs(t, mfaSvc.VerifyCode(mockStore, user.ID, recoveryCodes[0]), ErrInvalidMFACode)
+		assert.ErrorIs(t, mfaSvc.VerifyCode(mockStore, user.ID, "not-a-code"), ErrInvalidMFACode)
+	})
+
+	t.Run("Challenge names the user", func(t *testing.T) {
+		token, err := mfaSvc.Challenge(user)
+		require.NoError(t, err)
+
+		challenged, err := mfaSvc.ChallengeUser(mockStore, token)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, challenged.ID)
+
+		_, err = mfaSvc.ChallengeUser(mockStore, token+"x")
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Disable needs a code", func(t *testing.T) {
+		assert.ErrorIs(t, mfaSvc.Disable(mockStore, user, "000000"), ErrInvalidMFACode)
+		assert.NoError(t, mfaSvc.Disable(mockStore, user, recoveryCodes[1]))
+		assert.Nil(t, *credential)
+		assert.Empty(t, *codes)
+	})
+}
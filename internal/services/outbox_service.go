@@ -2,8 +2,94 @@ package services
 
 import (
 	"app/internal/stores"
+	"github.com/google/uuid"
+	"time"
 )
 
+// UserLoggedOutPayload is the payload of the UserLoggedOut event. SessionID is
+// empty when every session of the user was ended.
+type UserLoggedOutPayload struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	SessionID *uuid.UUID `json:"session_id,omitempty"`
+}
+
+// VerificationRequestedPayload is the payload of the VerificationRequested
+// event. The mailer builds the verification link from Token.
+type VerificationRequestedPayload struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PasswordResetRequestedPayload is the payload of the PasswordResetRequested
+// event. Only the hash of Token is stored; the mailer builds the reset link
+// from Token itself.
+type PasswordResetRequestedPayload struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MagicLinkRequestedPayload is the payload of the MagicLinkRequested event.
+// Only the hash of Token is stored; the mailer builds the login link from
+// Token itself.
+type MagicLinkRequestedPayload struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PasswordChangedPayload is the payload of the PasswordChanged event.
+type PasswordChangedPayload struct {
+	UserID               uuid.UUID `json:"user_id"`
+	OtherSessionsRevoked bool      `json:"other_sessions_revoked"`
+}
+
+// AccountLockedPayload is the payload of the AccountLocked event. IP is the
+// address of the failed login that locked the account.
+type AccountLockedPayload struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Email       string    `json:"email"`
+	IP          string    `json:"ip"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// AccountUnlockedPayload is the payload of the AccountUnlocked event.
+type AccountUnlockedPayload struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UnlockedBy string    `json:"unlocked_by"`
+}
+
+// MFAPayload is the payload of the MFAEnabled, MFADisabled and
+// RecoveryCodesRegenerated events.
+type MFAPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+}
+
+// WebAuthnCredentialRegisteredPayload is the payload of the
+// WebAuthnCredentialRegistered event.
+type WebAuthnCredentialRegisteredPayload struct {
+	UserID       uuid.UUID `json:"user_id"`
+	CredentialID uint      `json:"credential_id"`
+	Name         string    `json:"name"`
+}
+
+// PhoneVerifiedPayload is the payload of the PhoneVerified event.
+type PhoneVerifiedPayload struct {
+	UserID uuid.UUID `json:"user_id"`
+	Phone  string    `json:"phone"`
+}
+
+// IdentityLinkedPayload is the payload of the IdentityLinked event.
+type IdentityLinkedPayload struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+	Email    string    `json:"email"`
+}
+
 type UserTokenOutboxService struct {
 }
 
@@ -11,10 +97,74 @@ func NewOutboxService() *UserTokenOutboxService {
 	return &UserTokenOutboxService{}
 }
 
-func (s *UserTokenOutboxService) SaveUserRegisteredEvent(store *stores.UserTokenOutboxStore, payload interface{}) error {
+func (s *UserTokenOutboxService) SaveUserRegisteredEvent(store stores.Store, payload interface{}) error {
 	return store.Outbox().Save(UserRegistered, payload)
 }
 
-func (s *UserTokenOutboxService) SaveUserLoggedInEvent(store *stores.UserTokenOutboxStore, payload interface{}) error {
+func (s *UserTokenOutboxService) SaveUserLoggedInEvent(store stores.Store, payload interface{}) error {
 	return store.Outbox().Save(UserLoggedIn, payload)
 }
+
+func (s *UserTokenOutboxService) SaveRefreshTokenReuseDetectedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(RefreshTokenReuseDetected, payload)
+}
+
+func (s *UserTokenOutboxService) SaveUserLoggedOutEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(UserLoggedOut, payload)
+}
+
+func (s *UserTokenOutboxService) SaveVerificationRequestedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(VerificationRequested, payload)
+}
+
+func (s *UserTokenOutboxService) SaveEmailVerifiedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(EmailVerified, payload)
+}
+
+func (s *UserTokenOutboxService) SavePasswordResetRequestedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(PasswordResetRequested, payload)
+}
+
+func (s *UserTokenOutboxService) SavePasswordResetEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(PasswordReset, payload)
+}
+
+func (s *UserTokenOutboxService) SavePasswordChangedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(PasswordChanged, payload)
+}
+
+func (s *UserTokenOutboxService) SaveAccountLockedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(AccountLocked, payload)
+}
+
+func (s *UserTokenOutboxService) SaveAccountUnlockedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(AccountUnlocked, payload)
+}
+
+func (s *UserTokenOutboxService) SaveMFAEnabledEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(MFAEnabled, payload)
+}
+
+func (s *UserTokenOutboxService) SaveMFADisabledEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(MFADisabled, payload)
+}
+
+func (s *UserTokenOutboxService) SaveRecoveryCodesRegeneratedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(RecoveryCodesRegenerated, payload)
+}
+
+func (s *UserTokenOutboxService) SaveWebAuthnCredentialRegisteredEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(WebAuthnCredentialRegistered, payload)
+}
+
+func (s *UserTokenOutboxService) SaveMagicLinkRequestedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(MagicLinkRequested, payload)
+}
+
+func (s *UserTokenOutboxService) SavePhoneVerifiedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(PhoneVerified, payload)
+}
+
+func (s *UserTokenOutboxService) SaveIdentityLinkedEvent(store stores.Store, payload interface{}) error {
+	return store.Outbox().Save(IdentityLinked, payload)
+}
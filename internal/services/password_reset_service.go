@@ -0,0 +1,130 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"app/internal/validators"
+	"time"
+)
+
+var (
+	PasswordResetRequested = "PasswordResetRequested"
+	PasswordReset          = "PasswordReset"
+	PasswordChanged        = "PasswordChanged"
+)
+
+// PasswordResetService runs the forgot-password flow. Reset tokens are random,
+// stored as hashes and can be used once; requesting a new one invalidates the
+// ones sent before.
+type PasswordResetService struct {
+	hasher         utils.PasswordHasher
+	tokenGenerator utils.TokenGenerator
+	passwordPolicy *validators.PasswordPolicy
+	ttl            time.Duration
+}
+
+func NewPasswordResetService(
+	hasher utils.PasswordHasher,
+	tokenGenerator utils.TokenGenerator,
+	passwordPolicy *validators.PasswordPolicy,
+	ttl time.Duration,
+) *PasswordResetService {
+	return &PasswordResetService{
+		hasher:         hasher,
+		tokenGenerator: tokenGenerator,
+		passwordPolicy: passwordPolicy,
+		ttl:            ttl,
+	}
+}
+
+// RequestReset stores a reset token for the user registered under email. It
+// returns nil when there is nobody to send it to, which callers must not
+// reveal.
+func (s *PasswordResetService) RequestReset(
+	store stores.Store,
+	email string,
+) (*PasswordResetRequestedPayload, error) {
+	user, err := store.Users().GetByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	token, err := s.tokenGenerator.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.PasswordResetTokens().DeleteByUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	err = store.PasswordResetTokens().Save(&domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasswordResetRequestedPayload{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ResetPassword consumes the token, sets the new password and revokes every
+// refresh token of the user.
+func (s *PasswordResetService) ResetPassword(
+	store stores.Store,
+	token string,
+	password string,
+) (*domain.User, error) {
+	resetToken, err := store.PasswordResetTokens().GetByHash(utils.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if resetToken == nil || resetToken.UsedAt != nil || resetToken.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := store.Users().GetByID(resetToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidToken
+	}
+
+	// The token stays usable, so the user can retry with another password.
+	if s.passwordPolicy.ContainsPersonalInfo(password, user.Email, user.Name, user.Surname) {
+		return nil, ErrPasswordContainsPersonalInfo
+	}
+
+	usedAt := time.Now()
+	resetToken.UsedAt = &usedAt
+	if err := store.PasswordResetTokens().Save(resetToken); err != nil {
+		return nil, err
+	}
+
+	user.Password, err = s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Users().Save(user); err != nil {
+		return nil, err
+	}
+
+	if err := store.Tokens().DeleteByUser(user.ID); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
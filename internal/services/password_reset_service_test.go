@@ -0,0 +1,101 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPasswordResetService_RequestReset(t *testing.T) {
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com"}
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockResetRepo := new(mocks.PasswordResetTokenRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("PasswordResetTokens").Return(mockResetRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockUserRepo.On("GetByEmail", "nobody@example.com").Return(nil, nil)
+	mockResetRepo.On("DeleteByUser", user.ID).Return(nil)
+
+	var saved *domain.PasswordResetToken
+	mockResetRepo.On("Save", mock.AnythingOfType("*domain.PasswordResetToken")).
+		Run(func(args mock.Arguments) { saved = args.Get(0).(*domain.PasswordResetToken) }).
+		Return(nil)
+
+	resetSvc := NewPasswordResetService(nil, utils.NewTokenGenerator(), nil, 30*time.Minute)
+
+	payload, err := resetSvc.RequestReset(mockStore, "john@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, payload.UserID)
+	assert.NotEmpty(t, payload.Token)
+	assert.Equal(t, utils.HashToken(payload.Token), saved.TokenHash)
+	assert.NotEqual(t, payload.Token, saved.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), saved.ExpiresAt, time.Minute)
+
+	payload, err = resetSvc.RequestReset(mockStore, "nobody@example.com")
+	assert.NoError(t, err)
+	assert.Nil(t, payload)
+}
+
+func TestPasswordResetService_ResetPassword(t *testing.T) {
+	userID := uuid.New()
+	newStore := func(resetToken *domain.PasswordResetToken) (*mocks.StoreMock, *mocks.UserRepositoryMock, *mocks.TokenRepositoryMock) {
+		mockStore := new(mocks.StoreMock)
+		mockUserRepo := new(mocks.UserRepositoryMock)
+		mockTokenRepo := new(mocks.TokenRepositoryMock)
+		mockResetRepo := new(mocks.PasswordResetTokenRepositoryMock)
+
+		mockStore.On("Users").Return(mockUserRepo)
+		mockStore.On("Tokens").Return(mockTokenRepo)
+		mockStore.On("PasswordResetTokens").Return(mockResetRepo)
+		mockResetRepo.On("GetByHash", utils.HashToken("reset-token")).Return(resetToken, nil)
+		mockResetRepo.On("Save", mock.Anything).Return(nil)
+		mockUserRepo.On("GetByID", userID).Return(&domain.User{ID: userID, Password: "old_hash"}, nil)
+		mockUserRepo.On("Save", mock.Anything).Return(nil)
+		mockTokenRepo.On("DeleteByUser", userID).Return(nil)
+
+		return mockStore, mockUserRepo, mockTokenRepo
+	}
+
+	mockHasher := new(mocks.PasswordHasherMock)
+	mockHasher.On("Hash", "new-password").Return("new_hash", nil)
+	resetSvc := NewPasswordResetService(mockHasher, utils.NewTokenGenerator(), nil, 30*time.Minute)
+
+	t.Run("Success", func(t *testing.T) {
+		resetToken := &domain.PasswordResetToken{UserID: userID, ExpiresAt: time.Now().Add(time.Minute)}
+		mockStore, mockUserRepo, mockTokenRepo := newStore(resetToken)
+
+		user, err := resetSvc.ResetPassword(mockStore, "reset-token", "new-password")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new_hash", user.Password)
+		assert.NotNil(t, resetToken.UsedAt)
+		mockUserRepo.AssertCalled(t, "Save", user)
+		mockTokenRepo.AssertCalled(t, "DeleteByUser", userID)
+	})
+
+	t.Run("Rejects", func(t *testing.T) {
+		usedAt := time.Now()
+		tokens := map[string]*domain.PasswordResetToken{
+			"unknown": nil,
+			"used":    {UserID: userID, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt},
+			"expired": {UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)},
+		}
+		for name, resetToken := range tokens {
+			mockStore, _, mockTokenRepo := newStore(resetToken)
+
+			_, err := resetSvc.ResetPassword(mockStore, "reset-token", "new-password")
+
+			assert.ErrorIs(t, err, ErrInvalidToken, name)
+			mockTokenRepo.AssertNotCalled(t, "DeleteByUser", userID)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/sms"
+	"app/internal/stores"
+	"app/internal/utils"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var PhoneVerified = "PhoneVerified"
+
+// Purposes of SMS codes. A code only works for the purpose it was sent for.
+const (
+	SMSCodeLogin       = "login"
+	SMSCodeVerifyPhone = "verify_phone"
+)
+
+const smsCodeDigits = 6
+
+// SMSCodeService sends one-time codes by SMS to confirm the phone of a user
+// and to log in with it. Codes are stored as hashes, expire after ttl and stop
+// working after maxAttempts wrong guesses; sending a new code invalidates the
+// ones sent before.
+type SMSCodeService struct {
+	hasher      utils.PasswordHasher
+	sender      sms.SMSSender
+	ttl         time.Duration
+	maxAttempts int
+}
+
+func NewSMSCodeService(
+	hasher utils.PasswordHasher,
+	sender sms.SMSSender,
+	ttl time.Duration,
+	maxAttempts int,
+) *SMSCodeService {
+	return &SMSCodeService{
+		hasher:      hasher,
+		sender:      sender,
+		ttl:         ttl,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// RequestPhoneVerification stores a code that confirms phone as the phone of
+// user and returns it for Send.
+func (s *SMSCodeService) RequestPhoneVerification(
+	store stores.Store,
+	user *domain.User,
+	phone string,
+) (string, error) {
+	owner, err := store.Users().GetByPhone(phone)
+	if err != nil {
+		return "", err
+	}
+	if owner != nil && owner.ID != user.ID {
+		return "", ErrPhoneTaken
+	}
+
+	return s.issue(store, user, phone, SMSCodeVerifyPhone)
+}
+
+// ConfirmPhone checks the code and sets phone as the verified phone of user.
+// A wrong code is counted before ErrInvalidSMSCode is returned, so callers
+// must commit in that case too.
+func (s *SMSCodeService) ConfirmPhone(
+	store stores.Store,
+	user *domain.User,
+	phone string,
+	code string,
+) error {
+	smsCode, err := s.verify(store, phone, SMSCodeVerifyPhone, code)
+	if err != nil {
+		return err
+	}
+	if smsCode.UserID != user.ID {
+		return ErrInvalidSMSCode
+	}
+
+	owner, err := store.Users().GetByPhone(phone)
+	if err != nil {
+		return err
+	}
+	if owner != nil && owner.ID != user.ID {
+		return ErrPhoneTaken
+	}
+
+	verifiedAt := time.Now()
+	user.Phone = &phone
+	user.PhoneVerifiedAt = &verifiedAt
+	return store.Users().Save(user)
+}
+
+// RequestLogin stores a login code for the user with the verified phone and
+// returns it for Send. It returns an empty code when no user has this phone,
+// which callers must not reveal.
+func (s *SMSCodeService) RequestLogin(store stores.Store, phone string) (string, error) {
+	user, err := store.Users().GetByPhone(phone)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", nil
+	}
+
+	return s.issue(store, user, phone, SMSCodeLogin)
+}
+
+// Login checks the code and returns the user it was sent to. A wrong code is
+// counted before ErrInvalidSMSCode is returned, so callers must commit in that
+// case too.
+func (s *SMSCodeService) Login(store stores.Store, phone, code string) (*domain.User, error) {
+	smsCode, err := s.verify(store, phone, SMSCodeLogin, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// The phone may have moved to another account since the code was sent.
+	user, err := store.Users().GetByID(smsCode.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Phone == nil || *user.Phone != phone {
+		return nil, ErrInvalidSMSCode
+	}
+
+	return user, nil
+}
+
+// Send delivers code to phone. It is called once the code is committed.
+func (s *SMSCodeService) Send(ctx context.Context, phone, code string) error {
+	return s.sender.Send(ctx, phone, fmt.Sprintf("Your verification code is %s", code))
+}
+
+func (s *SMSCodeService) issue(store stores.Store, user *domain.User, phone, purpose string) (string, error) {
+	code, err := generateSMSCode()
+	if err != nil {
+		return "", err
+	}
+	codeHash, err := s.hasher.Hash(code)
+	if err != nil {
+		return "", err
+	}
+
+	if err := store.SMSCodes().DeleteByPhone(phone, purpose); err != nil {
+		return "", err
+	}
+
+	err = store.SMSCodes().Save(&domain.SMSCode{
+		Phone:     phone,
+		Purpose:   purpose,
+		UserID:    user.ID,
+		CodeHash:  codeHash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (s *SMSCodeService) verify(store stores.Store, phone, purpose, code string) (*domain.SMSCode, error) {
+	smsCode, err := store.SMSCodes().GetLatest(phone, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if smsCode == nil || smsCode.UsedAt != nil || smsCode.ExpiresAt.Before(time.Now()) ||
+		smsCode.Attempts >= s.maxAttempts {
+		return nil, ErrInvalidSMSCode
+	}
+
+	if !s.hasher.Verify(code, smsCode.CodeHash) {
+		smsCode.Attempts++
+		if err := store.SMSCodes().Save(smsCode); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidSMSCode
+	}
+
+	usedAt := time.Now()
+	smsCode.UsedAt = &usedAt
+	if err := store.SMSCodes().Save(smsCode); err != nil {
+		return nil, err
+	}
+
+	return smsCode, nil
+}
+
+func generateSMSCode() (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < smsCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate sms code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", smsCodeDigits, n.Int64()), nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newSMSCodeStore returns a store that keeps the last saved SMS code.
+func newSMSCodeStore(users ...*domain.User) (*mocks.StoreMock, *mocks.UserRepositoryMock, **domain.SMSCode) {
+	var saved *domain.SMSCode
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockCodeRepo := new(mocks.SMSCodeRepositoryMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("SMSCodes").Return(mockCodeRepo)
+	for _, user := range users {
+		mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+	}
+	mockCodeRepo.On("DeleteByPhone", mock.Anything, mock.Anything).Return(func(string, string) error {
+		saved = nil
+		return nil
+	})
+	mockCodeRepo.On("Save", mock.AnythingOfType("*domain.SMSCode")).Return(func(code *domain.SMSCode) error {
+		saved = code
+		return nil
+	})
+	mockCodeRepo.On("GetLatest", mock.Anything, mock.Anything).Return(func(phone, purpose string) (*domain.SMSCode, error) {
+		if saved == nil || saved.Phone != phone || saved.Purpose != purpose {
+			return nil, nil
+		}
+		return saved, nil
+	})
+
+	return mockStore, mockUserRepo, &saved
+}
+
+func TestSMSCodeService_Login(t *testing.T) {
+	phone := "+998901234567"
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com", Phone: &phone}
+	mockStore, mockUserRepo, saved := newSMSCodeStore(user)
+	mockUserRepo.On("GetByPhone", phone).Return(user, nil)
+	mockUserRepo.On("GetByPhone", "+998907654321").Return(nil, nil)
+
+	mockSender := new(mocks.SMSSenderMock)
+	smsSvc := NewSMSCodeService(utils.NewBcryptHasher(), mockSender, 5*time.Minute, 3)
+
+	code, err := smsSvc.RequestLogin(mockStore, "+998907654321")
+	assert.NoError(t, err)
+	assert.Empty(t, code)
+
+	code, err = smsSvc.RequestLogin(mockStore, phone)
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^\d{6}$`), code)
+	assert.NotEqual(t, code, (*saved).CodeHash)
+	assert.Equal(t, SMSCodeLogin, (*saved).Purpose)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), (*saved).ExpiresAt, time.Minute)
+
+	mockSender.On("Send", mock.Anything, phone, "Your verification code is "+code).Return(nil).Once()
+	require.NoError(t, smsSvc.Send(context.Background(), phone, code))
+	mockSender.AssertExpectations(t)
+
+	t.Run("Wrong code is counted", func(t *testing.T) {
+		_, err := smsSvc.Login(mockStore, phone, wrongCode(code))
+		assert.ErrorIs(t, err, ErrInvalidSMSCode)
+		assert.Equal(t, 1, (*saved).Attempts)
+	})
+
+	t.Run("Other purpose", func(t *testing.T) {
+		err := smsSvc.ConfirmPhone(mockStore, user, phone, code)
+		assert.ErrorIs(t, err, ErrInvalidSMSCode)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		loggedIn, err := smsSvc.Login(mockStore, phone, code)
+		require.NoError(t, err)
+		assert.Equal(t, user, loggedIn)
+		assert.NotNil(t, (*saved).UsedAt)
+	})
+
+	t.Run("Used twice", func(t *testing.T) {
+		_, err := smsSvc.Login(mockStore, phone, code)
+		assert.ErrorIs(t, err, ErrInvalidSMSCode)
+	})
+
+	t.Run("Too many attempts", func(t *testing.T) {
+		code, err := smsSvc.RequestLogin(mockStore, phone)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err := smsSvc.Login(mockStore, phone, wrongCode(code))
+			assert.ErrorIs(t, err, ErrInvalidSMSCode)
+		}
+
+		_, err = smsSvc.Login(mockStore, phone, code)
+		assert.ErrorIs(t, err, ErrInvalidSMSCode)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		code, err := smsSvc.RequestLogin(mockStore, phone)
+		require.NoError(t, err)
+		(*saved).ExpiresAt = time.Now().Add(-time.Second)
+
+		_, err = smsSvc.Login(mockStore, phone, code)
+		assert.ErrorIs(t, err, ErrInvalidSMSCode)
+	})
+}
+
+func TestSMSCodeService_ConfirmPhone(t *testing.T) {
+	phone := "+998901234567"
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com"}
+	other := &domain.User{ID: uuid.New(), Email: "jane@example.com", Phone: &phone}
+	mockStore, mockUserRepo, _ := newSMSCodeStore(user)
+	mockUserRepo.On("Save", user).Return(nil)
+
+	smsSvc := NewSMSCodeService(utils.NewBcryptHasher(), new(mocks.SMSSenderMock), 5*time.Minute, 3)
+
+	t.Run("Phone of another user", func(t *testing.T) {
+		mockUserRepo.On("GetByPhone", phone).Return(other, nil).Once()
+
+		_, err := smsSvc.RequestPhoneVerification(mockStore, user, phone)
+		assert.ErrorIs(t, err, ErrPhoneTaken)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		mockUserRepo.On("GetByPhone", phone).Return(nil, nil).Twice()
+
+		code, err := smsSvc.RequestPhoneVerification(mockStore, user, phone)
+		require.NoError(t, err)
+		assert.Nil(t, user.Phone)
+
+		require.NoError(t, smsSvc.ConfirmPhone(mockStore, user, phone, code))
+		require.NotNil(t, user.Phone)
+		assert.Equal(t, phone, *user.Phone)
+		assert.NotNil(t, user.PhoneVerifiedAt)
+	})
+}
+
+// wrongCode returns another code of the same length.
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
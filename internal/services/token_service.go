@@ -1,41 +1,74 @@
 package services
 
 import (
+	"app/internal/denylists"
 	"app/internal/domain"
 	"app/internal/stores"
 	"app/internal/utils"
+	"context"
 	"github.com/google/uuid"
+	"slices"
+	"strings"
 	"time"
 )
 
 var (
-	UserLoggedIn = "UserLoggedIn"
+	UserLoggedIn              = "UserLoggedIn"
+	RefreshTokenReuseDetected = "RefreshTokenReuseDetected"
+	UserLoggedOut             = "UserLoggedOut"
 )
 
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// ClientInfo describes the device a session was started or refreshed from and,
+// for sessions started through the token endpoint, the OAuth client and the
+// scope the user granted it. The client and scope of a session are set when
+// it starts and kept on every rotation.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+	ClientID  string
+	Scope     string
+}
+
 type TokenService struct {
-	hasher         utils.PasswordHasher
 	tokenGenerator utils.TokenGenerator
 	jwt            utils.JWTHelper
+	denylist       denylists.Denylist
 }
 
-func NewTokenService(hasher utils.PasswordHasher, tokenGenerator utils.TokenGenerator, jwt utils.JWTHelper) *TokenService {
+func NewTokenService(
+	tokenGenerator utils.TokenGenerator,
+	jwt utils.JWTHelper,
+	denylist denylists.Denylist,
+) *TokenService {
 	return &TokenService{
-		hasher:         hasher,
 		tokenGenerator: tokenGenerator,
 		jwt:            jwt,
+		denylist:       denylist,
 	}
 }
 
+// IssueTokenForUser starts a new session for the user. Sessions on other
+// devices are left untouched.
 func (s *TokenService) IssueTokenForUser(
-	store *stores.UserTokenOutboxStore,
+	store stores.Store,
 	user *domain.User,
+	client ClientInfo,
 ) (string, string, error) {
-	accessToken, refreshToken, err := s.generateTokens(user)
+	accessToken, refreshToken, err := s.generateTokens(store, user, client.ClientID, client.Scope)
 	if err != nil {
 		return "", "", err
 	}
 
-	err = s.saveRefreshToken(store, user.ID, refreshToken)
+	token := &domain.Token{
+		UserID:    user.ID,
+		SessionID: uuid.New(),
+		ClientID:  client.ClientID,
+		Scope:     client.Scope,
+	}
+
+	err = s.saveRefreshToken(store, token, refreshToken, client)
 	if err != nil {
 		return "", "", err
 	}
@@ -43,62 +76,255 @@ func (s *TokenService) IssueTokenForUser(
 	return accessToken, refreshToken, nil
 }
 
+// RotateRefreshToken issues a new token pair for an existing session. The
+// presented token is marked as rotated and a child token is added to the same
+// family.
+func (s *TokenService) RotateRefreshToken(
+	store stores.Store,
+	user *domain.User,
+	token *domain.Token,
+	client ClientInfo,
+) (string, string, error) {
+	accessToken, refreshToken, err := s.generateTokens(store, user, token.ClientID, token.Scope)
+	if err != nil {
+		return "", "", err
+	}
+
+	rotatedAt := time.Now()
+	token.RotatedAt = &rotatedAt
+	if err := store.Tokens().Save(token); err != nil {
+		return "", "", err
+	}
+
+	next := &domain.Token{
+		UserID:    token.UserID,
+		SessionID: token.SessionID,
+		ParentID:  &token.ID,
+		ClientID:  token.ClientID,
+		Scope:     token.Scope,
+	}
+
+	err = s.saveRefreshToken(store, next, refreshToken, client)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// VerifyRefreshToken returns the token the refresh token belongs to. Presenting
+// a token that was already rotated means it leaked, even after its session
+// ended: the whole family is revoked and ErrRefreshTokenReused is returned
+// together with the reused token.
 func (s *TokenService) VerifyRefreshToken(
-	store *stores.UserTokenOutboxStore,
-	userID uuid.UUID,
+	store stores.Store,
 	refreshToken string,
-) (bool, error) {
-	token, err := store.Tokens().GetByUserID(userID)
+) (*domain.Token, error) {
+	token, err := store.Tokens().GetByHash(utils.HashToken(refreshToken))
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	if token == nil || !s.hasher.Verify(refreshToken, token.TokenHash) || token.ExpiresAt.Before(time.Now()) {
-		return false, ErrInvalidCredentials
+	if token == nil {
+		return nil, ErrInvalidCredentials
 	}
 
-	return true, nil
+	if token.RotatedAt != nil {
+		if err := store.Tokens().RevokeSession(token.SessionID); err != nil {
+			return nil, err
+		}
+		return token, ErrRefreshTokenReused
+	}
+
+	if token.RevokedAt != nil || token.ExpiresAt.Before(time.Now()) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return token, nil
+}
+
+// FindRefreshToken returns the token a refresh token belongs to, whatever its
+// state, or nil if it is unknown.
+func (s *TokenService) FindRefreshToken(
+	store stores.Store,
+	refreshToken string,
+) (*domain.Token, error) {
+	return store.Tokens().GetByHash(utils.HashToken(refreshToken))
 }
 
-func (s *TokenService) generateTokens(user *domain.User) (string, string, error) {
-	roles := make([]string, len(user.Roles))
-	for i, r := range user.Roles {
-		roles[i] = r.Role
+// ParseAccessToken verifies an access token and returns its claims. Revoked
+// tokens fail with ErrAccessTokenRevoked.
+func (s *TokenService) ParseAccessToken(ctx context.Context, accessToken string) (*utils.Claims, error) {
+	claims, err := s.jwt.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, err
 	}
 
-	accessToken, err := s.jwt.GenerateAccessToken(user.ID.String(), roles)
+	revoked, err := denylists.Revoked(ctx, s.denylist, claims)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	if revoked {
+		return claims, ErrAccessTokenRevoked
 	}
 
-	refreshToken, err := s.tokenGenerator.GenerateSecureToken(32)
+	return claims, nil
+}
+
+// RevokeAccessToken puts the jti of the token on the denylist until the token
+// expires.
+func (s *TokenService) RevokeAccessToken(ctx context.Context, claims *utils.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	return s.denylist.Add(ctx, claims.ID, time.Until(claims.ExpiresAt.Time))
+}
+
+// RevokeAllAccessTokens denylists every access token issued to the user so
+// far. Token issue times are whole seconds, so the tokens of this second stay
+// valid, including those of a login that comes right after.
+func (s *TokenService) RevokeAllAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	return s.denylist.AddSubject(ctx, userID.String(), time.Now().Truncate(time.Second), s.jwt.AccessTokenTTL())
+}
+
+// RevokeRefreshToken ends the session the refresh token belongs to.
+func (s *TokenService) RevokeRefreshToken(
+	store stores.Store,
+	refreshToken string,
+) (*domain.Token, error) {
+	token, err := store.Tokens().GetByHash(utils.HashToken(refreshToken))
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	return accessToken, refreshToken, nil
+	if token == nil || token.RevokedAt != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := store.Tokens().RevokeSession(token.SessionID); err != nil {
+		return nil, err
+	}
+
+	return token, nil
 }
 
-func (s *TokenService) saveRefreshToken(
-	store *stores.UserTokenOutboxStore,
+// RevokeAllForUser ends every session of the user. The tokens are revoked
+// rather than deleted, so presenting a rotated one later is still reported as
+// reuse.
+func (s *TokenService) RevokeAllForUser(
+	store stores.Store,
 	userID uuid.UUID,
-	refreshToken string,
 ) error {
-	token, err := store.Tokens().GetByUserID(userID)
+	return store.Tokens().RevokeByUser(userID)
+}
+
+// RevokeOtherSessions ends every session of the user except the one the
+// refresh token belongs to. Without a refresh token of the user every session
+// is ended.
+func (s *TokenService) RevokeOtherSessions(
+	store stores.Store,
+	userID uuid.UUID,
+	currentRefreshToken string,
+) error {
+	var currentSessionID *uuid.UUID
+	if currentRefreshToken != "" {
+		current, err := store.Tokens().GetByHash(utils.HashToken(currentRefreshToken))
+		if err != nil {
+			return err
+		}
+		if current != nil && current.UserID == userID {
+			currentSessionID = &current.SessionID
+		}
+	}
+
+	sessions, err := store.Tokens().ListByUserID(userID)
 	if err != nil {
 		return err
 	}
 
-	if token == nil {
-		token = &domain.Token{
-			UserID:    userID,
-			TokenHash: s.hasher.Hash(refreshToken),
-			ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+	for _, session := range sessions {
+		if currentSessionID != nil && session.SessionID == *currentSessionID {
+			continue
+		}
+		if err := store.Tokens().RevokeSession(session.SessionID); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// IssueTokenForClient issues an access token for a client acting on its own
+// behalf. No refresh token is issued, the client can simply ask again.
+func (s *TokenService) IssueTokenForClient(
+	client *domain.Client,
+	scope string,
+) (string, error) {
+	return s.jwt.GenerateClientAccessToken(client.ClientID, scope)
+}
+
+// generateTokens issues an access token carrying the roles of the user and
+// the permissions they grant, with a new refresh token. A token issued to an
+// OAuth client only carries the permissions its scope names.
+func (s *TokenService) generateTokens(
+	store stores.Store,
+	user *domain.User,
+	clientID string,
+	scope string,
+) (string, string, error) {
+	roles := user.RoleNames()
+	permissions, err := store.Roles().PermissionsOf(roles)
+	if err != nil {
+		return "", "", err
+	}
+
+	var accessToken string
+	if clientID == "" {
+		accessToken, err = s.jwt.GenerateAccessToken(user.ID.String(), roles, permissions)
 	} else {
-		token.TokenHash = s.hasher.Hash(refreshToken)
-		token.ExpiresAt = time.Now().Add(7 * 24 * time.Hour)
+		accessToken, err = s.jwt.GenerateDelegatedAccessToken(
+			user.ID.String(), roles, scopedPermissions(permissions, scope), clientID, scope,
+		)
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := s.tokenGenerator.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// scopedPermissions keeps the permissions named in scope.
+func scopedPermissions(permissions []string, scope string) []string {
+	granted := strings.Fields(scope)
+
+	var scoped []string
+	for _, permission := range permissions {
+		if slices.Contains(granted, permission) {
+			scoped = append(scoped, permission)
+		}
 	}
+	return scoped
+}
+
+func (s *TokenService) saveRefreshToken(
+	store stores.Store,
+	token *domain.Token,
+	refreshToken string,
+	client ClientInfo,
+) error {
+	now := time.Now()
+
+	token.TokenHash = utils.HashToken(refreshToken)
+	token.UserAgent = client.UserAgent
+	token.IP = client.IP
+	token.LastUsedAt = now
+	token.ExpiresAt = now.Add(refreshTokenTTL)
 
 	return store.Tokens().Save(token)
 }
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"app/internal/denylists"
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// withPermissions makes the roles of any user grant permissions.
+func withPermissions(store *mocks.StoreMock, permissions ...string) {
+	mockRoleRepo := new(mocks.RoleRepositoryMock)
+	mockRoleRepo.On("PermissionsOf", mock.Anything).Return(permissions, nil)
+	store.On("Roles").Return(mockRoleRepo)
+}
+
+func TestTokenService_IssueTokenForUser_StartsNewSession(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	user := &domain.User{
+		ID:    uuid.New(),
+		Roles: []domain.UserRole{{Role: domain.RoleCustomer}},
+	}
+
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	withPermissions(mockStore, "orders:create")
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), []string{domain.RoleCustomer}, []string{"orders:create"}).Return("jwt_token", nil)
+
+	var saved *domain.Token
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).(*domain.Token)
+	}).Return(nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	accessToken, refreshToken, err := tokenSvc.IssueTokenForUser(mockStore, user, ClientInfo{
+		UserAgent: "Mozilla/5.0",
+		IP:        "10.0.0.1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt_token", accessToken)
+	assert.NotEmpty(t, refreshToken)
+
+	assert.Equal(t, user.ID, saved.UserID)
+	assert.NotEqual(t, uuid.Nil, saved.SessionID)
+	assert.Equal(t, utils.HashToken(refreshToken), saved.TokenHash)
+	assert.Equal(t, "Mozilla/5.0", saved.UserAgent)
+	assert.Equal(t, "10.0.0.1", saved.IP)
+	assert.True(t, saved.ExpiresAt.After(time.Now()))
+}
+
+func TestTokenService_VerifyRefreshToken_Success(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+	token := &domain.Token{
+		ID:        1,
+		UserID:    uuid.New(),
+		SessionID: uuid.New(),
+		TokenHash: utils.HashToken("valid_refresh_token"),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockTokenRepo.On("GetByHash", token.TokenHash).Return(token, nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), nil, nil)
+	found, err := tokenSvc.VerifyRefreshToken(mockStore, "valid_refresh_token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, token, found)
+}
+
+func TestTokenService_VerifyRefreshToken_NoMatchingToken(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockTokenRepo.On("GetByHash", utils.HashToken("badtoken")).Return(nil, nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), nil, nil)
+	_, err := tokenSvc.VerifyRefreshToken(mockStore, "badtoken")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestTokenService_VerifyRefreshToken_Expired(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+
+	token := &domain.Token{
+		TokenHash: utils.HashToken("expired_token"),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockTokenRepo.On("GetByHash", token.TokenHash).Return(token, nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), nil, nil)
+	_, err := tokenSvc.VerifyRefreshToken(mockStore, "expired_token")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+// newTokenRepoStore wires a TokenRepositoryMock that keeps saved tokens by hash,
+// so a rotation chain can be replayed across several calls.
+func newTokenRepoStore() (*mocks.StoreMock, *mocks.TokenRepositoryMock, map[string]*domain.Token) {
+	mockStore := new(mocks.StoreMock)
+	mockTokenRepo := new(mocks.TokenRepositoryMock)
+	saved := make(map[string]*domain.Token)
+	nextID := uint(1)
+
+	mockStore.On("Tokens").Return(mockTokenRepo)
+	mockTokenRepo.On("Save", mock.AnythingOfType("*domain.Token")).Run(func(args mock.Arguments) {
+		token := args.Get(0).(*domain.Token)
+		if token.ID == 0 {
+			token.ID = nextID
+			nextID++
+		}
+		saved[token.TokenHash] = token
+	}).Return(nil)
+	mockTokenRepo.On("GetByHash", mock.Anything).Return(func(hash string) (*domain.Token, error) {
+		return saved[hash], nil
+	})
+	mockTokenRepo.On("RevokeSession", mock.Anything).Return(func(sessionID uuid.UUID) error {
+		now := time.Now()
+		for _, token := range saved {
+			if token.SessionID == sessionID {
+				token.RevokedAt = &now
+			}
+		}
+		return nil
+	})
+	mockTokenRepo.On("RevokeByUser", mock.Anything).Return(func(userID uuid.UUID) error {
+		now := time.Now()
+		for _, token := range saved {
+			if token.UserID == userID {
+				token.RevokedAt = &now
+			}
+		}
+		return nil
+	})
+
+	return mockStore, mockTokenRepo, saved
+}
+
+func TestTokenService_RotateRefreshToken_Chain(t *testing.T) {
+	mockStore, mockTokenRepo, _ := newTokenRepoStore()
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	user := &domain.User{ID: uuid.New()}
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), []string{}, []string(nil)).Return("jwt_token", nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	_, refreshToken, err := tokenSvc.IssueTokenForUser(mockStore, user, ClientInfo{})
+	assert.NoError(t, err)
+
+	first, err := tokenSvc.VerifyRefreshToken(mockStore, refreshToken)
+	assert.NoError(t, err)
+
+	previous := first
+	for i := 0; i < 3; i++ {
+		_, refreshToken, err = tokenSvc.RotateRefreshToken(mockStore, user, previous, ClientInfo{})
+		assert.NoError(t, err)
+		assert.NotNil(t, previous.RotatedAt)
+
+		current, err := tokenSvc.VerifyRefreshToken(mockStore, refreshToken)
+		assert.NoError(t, err)
+		assert.Equal(t, first.SessionID, current.SessionID)
+		assert.Equal(t, previous.ID, *current.ParentID)
+		assert.Nil(t, current.RotatedAt)
+
+		previous = current
+	}
+
+	mockTokenRepo.AssertNotCalled(t, "RevokeSession", mock.Anything)
+}
+
+func TestTokenService_RotateRefreshToken_KeepsClient(t *testing.T) {
+	mockStore, _, saved := newTokenRepoStore()
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	user := &domain.User{ID: uuid.New()}
+	withPermissions(mockStore, "orders:read", "users:unlock")
+	// Rotated tokens keep the client, the scope and the permissions it names.
+	mockJwtHelper.On(
+		"GenerateDelegatedAccessToken", user.ID.String(), []string{}, []string{"orders:read"}, "orders", "openid orders:read",
+	).Return("jwt_token", nil).Twice()
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	_, refreshToken, err := tokenSvc.IssueTokenForUser(mockStore, user, ClientInfo{ClientID: "orders", Scope: "openid orders:read"})
+	assert.NoError(t, err)
+	token, err := tokenSvc.VerifyRefreshToken(mockStore, refreshToken)
+	assert.NoError(t, err)
+
+	_, refreshToken, err = tokenSvc.RotateRefreshToken(mockStore, user, token, ClientInfo{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", saved[utils.HashToken(refreshToken)].ClientID)
+	assert.Equal(t, "openid orders:read", saved[utils.HashToken(refreshToken)].Scope)
+	mockJwtHelper.AssertExpectations(t)
+}
+
+func TestTokenService_VerifyRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	mockStore, mockTokenRepo, _ := newTokenRepoStore()
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	user := &domain.User{ID: uuid.New()}
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), []string{}, []string(nil)).Return("jwt_token", nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	_, stolenToken, err := tokenSvc.IssueTokenForUser(mockStore, user, ClientInfo{})
+	assert.NoError(t, err)
+
+	// The legitimate client rotates first.
+	original, err := tokenSvc.VerifyRefreshToken(mockStore, stolenToken)
+	assert.NoError(t, err)
+	_, legitimateToken, err := tokenSvc.RotateRefreshToken(mockStore, user, original, ClientInfo{})
+	assert.NoError(t, err)
+
+	// The attacker replays the already rotated token.
+	reused, err := tokenSvc.VerifyRefreshToken(mockStore, stolenToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Equal(t, original.ID, reused.ID)
+	mockTokenRepo.AssertCalled(t, "RevokeSession", original.SessionID)
+
+	// The whole family is gone, including the legitimate client's token.
+	_, err = tokenSvc.VerifyRefreshToken(mockStore, legitimateToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestTokenService_VerifyRefreshToken_ReuseAfterRevokeAll(t *testing.T) {
+	mockStore, _, saved := newTokenRepoStore()
+	mockJwtHelper := new(mocks.JWTHelperMock)
+
+	user := &domain.User{ID: uuid.New()}
+	withPermissions(mockStore)
+	mockJwtHelper.On("GenerateAccessToken", user.ID.String(), []string{}, []string(nil)).Return("jwt_token", nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	_, stolenToken, err := tokenSvc.IssueTokenForUser(mockStore, user, ClientInfo{})
+	assert.NoError(t, err)
+
+	original, err := tokenSvc.VerifyRefreshToken(mockStore, stolenToken)
+	assert.NoError(t, err)
+	_, currentToken, err := tokenSvc.RotateRefreshToken(mockStore, user, original, ClientInfo{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, tokenSvc.RevokeAllForUser(mockStore, user.ID))
+	// The rows are kept, only revoked.
+	assert.Len(t, saved, 2)
+
+	_, err = tokenSvc.VerifyRefreshToken(mockStore, currentToken)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = tokenSvc.VerifyRefreshToken(mockStore, stolenToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+}
+
+func TestTokenService_RevokeAllAccessTokens(t *testing.T) {
+	mockJwtHelper := new(mocks.JWTHelperMock)
+	userID := uuid.New()
+	now := time.Now()
+
+	issuedAt := func(at time.Time) *utils.Claims {
+		return &utils.Claims{
+			UserID: userID.String(),
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID:       uuid.NewString(),
+				Subject:  userID.String(),
+				IssuedAt: jwt.NewNumericDate(at),
+			},
+		}
+	}
+	mockJwtHelper.On("AccessTokenTTL").Return(15 * time.Minute)
+	mockJwtHelper.On("ParseAccessToken", "earlier").Return(issuedAt(now.Add(-time.Minute)), nil)
+	mockJwtHelper.On("ParseAccessToken", "later").Return(issuedAt(now), nil)
+
+	tokenSvc := NewTokenService(utils.NewTokenGenerator(), mockJwtHelper, denylists.NewMemoryDenylist())
+	assert.NoError(t, tokenSvc.RevokeAllAccessTokens(t.Context(), userID))
+
+	_, err := tokenSvc.ParseAccessToken(t.Context(), "earlier")
+	assert.ErrorIs(t, err, ErrAccessTokenRevoked)
+
+	// Tokens issued from the revoking second on stay valid.
+	_, err = tokenSvc.ParseAccessToken(t.Context(), "later")
+	assert.NoError(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"app/internal/domain"
 	"app/internal/stores"
 	"app/internal/utils"
+	"app/internal/validators"
 	"github.com/google/uuid"
 	"strings"
 )
@@ -13,15 +14,23 @@ var (
 )
 
 type UserService struct {
-	hasher utils.PasswordHasher
+	hasher               utils.PasswordHasher
+	passwordPolicy       *validators.PasswordPolicy
+	requireVerifiedEmail bool
 }
 
-func NewUserService(hasher utils.PasswordHasher) *UserService {
-	return &UserService{hasher: hasher}
+// NewUserService creates a UserService. With requireVerifiedEmail set,
+// Authenticate rejects users who have not verified their email yet.
+func NewUserService(
+	hasher utils.PasswordHasher,
+	passwordPolicy *validators.PasswordPolicy,
+	requireVerifiedEmail bool,
+) *UserService {
+	return &UserService{hasher: hasher, passwordPolicy: passwordPolicy, requireVerifiedEmail: requireVerifiedEmail}
 }
 
 func (s *UserService) Register(
-	store *stores.UserTokenOutboxStore,
+	store stores.Store,
 	name string,
 	surname string,
 	email string,
@@ -35,9 +44,14 @@ func (s *UserService) Register(
 		return nil, ErrUserAlreadyExists
 	}
 
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return nil, err
+	}
+
 	user = &domain.User{
 		Email:    email,
-		Password: s.hasher.Hash(password),
+		Password: hash,
 		Name:     name,
 		Surname:  surname,
 		Roles: []domain.UserRole{
@@ -53,7 +67,7 @@ func (s *UserService) Register(
 }
 
 func (s *UserService) PromoteToSeller(
-	store *stores.UserTokenOutboxStore,
+	store stores.Store,
 	userId string,
 ) (*domain.User, error) {
 	userUUID, err := uuid.Parse(userId)
@@ -84,8 +98,44 @@ func (s *UserService) PromoteToSeller(
 	return user, nil
 }
 
+// ChangePassword sets a new password after checking the current one. The
+// request is validated against the rest of the password policy.
+func (s *UserService) ChangePassword(
+	store stores.Store,
+	userId string,
+	currentPassword string,
+	newPassword string,
+) (*domain.User, error) {
+	userUUID, err := uuid.Parse(userId)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := store.Users().GetByID(userUUID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !s.hasher.Verify(currentPassword, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.passwordPolicy.ContainsPersonalInfo(newPassword, user.Email, user.Name, user.Surname) {
+		return nil, ErrPasswordContainsPersonalInfo
+	}
+
+	user.Password, err = s.hasher.Hash(newPassword)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Users().Save(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 func (s *UserService) GetByID(
-	store *stores.UserTokenOutboxStore,
+	store stores.Store,
 	userId string,
 ) (*domain.User, error) {
 	userUUID, err := uuid.Parse(userId)
@@ -106,7 +156,7 @@ func (s *UserService) GetByID(
 }
 
 func (s *UserService) Authenticate(
-	store *stores.UserTokenOutboxStore,
+	store stores.Store,
 	email string,
 	password string,
 ) (*domain.User, error) {
@@ -119,5 +169,22 @@ func (s *UserService) Authenticate(
 		return nil, ErrInvalidCredentials
 	}
 
+	if s.requireVerifiedEmail && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// The password is at hand only now, so this is where an outdated hash can
+	// be upgraded. Callers run Authenticate in a transaction.
+	if s.hasher.NeedsRehash(user.Password) {
+		hash, err := s.hasher.Hash(password)
+		if err != nil {
+			return nil, err
+		}
+		user.Password = hash
+		if err := store.Users().Save(user); err != nil {
+			return nil, err
+		}
+	}
+
 	return user, nil
 }
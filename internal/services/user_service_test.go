@@ -0,0 +1,158 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserService_Register_Success(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	mockHasher.On("Hash", "password123").Return("hashed_password", nil)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(nil, nil)
+	mockUserRepo.On("Save", mock.Anything).Return(func(u *domain.User) error {
+		u.ID = uuid.New()
+		return nil
+	})
+
+	userSvc := NewUserService(mockHasher, nil, false)
+	user, err := userSvc.Register(mockStore, "John", "Doe", "john@example.com", "password123")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, "john@example.com", user.Email)
+	assert.Equal(t, "hashed_password", user.Password)
+
+	roles := make([]string, len(user.Roles))
+	for i, r := range user.Roles {
+		roles[i] = r.Role
+	}
+	assert.Contains(t, roles, domain.RoleCustomer)
+}
+
+func TestUserService_Register_UserAlreadyExists(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	existingUser := &domain.User{ID: uuid.New()}
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(existingUser, nil)
+
+	userSvc := NewUserService(mockHasher, nil, false)
+	_, err := userSvc.Register(mockStore, "John", "Doe", "john@example.com", "password123")
+
+	assert.ErrorIs(t, err, ErrUserAlreadyExists)
+}
+
+func TestUserService_Authenticate_Success(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	existingUser := &domain.User{
+		ID:       uuid.New(),
+		Email:    "john@example.com",
+		Password: "hashed_password",
+		Roles:    []domain.UserRole{{Role: domain.RoleCustomer}},
+	}
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(existingUser, nil)
+	mockHasher.On("Verify", "password123", "hashed_password").Return(true)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+
+	userSvc := NewUserService(mockHasher, nil, false)
+	user, err := userSvc.Authenticate(mockStore, "john@example.com", "password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, existingUser, user)
+}
+
+func TestUserService_Authenticate_UpgradesOutdatedHash(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	existingUser := &domain.User{ID: uuid.New(), Email: "john@example.com", Password: "bcrypt_hash"}
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(existingUser, nil)
+	mockUserRepo.On("Save", existingUser).Return(nil)
+	mockHasher.On("Verify", "password123", "bcrypt_hash").Return(true)
+	mockHasher.On("NeedsRehash", "bcrypt_hash").Return(true)
+	mockHasher.On("Hash", "password123").Return("argon2id_hash", nil)
+
+	user, err := NewUserService(mockHasher, nil, false).Authenticate(mockStore, "john@example.com", "password123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "argon2id_hash", user.Password)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUserService_Authenticate_InvalidPassword(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	existingUser := &domain.User{ID: uuid.New(), Password: "hashed_password"}
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(existingUser, nil)
+	mockHasher.On("Verify", "wrong_password", "hashed_password").Return(false)
+
+	userSvc := NewUserService(mockHasher, nil, false)
+	_, err := userSvc.Authenticate(mockStore, "john@example.com", "wrong_password")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestUserService_Authenticate_UserNotFound(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	mockStore.On("Users").Return(mockUserRepo)
+
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(nil, nil)
+
+	userSvc := NewUserService(mockHasher, nil, false)
+	_, err := userSvc.Authenticate(mockStore, "john@example.com", "password123")
+
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestUserService_Authenticate_RequireVerifiedEmail(t *testing.T) {
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockHasher := new(mocks.PasswordHasherMock)
+
+	user := &domain.User{Email: "john@example.com", Password: "hashed_password"}
+
+	mockStore.On("Users").Return(mockUserRepo)
+	mockUserRepo.On("GetByEmail", "john@example.com").Return(user, nil)
+	mockHasher.On("Verify", "password123", "hashed_password").Return(true)
+	mockHasher.On("NeedsRehash", "hashed_password").Return(false)
+
+	_, err := NewUserService(mockHasher, nil, true).Authenticate(mockStore, "john@example.com", "password123")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	_, err = NewUserService(mockHasher, nil, false).Authenticate(mockStore, "john@example.com", "password123")
+	assert.NoError(t, err)
+
+	verifiedAt := time.Now()
+	user.EmailVerifiedAt = &verifiedAt
+	_, err = NewUserService(mockHasher, nil, true).Authenticate(mockStore, "john@example.com", "password123")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/stores"
+	"app/internal/utils"
+	"app/internal/webauthn"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var WebAuthnCredentialRegistered = "WebAuthnCredentialRegistered"
+
+const (
+	webAuthnRegistrationPurpose = "webauthn_register"
+	webAuthnLoginPurpose        = "webauthn_login"
+)
+
+// WebAuthnService runs the registration and login ceremonies of passkeys.
+// The challenge of a ceremony travels in a signed session token and is also
+// recorded server side; finishing the ceremony consumes the record, so a
+// captured session and response cannot be replayed.
+type WebAuthnService struct {
+	rp     *webauthn.RelyingParty
+	signer utils.TokenSigner
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewWebAuthnService(rp *webauthn.RelyingParty, signer utils.TokenSigner, ttl time.Duration) *WebAuthnService {
+	return &WebAuthnService{rp: rp, signer: signer, ttl: ttl, now: time.Now}
+}
+
+// BeginRegistration returns the options for a new credential of the user and
+// the session token the finishing request must carry.
+func (s *WebAuthnService) BeginRegistration(
+	store stores.Store,
+	user *domain.User,
+) (*webauthn.CreationOptions, string, error) {
+	credentials, err := store.WebAuthnCredentials().ListByUser(user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	challenge, session, err := s.newSession(store, webAuthnRegistrationPurpose, user.ID.String())
+	if err != nil {
+		return nil, "", err
+	}
+
+	displayName := strings.TrimSpace(user.Name + " " + user.Surname)
+	if displayName == "" {
+		displayName = user.Email
+	}
+
+	options := s.rp.CreationOptions(
+		webauthn.UserEntity{ID: user.ID[:], Name: user.Email, DisplayName: displayName},
+		challenge,
+		s.ttl,
+		credentialDescriptors(credentials),
+	)
+	return options, session, nil
+}
+
+// FinishRegistration verifies the new credential and stores it.
+func (s *WebAuthnService) FinishRegistration(
+	store stores.Store,
+	user *domain.User,
+	session string,
+	name string,
+	credential *webauthn.RegistrationCredential,
+) (*domain.WebAuthnCredential, error) {
+	challenge, err := s.verifySession(store, session, webAuthnRegistrationPurpose, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := s.rp.VerifyRegistration(
+		credential.Response.ClientDataJSON, credential.Response.AttestationObject, challenge,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebAuthnResponse, err)
+	}
+	if !bytes.Equal(registration.CredentialID, credential.RawID) {
+		return nil, fmt.Errorf("%w: credential id mismatch", ErrInvalidWebAuthnResponse)
+	}
+
+	existing, err := store.WebAuthnCredentials().GetByCredentialID(registration.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrWebAuthnCredentialExists
+	}
+
+	stored := &domain.WebAuthnCredential{
+		UserID:          user.ID,
+		CredentialID:    registration.CredentialID,
+		PublicKey:       registration.PublicKey,
+		SignCount:       registration.SignCount,
+		Transports:      strings.Join(credential.Response.Transports, ","),
+		AAGUID:          registration.AAGUID,
+		AttestationType: registration.AttestationType,
+		Name:            name,
+	}
+	if err := store.WebAuthnCredentials().Save(stored); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// BeginLogin returns the options of a login ceremony. They name no account
+// and list no credentials: passkeys are registered as discoverable, so the
+// browser offers those it holds for the site, and the answer tells nothing
+// about which accounts exist.
+func (s *WebAuthnService) BeginLogin(store stores.Store) (*webauthn.RequestOptions, string, error) {
+	challenge, session, err := s.newSession(store, webAuthnLoginPurpose, "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.rp.RequestOptions(challenge, s.ttl, nil), session, nil
+}
+
+// FinishLogin verifies the assertion and returns the user the credential
+// belongs to.
+func (s *WebAuthnService) FinishLogin(
+	store stores.Store,
+	session string,
+	credential *webauthn.AssertionCredential,
+) (*domain.User, error) {
+	challenge, err := s.verifySession(store, session, webAuthnLoginPurpose, "")
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := store.WebAuthnCredentials().GetByCredentialID(credential.RawID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if len(credential.Response.UserHandle) > 0 && !bytes.Equal(credential.Response.UserHandle, stored.UserID[:]) {
+		return nil, ErrInvalidCredentials
+	}
+
+	signCount, err := s.rp.VerifyAssertion(
+		credential.Response.ClientDataJSON,
+		credential.Response.AuthenticatorData,
+		credential.Response.Signature,
+		challenge,
+		stored.PublicKey,
+		stored.SignCount,
+	)
+	if errors.Is(err, webauthn.ErrSignCount) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebAuthnResponse, err)
+	}
+
+	usedAt := s.now()
+	stored.SignCount = signCount
+	stored.LastUsedAt = &usedAt
+	if err := store.WebAuthnCredentials().Save(stored); err != nil {
+		return nil, err
+	}
+
+	user, err := store.Users().GetByID(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// newSession records a fresh challenge and signs the session token naming it.
+// Challenges of abandoned ceremonies are cleared on the way.
+func (s *WebAuthnService) newSession(store stores.Store, purpose, subject string) ([]byte, string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, "", fmt.Errorf("failed to generate webauthn challenge: %w", err)
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(challenge)
+
+	now := s.now()
+	if err := store.WebAuthnChallenges().DeleteExpired(now); err != nil {
+		return nil, "", err
+	}
+	err := store.WebAuthnChallenges().Save(&domain.WebAuthnChallenge{
+		ChallengeHash: utils.HashToken(nonce),
+		Purpose:       purpose,
+		ExpiresAt:     now.Add(s.ttl),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := s.signer.Sign(utils.SignedTokenClaims{
+		Purpose:   purpose,
+		Subject:   subject,
+		Nonce:     nonce,
+		ExpiresAt: now.Add(s.ttl).Unix(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return challenge, session, nil
+}
+
+// verifySession checks the session token and consumes its challenge, so the
+// session cannot finish a second ceremony. Callers run it in a transaction,
+// which also restores the challenge when the ceremony fails.
+func (s *WebAuthnService) verifySession(store stores.Store, session, purpose, subject string) ([]byte, error) {
+	claims, err := s.signer.Verify(session, purpose)
+	if errors.Is(err, utils.ErrInvalidToken) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject != subject {
+		return nil, ErrInvalidToken
+	}
+
+	challenge, err := base64.RawURLEncoding.DecodeString(claims.Nonce)
+	if err != nil || len(challenge) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	recorded, err := store.WebAuthnChallenges().GetByHash(utils.HashToken(claims.Nonce))
+	if err != nil {
+		return nil, err
+	}
+	if recorded == nil || recorded.Purpose != purpose || !s.now().Before(recorded.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	if err := store.WebAuthnChallenges().Delete(recorded.ID); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+func credentialDescriptors(credentials []domain.WebAuthnCredential) []webauthn.CredentialDescriptor {
+	descriptors := make([]webauthn.CredentialDescriptor, len(credentials))
+	for i, c := range credentials {
+		descriptors[i] = webauthn.CredentialDescriptor{
+			Type:       "public-key",
+			ID:         c.CredentialID,
+			Transports: c.TransportList(),
+		}
+	}
+	return descriptors
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"app/internal/domain"
+	"app/internal/mocks"
+	"app/internal/utils"
+	"app/internal/webauthn"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// webAuthnFixture is a ceremony recorded for the rp id "localhost", see the
+// testdata of the webauthn package.
+type webAuthnFixture struct {
+	Challenge  string          `json:"challenge"`
+	PublicKey  string          `json:"public_key"`
+	SignCount  uint32          `json:"sign_count"`
+	Credential json.RawMessage `json:"credential"`
+}
+
+func loadWebAuthnFixture(t *testing.T, name string, credential interface{}) webAuthnFixture {
+	data, err := os.ReadFile(filepath.Join("..", "webauthn", "testdata", name))
+	require.NoError(t, err)
+
+	var f webAuthnFixture
+	require.NoError(t, json.Unmarshal(data, &f))
+	require.NoError(t, json.Unmarshal(f.Credential, credential))
+	return f
+}
+
+func newTestWebAuthnService(signer utils.TokenSigner) *WebAuthnService {
+	rp := &webauthn.RelyingParty{
+		ID:                      "localhost",
+		Name:                    "Shop",
+		Origins:                 []string{"http://localhost:8080"},
+		RequireUserVerification: true,
+	}
+	return NewWebAuthnService(rp, signer, 5*time.Minute)
+}
+
+// withWebAuthnChallenges keeps the recorded challenges by hash, so a session
+// can be consumed only once.
+func withWebAuthnChallenges(store *mocks.StoreMock) map[string]*domain.WebAuthnChallenge {
+	challenges := make(map[string]*domain.WebAuthnChallenge)
+	nextID := uint(1)
+
+	mockChallengeRepo := new(mocks.WebAuthnChallengeRepositoryMock)
+	store.On("WebAuthnChallenges").Return(mockChallengeRepo)
+	mockChallengeRepo.On("DeleteExpired", mock.Anything).Return(nil)
+	mockChallengeRepo.On("Save", mock.AnythingOfType("*domain.WebAuthnChallenge")).Run(func(args mock.Arguments) {
+		challenge := args.Get(0).(*domain.WebAuthnChallenge)
+		challenge.ID = nextID
+		nextID++
+		challenges[challenge.ChallengeHash] = challenge
+	}).Return(nil)
+	mockChallengeRepo.On("GetByHash", mock.Anything).Return(func(hash string) (*domain.WebAuthnChallenge, error) {
+		return challenges[hash], nil
+	})
+	mockChallengeRepo.On("Delete", mock.Anything).Return(func(id uint) error {
+		for hash, challenge := range challenges {
+			if challenge.ID == id {
+				delete(challenges, hash)
+			}
+		}
+		return nil
+	})
+
+	return challenges
+}
+
+// signWebAuthnSession records the challenge and signs the session a ceremony
+// with it would have returned.
+func signWebAuthnSession(
+	t *testing.T,
+	signer utils.TokenSigner,
+	challenges map[string]*domain.WebAuthnChallenge,
+	purpose, subject, challenge string,
+) string {
+	challenges[utils.HashToken(challenge)] = &domain.WebAuthnChallenge{
+		ID:            uint(len(challenges) + 100),
+		ChallengeHash: utils.HashToken(challenge),
+		Purpose:       purpose,
+		ExpiresAt:     time.Now().Add(time.Minute),
+	}
+
+	session, err := signer.Sign(utils.SignedTokenClaims{
+		Purpose:   purpose,
+		Subject:   subject,
+		Nonce:     challenge,
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	return session
+}
+
+func TestWebAuthnService_Registration(t *testing.T) {
+	signer := utils.NewHMACTokenSigner([]byte("secret"))
+	svc := newTestWebAuthnService(signer)
+	user := &domain.User{ID: uuid.New(), Email: "john@example.com", Name: "John"}
+	existing := domain.WebAuthnCredential{UserID: user.ID, CredentialID: []byte{1, 2, 3}, Transports: "usb,nfc"}
+
+	var credential webauthn.RegistrationCredential
+	f := loadWebAuthnFixture(t, "registration_packed_self.json", &credential)
+
+	mockStore := new(mocks.StoreMock)
+	mockCredentialRepo := new(mocks.WebAuthnCredentialRepositoryMock)
+	mockStore.On("WebAuthnCredentials").Return(mockCredentialRepo)
+	challenges := withWebAuthnChallenges(mockStore)
+	mockCredentialRepo.On("ListByUser", user.ID).Return([]domain.WebAuthnCredential{existing}, nil)
+
+	t.Run("Options", func(t *testing.T) {
+		options, session, err := svc.BeginRegistration(mockStore, user)
+		require.NoError(t, err)
+
+		assert.Equal(t, "localhost", options.RP.ID)
+		assert.Equal(t, []byte(user.ID[:]), []byte(options.User.ID))
+		assert.Equal(t, "John", options.User.DisplayName)
+		assert.Len(t, options.Challenge, 32)
+		require.Len(t, options.ExcludeCredentials, 1)
+		assert.Equal(t, []byte{1, 2, 3}, []byte(options.ExcludeCredentials[0].ID))
+		assert.Equal(t, []string{"usb", "nfc"}, options.ExcludeCredentials[0].Transports)
+
+		claims, err := signer.Verify(session, webAuthnRegistrationPurpose)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID.String(), claims.Subject)
+		assert.Equal(t, base64.RawURLEncoding.EncodeToString(options.Challenge), claims.Nonce)
+	})
+
+	t.Run("Session of another user", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, uuid.NewString(), f.Challenge)
+
+		_, err := svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Other challenge", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, user.ID.String(), "b3RoZXI")
+
+		_, err := svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		assert.ErrorIs(t, err, ErrInvalidWebAuthnResponse)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, user.ID.String(), f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(nil, nil).Once()
+		mockCredentialRepo.On("Save", mock.Anything).Return(nil).Once()
+
+		stored, err := svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		require.NoError(t, err)
+
+		assert.Equal(t, user.ID, stored.UserID)
+		assert.Equal(t, []byte(credential.RawID), stored.CredentialID)
+		assert.NotEmpty(t, stored.PublicKey)
+		assert.Equal(t, webauthn.AttestationSelf, stored.AttestationType)
+		assert.Equal(t, "Laptop", stored.Name)
+	})
+
+	t.Run("Replayed session", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, user.ID.String(), f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(nil, nil).Once()
+		mockCredentialRepo.On("Save", mock.Anything).Return(nil).Once()
+
+		_, err := svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		require.NoError(t, err)
+
+		_, err = svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Already registered", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, user.ID.String(), f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).
+			Return(&domain.WebAuthnCredential{UserID: user.ID}, nil).Once()
+
+		_, err := svc.FinishRegistration(mockStore, user, session, "Laptop", &credential)
+		assert.ErrorIs(t, err, ErrWebAuthnCredentialExists)
+	})
+}
+
+func TestWebAuthnService_Login(t *testing.T) {
+	signer := utils.NewHMACTokenSigner([]byte("secret"))
+	svc := newTestWebAuthnService(signer)
+
+	var credential webauthn.AssertionCredential
+	f := loadWebAuthnFixture(t, "assertion_packed_self.json", &credential)
+	publicKey, err := base64.RawURLEncoding.DecodeString(f.PublicKey)
+	require.NoError(t, err)
+
+	// The recorded user handle is the id of this user.
+	user := &domain.User{ID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Email: "john@example.com"}
+	stored := func(signCount uint32) *domain.WebAuthnCredential {
+		return &domain.WebAuthnCredential{
+			UserID:       user.ID,
+			CredentialID: credential.RawID,
+			PublicKey:    publicKey,
+			SignCount:    signCount,
+			Transports:   "internal",
+		}
+	}
+
+	mockStore := new(mocks.StoreMock)
+	mockUserRepo := new(mocks.UserRepositoryMock)
+	mockCredentialRepo := new(mocks.WebAuthnCredentialRepositoryMock)
+	mockStore.On("Users").Return(mockUserRepo)
+	mockStore.On("WebAuthnCredentials").Return(mockCredentialRepo)
+	challenges := withWebAuthnChallenges(mockStore)
+	mockUserRepo.On("GetByID", user.ID).Return(user, nil)
+
+	t.Run("Options", func(t *testing.T) {
+		options, session, err := svc.BeginLogin(mockStore)
+		require.NoError(t, err)
+
+		// No account is named, so the options look the same for everyone.
+		assert.Empty(t, options.AllowCredentials)
+		assert.Len(t, options.Challenge, 32)
+		claims, err := signer.Verify(session, webAuthnLoginPurpose)
+		require.NoError(t, err)
+		assert.Empty(t, claims.Subject)
+		assert.Contains(t, challenges, utils.HashToken(claims.Nonce))
+	})
+
+	t.Run("Unknown credential", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnLoginPurpose, "", f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(nil, nil).Once()
+
+		_, err := svc.FinishLogin(mockStore, session, &credential)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Registration session", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnRegistrationPurpose, "", f.Challenge)
+
+		_, err := svc.FinishLogin(mockStore, session, &credential)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Replayed assertion", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnLoginPurpose, "", f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(stored(f.SignCount+1), nil).Once()
+
+		_, err := svc.FinishLogin(mockStore, session, &credential)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnLoginPurpose, "", f.Challenge)
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(stored(f.SignCount), nil).Once()
+		mockCredentialRepo.On("Save", mock.MatchedBy(func(c *domain.WebAuthnCredential) bool {
+			return c.SignCount > f.SignCount && c.LastUsedAt != nil
+		})).Return(nil).Once()
+
+		loggedIn, err := svc.FinishLogin(mockStore, session, &credential)
+		require.NoError(t, err)
+		assert.Equal(t, user, loggedIn)
+		mockCredentialRepo.AssertExpectations(t)
+	})
+
+	t.Run("Replayed session", func(t *testing.T) {
+		session := signWebAuthnSession(t, signer, challenges, webAuthnLoginPurpose, "", f.Challenge)
+		// The counter alone would let the replay through, as it does for
+		// authenticators that always report zero.
+		mockCredentialRepo.On("GetByCredentialID", []byte(credential.RawID)).Return(stored(f.SignCount), nil).Once()
+		mockCredentialRepo.On("Save", mock.Anything).Return(nil).Once()
+
+		_, err := svc.FinishLogin(mockStore, session, &credential)
+		require.NoError(t, err)
+
+		_, err = svc.FinishLogin(mockStore, session, &credential)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
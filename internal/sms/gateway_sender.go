@@ -0,0 +1,67 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// GatewaySender sends messages through an HTTP SMS gateway, as a JSON POST
+// request to a single URL. Any non-2xx response is treated as a failed
+// delivery.
+type GatewaySender struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+}
+
+type gatewayMessage struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// NewGatewaySender returns a sender for the gateway at url. token is sent as
+// a bearer token when it is not empty.
+func NewGatewaySender(url, token, from string, client *http.Client) *GatewaySender {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &GatewaySender{url: url, token: token, from: from, client: client}
+}
+
+func (s *GatewaySender) Send(ctx context.Context, phone, message string) error {
+	body, err := json.Marshal(gatewayMessage{
+		From: s.from,
+		To:   phone,
+		Text: message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send sms: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
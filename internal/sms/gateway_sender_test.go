@@ -0,0 +1,44 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGatewaySender_Send_Success(t *testing.T) {
+	var received gatewayMessage
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sender := NewGatewaySender(srv.URL, "gateway-token", "Shop", srv.Client())
+	err := sender.Send(context.Background(), "+998901234567", "Your code is 123456")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer gateway-token", authorization)
+	assert.Equal(t, gatewayMessage{From: "Shop", To: "+998901234567", Text: "Your code is 123456"}, received)
+}
+
+func TestGatewaySender_Send_Non2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sender := NewGatewaySender(srv.URL, "", "", srv.Client())
+	err := sender.Send(context.Background(), "+998901234567", "Your code is 123456")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
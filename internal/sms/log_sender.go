@@ -0,0 +1,19 @@
+package sms
+
+import (
+	"context"
+	"log"
+)
+
+// LogSender writes messages to the log instead of sending them. It is meant
+// for local development, where codes can be read from the output.
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(_ context.Context, phone, message string) error {
+	log.Printf("sms to %s: %s", phone, message)
+	return nil
+}
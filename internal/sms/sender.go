@@ -0,0 +1,8 @@
+package sms
+
+import "context"
+
+//go:generate mockery --name=SMSSender --output=../mocks --structname=SMSSenderMock
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
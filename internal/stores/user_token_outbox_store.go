@@ -5,11 +5,30 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockery --name=Store --output=../mocks --structname=StoreMock
+type Store interface {
+	Users() repositories.UserRepository
+	Tokens() repositories.TokenRepository
+	Outbox() repositories.EventRepository
+	Clients() repositories.ClientRepository
+	AuthorizationCodes() repositories.AuthorizationCodeRepository
+	PasswordResetTokens() repositories.PasswordResetTokenRepository
+	LoginLockouts() repositories.LoginLockoutRepository
+	TOTPCredentials() repositories.TOTPCredentialRepository
+	RecoveryCodes() repositories.RecoveryCodeRepository
+	WebAuthnCredentials() repositories.WebAuthnCredentialRepository
+	WebAuthnChallenges() repositories.WebAuthnChallengeRepository
+	MagicLinkTokens() repositories.MagicLinkTokenRepository
+	SMSCodes() repositories.SMSCodeRepository
+	UserIdentities() repositories.UserIdentityRepository
+	Roles() repositories.RoleRepository
+}
+
 type UserTokenOutboxStore struct {
 	db *gorm.DB
 }
 
-func NewUserTokenOutboxStore(db *gorm.DB) *UserTokenOutboxStore {
+func NewUserTokenOutboxStore(db *gorm.DB) Store {
 	return &UserTokenOutboxStore{db: db}
 }
 
@@ -22,3 +41,39 @@ func (s *UserTokenOutboxStore) Tokens() repositories.TokenRepository {
 func (s *UserTokenOutboxStore) Outbox() repositories.EventRepository {
 	return repositories.NewEventRepository(s.db)
 }
+func (s *UserTokenOutboxStore) Clients() repositories.ClientRepository {
+	return repositories.NewClientRepository(s.db)
+}
+func (s *UserTokenOutboxStore) AuthorizationCodes() repositories.AuthorizationCodeRepository {
+	return repositories.NewAuthorizationCodeRepository(s.db)
+}
+func (s *UserTokenOutboxStore) PasswordResetTokens() repositories.PasswordResetTokenRepository {
+	return repositories.NewPasswordResetTokenRepository(s.db)
+}
+func (s *UserTokenOutboxStore) LoginLockouts() repositories.LoginLockoutRepository {
+	return repositories.NewLoginLockoutRepository(s.db)
+}
+func (s *UserTokenOutboxStore) TOTPCredentials() repositories.TOTPCredentialRepository {
+	return repositories.NewTOTPCredentialRepository(s.db)
+}
+func (s *UserTokenOutboxStore) RecoveryCodes() repositories.RecoveryCodeRepository {
+	return repositories.NewRecoveryCodeRepository(s.db)
+}
+func (s *UserTokenOutboxStore) WebAuthnCredentials() repositories.WebAuthnCredentialRepository {
+	return repositories.NewWebAuthnCredentialRepository(s.db)
+}
+func (s *UserTokenOutboxStore) WebAuthnChallenges() repositories.WebAuthnChallengeRepository {
+	return repositories.NewWebAuthnChallengeRepository(s.db)
+}
+func (s *UserTokenOutboxStore) MagicLinkTokens() repositories.MagicLinkTokenRepository {
+	return repositories.NewMagicLinkTokenRepository(s.db)
+}
+func (s *UserTokenOutboxStore) SMSCodes() repositories.SMSCodeRepository {
+	return repositories.NewSMSCodeRepository(s.db)
+}
+func (s *UserTokenOutboxStore) UserIdentities() repositories.UserIdentityRepository {
+	return repositories.NewUserIdentityRepository(s.db)
+}
+func (s *UserTokenOutboxStore) Roles() repositories.RoleRepository {
+	return repositories.NewRoleRepository(s.db)
+}
@@ -4,6 +4,7 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate mockery --name=UnitOfWork --output=../mocks --structname=UnitOfWorkMock
 type UnitOfWork[T any] interface {
 	DoTransaction(fn func(store T) error) error
 	Do(fn func(store T) error) error
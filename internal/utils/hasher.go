@@ -1,26 +1,84 @@
 package utils
 
 import (
+	"strings"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
 //go:generate mockery --name=PasswordHasher --output=../mocks --structname=PasswordHasherMock
 type PasswordHasher interface {
-	Hash(password string) string
+	Hash(password string) (string, error)
 	Verify(password, hash string) bool
+	// NeedsRehash reports whether hash was made with another algorithm or
+	// other parameters than Hash uses now.
+	NeedsRehash(hash string) bool
 }
 
-type BcryptHasher struct{}
+type BcryptHasher struct {
+	cost int
+}
 
 func NewBcryptHasher() *BcryptHasher {
-	return &BcryptHasher{}
+	return &BcryptHasher{cost: bcrypt.DefaultCost}
 }
 
-func (h *BcryptHasher) Hash(password string) string {
-	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(hash)
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
 }
 
 func (h *BcryptHasher) Verify(password, hash string) bool {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cost
+}
+
+// bcryptPrefixes are the prefixes of the bcrypt variants BcryptHasher verifies.
+var bcryptPrefixes = []string{"$2a$", "$2b$", "$2y$"}
+
+// DispatchHasher hashes new passwords with one hasher and verifies existing
+// hashes with the hasher their prefix belongs to, so hashes made with an older
+// algorithm keep working until they are upgraded.
+type DispatchHasher struct {
+	current  PasswordHasher
+	prefixes map[string]PasswordHasher
+}
+
+// NewDispatchHasher hashes with Argon2id and still verifies bcrypt hashes.
+func NewDispatchHasher(argon2id *Argon2idHasher, bcryptHasher *BcryptHasher) *DispatchHasher {
+	prefixes := map[string]PasswordHasher{argon2idPrefix: argon2id}
+	for _, prefix := range bcryptPrefixes {
+		prefixes[prefix] = bcryptHasher
+	}
+	return &DispatchHasher{current: argon2id, prefixes: prefixes}
+}
+
+func (h *DispatchHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *DispatchHasher) Verify(password, hash string) bool {
+	hasher := h.hasherFor(hash)
+	return hasher != nil && hasher.Verify(password, hash)
+}
+
+func (h *DispatchHasher) NeedsRehash(hash string) bool {
+	hasher := h.hasherFor(hash)
+	return hasher != h.current || h.current.NeedsRehash(hash)
+}
+
+func (h *DispatchHasher) hasherFor(hash string) PasswordHasher {
+	for prefix, hasher := range h.prefixes {
+		if strings.HasPrefix(hash, prefix) {
+			return hasher
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keep the tests fast.
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	hash, err := hasher.Hash("correct-horse-42")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$"))
+	assert.Len(t, strings.Split(hash, "$"), 6)
+
+	assert.True(t, hasher.Verify("correct-horse-42", hash))
+	assert.False(t, hasher.Verify("correct-horse-43", hash))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	other, err := hasher.Hash("correct-horse-42")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "salts must differ")
+
+	stronger := testArgon2idParams
+	stronger.Iterations = 2
+	assert.True(t, NewArgon2idHasher(stronger).NeedsRehash(hash))
+	assert.True(t, NewArgon2idHasher(stronger).Verify("correct-horse-42", hash))
+
+	assert.False(t, hasher.Verify("correct-horse-42", "$argon2id$v=19$m=64,t=1,p=1$bad"))
+	assert.False(t, hasher.Verify("correct-horse-42", "$argon2i$v=19$m=64,t=1,p=1$c2FsdA$a2V5"))
+}
+
+func TestDispatchHasher(t *testing.T) {
+	bcryptHasher := &BcryptHasher{cost: bcrypt.MinCost}
+	hasher := NewDispatchHasher(NewArgon2idHasher(testArgon2idParams), bcryptHasher)
+
+	legacy, err := bcryptHasher.Hash("correct-horse-42")
+	require.NoError(t, err)
+	assert.True(t, hasher.Verify("correct-horse-42", legacy))
+	assert.False(t, hasher.Verify("wrong", legacy))
+	assert.True(t, hasher.NeedsRehash(legacy))
+
+	hash, err := hasher.Hash("correct-horse-42")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+	assert.True(t, hasher.Verify("correct-horse-42", hash))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	assert.False(t, hasher.Verify("correct-horse-42", "plaintext"))
+	assert.True(t, hasher.NeedsRehash("plaintext"))
+}
@@ -1,61 +1,145 @@
 package utils
 
 import (
-	"crypto/rsa"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
-	"strings"
+	"github.com/google/uuid"
+	"slices"
 	"time"
 )
 
 //go:generate mockery --name=JWTHelper --output=../mocks --structname=JWTHelperMock
 type JWTHelper interface {
-	GenerateAccessToken(userID string, roles []string) (string, error)
+	GenerateAccessToken(userID string, roles []string, permissions []string) (string, error)
+	GenerateDelegatedAccessToken(userID string, roles []string, permissions []string, clientID string, scope string) (string, error)
+	GenerateClientAccessToken(clientID string, scope string) (string, error)
+	ParseAccessToken(tokenString string) (*Claims, error)
+	AccessTokenTTL() time.Duration
 }
 
 type JWTManager struct {
-	privateKey    *rsa.PrivateKey
+	keyring       *Keyring
 	tokenDuration time.Duration
-	kid           string
+	issuer        string
 }
 
-func NewJWTManager(pemKey string, duration time.Duration, kid string) (*JWTManager, error) {
-	if pemKey == "" {
-		return nil, fmt.Errorf("private key PEM string is empty")
-	}
-
-	pemKey = strings.ReplaceAll(pemKey, `\n`, "\n")
-
-	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(pemKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
+func NewJWTManager(keyring *Keyring, duration time.Duration, issuer string) *JWTManager {
 	return &JWTManager{
-		privateKey:    privateKey,
+		keyring:       keyring,
 		tokenDuration: duration,
-		kid:           kid,
-	}, nil
+		issuer:        issuer,
+	}
 }
 
+var ErrInvalidToken = errors.New("invalid token")
+
 type Claims struct {
 	UserID string   `json:"user_id"`
 	Roles  []string `json:"roles"`
+	// Permissions are those of every role of the user when the token was
+	// issued.
+	Permissions []string `json:"permissions,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func (j *JWTManager) GenerateAccessToken(userID string, roles []string) (string, error) {
+// HasPermission reports whether the token grants permission.
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+func (j *JWTManager) GenerateAccessToken(userID string, roles []string, permissions []string) (string, error) {
+	return j.sign(j.userClaims(userID, roles, permissions))
+}
+
+// GenerateDelegatedAccessToken issues a token for a client acting for a user,
+// as in the authorization_code grant. Next to the claims of the user it names
+// the client and the scope the user granted it.
+func (j *JWTManager) GenerateDelegatedAccessToken(
+	userID string,
+	roles []string,
+	permissions []string,
+	clientID string,
+	scope string,
+) (string, error) {
+	claims := j.userClaims(userID, roles, permissions)
+	claims.ClientID = clientID
+	claims.Scope = scope
+
+	return j.sign(claims)
+}
+
+func (j *JWTManager) userClaims(userID string, roles []string, permissions []string) *Claims {
+	return &Claims{
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    j.issuer,
+		},
+	}
+}
+
+// GenerateClientAccessToken issues a token for a client acting on its own
+// behalf, as in the client_credentials grant. The subject is the client ID and
+// no user or roles are attached.
+func (j *JWTManager) GenerateClientAccessToken(clientID string, scope string) (string, error) {
 	claims := &Claims{
-		UserID: userID,
-		Roles:  roles,
+		ClientID: clientID,
+		Scope:    scope,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   clientID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "auth-service",
+			Issuer:    j.issuer,
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	token.Header["kid"] = j.kid
 
-	return token.SignedString(j.privateKey)
+	return j.sign(claims)
+}
+
+// AccessTokenTTL is the lifetime of the tokens the manager issues.
+func (j *JWTManager) AccessTokenTTL() time.Duration {
+	return j.tokenDuration
+}
+
+// ParseAccessToken verifies a token issued by GenerateAccessToken against the
+// keyring key named by its kid, which is one of the keys published in the JWKS.
+func (j *JWTManager) ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := j.keyring.VerificationKey(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		if token.Method.Alg() != key.Alg {
+			return nil, fmt.Errorf("kid %q does not sign with %s", kid, token.Method.Alg())
+		}
+		return key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{AlgRS256, AlgES256, AlgEdDSA}),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+func (j *JWTManager) sign(claims *Claims) (string, error) {
+	key := j.keyring.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Alg), claims)
+	token.Header["kid"] = key.Kid
+
+	return token.SignedString(key.PrivateKey)
 }
@@ -0,0 +1,196 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIssuer = "http://auth.test"
+
+func newTestRSAKeyPEM(t *testing.T) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+func newTestPKCS8KeyPEM(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func newTestECKeyPEM(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func newTestEd25519KeyPEM(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return newTestPKCS8KeyPEM(t, key)
+}
+
+func newTestKeyring(t *testing.T) *Keyring {
+	key, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestJWTManager_ParseAccessToken_RoundTrip(t *testing.T) {
+	manager := NewJWTManager(newTestKeyring(t), time.Minute, testIssuer)
+
+	token, err := manager.GenerateAccessToken("user-1", []string{"customer"}, []string{"orders:create"})
+	require.NoError(t, err)
+
+	claims, err := manager.ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"customer"}, claims.Roles)
+	assert.Equal(t, []string{"orders:create"}, claims.Permissions)
+	assert.True(t, claims.HasPermission("orders:create"))
+	assert.False(t, claims.HasPermission("users:unlock"))
+	assert.NotEmpty(t, claims.ID)
+
+	other, err := manager.GenerateAccessToken("user-1", []string{"customer"}, nil)
+	require.NoError(t, err)
+	otherClaims, err := manager.ParseAccessToken(other)
+	require.NoError(t, err)
+	assert.NotEqual(t, claims.ID, otherClaims.ID)
+}
+
+func TestJWTManager_GenerateDelegatedAccessToken(t *testing.T) {
+	manager := NewJWTManager(newTestKeyring(t), time.Minute, testIssuer)
+
+	token, err := manager.GenerateDelegatedAccessToken("user-1", []string{"customer"}, []string{"orders:read"}, "spa", "openid orders:read")
+	require.NoError(t, err)
+
+	claims, err := manager.ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, []string{"orders:read"}, claims.Permissions)
+	assert.Equal(t, "spa", claims.ClientID)
+	assert.Equal(t, "openid orders:read", claims.Scope)
+}
+
+func TestJWTManager_GenerateClientAccessToken(t *testing.T) {
+	manager := NewJWTManager(newTestKeyring(t), time.Minute, testIssuer)
+
+	token, err := manager.GenerateClientAccessToken("billing", "orders:read")
+	require.NoError(t, err)
+
+	claims, err := manager.ParseAccessToken(token)
+	assert.NoError(t, err)
+	assert.Empty(t, claims.UserID)
+	assert.Equal(t, "billing", claims.Subject)
+	assert.Equal(t, "billing", claims.ClientID)
+	assert.Equal(t, "orders:read", claims.Scope)
+}
+
+func TestJWTManager_AlgorithmFollowsKeyType(t *testing.T) {
+	tests := []struct {
+		name string
+		pem  string
+		alg  string
+	}{
+		{name: "RSA", pem: newTestRSAKeyPEM(t), alg: AlgRS256},
+		{name: "ECDSA P-256", pem: newTestECKeyPEM(t), alg: AlgES256},
+		{name: "Ed25519", pem: newTestEd25519KeyPEM(t), alg: AlgEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseSigningKeyPEM(tt.pem)
+			require.NoError(t, err)
+			keyring, err := NewKeyring(key)
+			require.NoError(t, err)
+
+			manager := NewJWTManager(keyring, time.Minute, testIssuer)
+			token, err := manager.GenerateAccessToken("user-1", []string{"customer"}, nil)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Method.Alg())
+			assert.Equal(t, key.Kid, parsed.Header["kid"])
+
+			claims, err := manager.ParseAccessToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, "user-1", claims.UserID)
+		})
+	}
+}
+
+func TestJWTManager_ParseAccessToken_RejectsAlgorithmMismatch(t *testing.T) {
+	key, err := ParseSigningKeyPEM(newTestECKeyPEM(t))
+	require.NoError(t, err)
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+	manager := NewJWTManager(keyring, time.Minute, testIssuer)
+
+	// Re-sign the same claims with an RSA key but keep the EC key's kid.
+	rsaKey, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, &Claims{
+		UserID: "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	})
+	token.Header["kid"] = key.Kid
+	signed, err := token.SignedString(rsaKey.PrivateKey)
+	require.NoError(t, err)
+
+	_, err = manager.ParseAccessToken(signed)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.True(t, strings.Contains(err.Error(), "does not sign with RS256"))
+}
+
+func TestJWTManager_ParseAccessToken_Rejects(t *testing.T) {
+	manager := NewJWTManager(newTestKeyring(t), time.Minute, testIssuer)
+
+	otherKey := NewJWTManager(newTestKeyring(t), time.Minute, testIssuer)
+	forged, err := otherKey.GenerateAccessToken("user-1", nil, nil)
+	require.NoError(t, err)
+
+	expiredManager := NewJWTManager(newTestKeyring(t), -time.Minute, testIssuer)
+	expired, err := expiredManager.GenerateAccessToken("user-1", nil, nil)
+	require.NoError(t, err)
+
+	_, err = manager.ParseAccessToken(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = expiredManager.ParseAccessToken(expired)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = manager.ParseAccessToken("not-a-jwt")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
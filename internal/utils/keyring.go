@@ -0,0 +1,259 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SigningKey is a key held by the Keyring. Retired keys only keep the public
+// half and are used for verification only. Alg is derived from the key type.
+type SigningKey struct {
+	Kid        string
+	Alg        string
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// Keyring holds the active signing key, an optional next key waiting to be
+// promoted and the retired keys that still verify tokens issued before a
+// rotation. Every key is published in the JWKS, so verifiers learn about the
+// next key before it signs anything.
+type Keyring struct {
+	mu        sync.RWMutex
+	active    *SigningKey
+	next      *SigningKey
+	promoteAt time.Time
+	keys      map[string]*SigningKey
+	order     []string
+	now       func() time.Time
+}
+
+func NewKeyring(active *SigningKey) (*Keyring, error) {
+	if active == nil || active.PrivateKey == nil {
+		return nil, errors.New("active key must have a private key")
+	}
+
+	k := &Keyring{
+		active: active,
+		keys:   make(map[string]*SigningKey),
+		now:    time.Now,
+	}
+	k.add(active)
+
+	return k, nil
+}
+
+// AddRetired registers a key that is only used to verify tokens.
+func (k *Keyring) AddRetired(key *SigningKey) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.add(&SigningKey{Kid: key.Kid, Alg: key.Alg, PublicKey: key.PublicKey})
+}
+
+// SchedulePromotion makes next the signing key once at has passed. The current
+// active key is retired at that point.
+func (k *Keyring) SchedulePromotion(next *SigningKey, at time.Time) error {
+	if next == nil || next.PrivateKey == nil {
+		return errors.New("next key must have a private key")
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.next = next
+	k.promoteAt = at
+	k.add(next)
+
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with, promoting the next key
+// first if its time has come.
+func (k *Keyring) SigningKey() *SigningKey {
+	k.mu.RLock()
+	due := k.next != nil && !k.now().Before(k.promoteAt)
+	active := k.active
+	k.mu.RUnlock()
+
+	if !due {
+		return active
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.next != nil && !k.now().Before(k.promoteAt) {
+		k.active = k.next
+		k.next = nil
+	}
+
+	return k.active
+}
+
+// VerificationKey returns the key registered under kid.
+func (k *Keyring) VerificationKey(kid string) (*SigningKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// JWKS serializes the public half of every key in the keyring.
+func (k *Keyring) JWKS() ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(k.order))}
+	for _, kid := range k.order {
+		jwk, err := NewJWK(k.keys[kid].PublicKey, kid)
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return json.Marshal(jwks)
+}
+
+// Algorithms lists the distinct algorithms of the keys in the keyring.
+func (k *Keyring) Algorithms() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var algs []string
+	for _, kid := range k.order {
+		if alg := k.keys[kid].Alg; !seen[alg] {
+			seen[alg] = true
+			algs = append(algs, alg)
+		}
+	}
+
+	return algs
+}
+
+func (k *Keyring) add(key *SigningKey) {
+	if _, ok := k.keys[key.Kid]; !ok {
+		k.order = append(k.order, key.Kid)
+	}
+	k.keys[key.Kid] = key
+}
+
+// ParseSigningKeyPEM parses a PEM encoded RSA, ECDSA P-256 or Ed25519 private
+// key. Escaped newlines are accepted so keys can be passed through single-line
+// env vars.
+func ParseSigningKeyPEM(pemKey string) (*SigningKey, error) {
+	if pemKey == "" {
+		return nil, fmt.Errorf("private key PEM string is empty")
+	}
+
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(pemKey, `\n`, "\n")))
+	if block == nil {
+		return nil, errors.New("failed to parse PEM block")
+	}
+
+	privateKey, err := parsePrivateKeyBlock(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return newSigningKey(privateKey, privateKey.Public())
+}
+
+// ParseVerificationKeysPEM parses one or more PEM blocks holding public or
+// private keys. Only the public halves are kept.
+func ParseVerificationKeysPEM(pemKeys string) ([]*SigningKey, error) {
+	rest := []byte(strings.ReplaceAll(pemKeys, `\n`, "\n"))
+
+	var keys []*SigningKey
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		publicKey, err := parsePublicKeyBlock(block)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := newSigningKey(nil, publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("failed to parse PEM block")
+	}
+
+	return keys, nil
+}
+
+func parsePrivateKeyBlock(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+}
+
+func parsePublicKeyBlock(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return pub, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		privateKey, err := parsePrivateKeyBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key: %w", err)
+		}
+		return privateKey.Public(), nil
+	}
+}
+
+func newSigningKey(privateKey crypto.Signer, publicKey crypto.PublicKey) (*SigningKey, error) {
+	alg, err := Algorithm(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, err := Thumbprint(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		Kid:        kid,
+		Alg:        alg,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThumbprint_RFC7638Example(t *testing.T) {
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+
+	thumbprint, err := Thumbprint(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func TestThumbprint_RFC8037Example(t *testing.T) {
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.NoError(t, err)
+
+	thumbprint, err := Thumbprint(ed25519.PublicKey(x))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", thumbprint)
+}
+
+func TestNewJWK_ECDSA(t *testing.T) {
+	key, err := ParseSigningKeyPEM(newTestECKeyPEM(t))
+	require.NoError(t, err)
+
+	jwk, err := NewJWK(key.PublicKey, key.Kid)
+	require.NoError(t, err)
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	require.NoError(t, err)
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	require.NoError(t, err)
+
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "P-256", jwk.Crv)
+	assert.Equal(t, AlgES256, jwk.Alg)
+	assert.Len(t, x, 32)
+	assert.Len(t, y, 32)
+	assert.Empty(t, jwk.N)
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	for name, pemKey := range map[string]string{"RSA": newTestRSAKeyPEM(t), "EC": newTestECKeyPEM(t)} {
+		t.Run(name, func(t *testing.T) {
+			key, err := ParseSigningKeyPEM(pemKey)
+			require.NoError(t, err)
+			jwk, err := NewJWK(key.PublicKey, key.Kid)
+			require.NoError(t, err)
+
+			publicKey, err := jwk.PublicKey()
+			require.NoError(t, err)
+			assert.Equal(t, key.PublicKey, publicKey)
+		})
+	}
+
+	jwk, err := NewJWK(edKey.Public(), "ed")
+	require.NoError(t, err)
+	publicKey, err := jwk.PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, edKey.Public(), publicKey)
+
+	_, err = JWK{Kty: "EC", Crv: "P-384", X: jwk.X, Y: jwk.X}.PublicKey()
+	assert.Error(t, err)
+	_, err = JWK{Kty: "RSA"}.PublicKey()
+	assert.Error(t, err)
+}
+
+func TestKeyring_SchedulePromotion(t *testing.T) {
+	active, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+	next, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(active)
+	require.NoError(t, err)
+
+	now := time.Now()
+	keyring.now = func() time.Time { return now }
+	require.NoError(t, keyring.SchedulePromotion(next, now.Add(time.Hour)))
+
+	manager := NewJWTManager(keyring, time.Hour, testIssuer)
+	before, err := manager.GenerateAccessToken("user-1", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, active.Kid, keyring.SigningKey().Kid)
+
+	now = now.Add(2 * time.Hour)
+	after, err := manager.GenerateAccessToken("user-1", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, next.Kid, keyring.SigningKey().Kid)
+
+	// Tokens signed by the retired key keep verifying after the promotion.
+	_, err = manager.ParseAccessToken(before)
+	assert.NoError(t, err)
+	_, err = manager.ParseAccessToken(after)
+	assert.NoError(t, err)
+}
+
+func TestKeyring_JWKS_PublishesEveryKey(t *testing.T) {
+	active, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+	next, err := ParseSigningKeyPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+	retired, err := ParseVerificationKeysPEM(newTestRSAKeyPEM(t))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(active)
+	require.NoError(t, err)
+	keyring.AddRetired(retired[0])
+	require.NoError(t, keyring.SchedulePromotion(next, time.Now().Add(time.Hour)))
+
+	data, err := keyring.JWKS()
+	require.NoError(t, err)
+
+	var jwks JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+
+	kids := make([]string, len(jwks.Keys))
+	for i, key := range jwks.Keys {
+		kids[i] = key.Kid
+		assert.Equal(t, "RS256", key.Alg)
+	}
+	assert.Equal(t, []string{active.Kid, retired[0].Kid, next.Kid}, kids)
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 7636 appendix B.
+const (
+	rfc7636Verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfc7636Challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestPKCEChallenge_RFC7636(t *testing.T) {
+	assert.Equal(t, rfc7636Challenge, PKCEChallenge(rfc7636Verifier))
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	assert.True(t, VerifyPKCE(rfc7636Verifier, rfc7636Challenge))
+	assert.False(t, VerifyPKCE(rfc7636Verifier+"x", rfc7636Challenge))
+	assert.False(t, VerifyPKCE("short", PKCEChallenge("short")))
+
+	invalid := strings.Repeat("a", 42) + "/"
+	assert.False(t, VerifyPKCE(invalid, PKCEChallenge(invalid)))
+}
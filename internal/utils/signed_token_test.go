@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACTokenSigner(t *testing.T) {
+	now := time.Now()
+	signer := NewHMACTokenSigner([]byte("secret"))
+	signer.now = func() time.Time { return now }
+
+	token, err := signer.Sign(SignedTokenClaims{
+		Purpose:   "verify_email",
+		Subject:   "user-1",
+		Email:     "john@example.com",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	claims, err := signer.Verify(token, "verify_email")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	assert.Equal(t, "john@example.com", claims.Email)
+
+	_, err = signer.Verify(token, "reset_password")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewHMACTokenSigner([]byte("other")).Verify(token, "verify_email")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	_, err = signer.Verify(payload+"x."+signature, "verify_email")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	now = now.Add(time.Hour)
+	_, err = signer.Verify(token, "verify_email")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
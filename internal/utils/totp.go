@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP generates and checks RFC 6238 time-based one-time passwords. Secrets
+// are base32 encoded, as authenticator apps expect them.
+type TOTP struct {
+	// Algorithm is "SHA1", "SHA256" or "SHA512". Most authenticator apps only
+	// support SHA1.
+	Algorithm string
+	Digits    int
+	Period    time.Duration
+	// Skew is the number of periods before and after the current one whose
+	// codes are still accepted, to allow for clock drift.
+	Skew int
+}
+
+// NewTOTP returns the parameters every authenticator app supports: SHA1, six
+// digits and a 30 second period.
+func NewTOTP() *TOTP {
+	return &TOTP{Algorithm: "SHA1", Digits: 6, Period: 30 * time.Second, Skew: 1}
+}
+
+// GenerateTOTPSecret returns a random 160-bit secret, the key length RFC 4226
+// recommends for HMAC-SHA1.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return totpSecretEncoding.EncodeToString(secret), nil
+}
+
+// Step returns the number of periods since the Unix epoch at t.
+func (t *TOTP) Step(at time.Time) int64 {
+	return at.Unix() / int64(t.Period/time.Second)
+}
+
+// Code returns the code of a raw key for a time step (RFC 4226 section 5.3).
+func (t *TOTP) Code(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(t.hash(), key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < t.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.Digits, value%mod)
+}
+
+// Verify checks code against the base32 secret at the given time. Only steps
+// after lastStep are accepted, so a code cannot be replayed. It returns the
+// step the code matched.
+func (t *TOTP) Verify(secret, code string, at time.Time, lastStep int64) (int64, bool) {
+	key, err := DecodeTOTPSecret(secret)
+	if err != nil || len(code) != t.Digits {
+		return 0, false
+	}
+
+	current := t.Step(at)
+	for step := current - int64(t.Skew); step <= current+int64(t.Skew); step++ {
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(t.Code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns the otpauth URI authenticator apps scan from a QR code.
+func (t *TOTP) URI(secret, issuer, account string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {t.Algorithm},
+		"digits":    {fmt.Sprint(t.Digits)},
+		"period":    {fmt.Sprint(int(t.Period.Seconds()))},
+	}
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// DecodeTOTPSecret decodes a base32 secret, ignoring case, spaces and padding.
+func DecodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return totpSecretEncoding.DecodeString(strings.TrimRight(secret, "="))
+}
+
+func (t *TOTP) hash() func() hash.Hash {
+	switch t.Algorithm {
+	case "SHA256":
+		return sha256.New
+	case "SHA512":
+		return sha512.New
+	default:
+		return sha1.New
+	}
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 appendix B. Every algorithm uses the ASCII seed repeated to its
+// output length.
+var rfc6238Vectors = []struct {
+	unix   int64
+	sha1   string
+	sha256 string
+	sha512 string
+}{
+	{59, "94287082", "46119246", "90693936"},
+	{1111111109, "07081804", "68084774", "25091201"},
+	{1111111111, "14050471", "67062674", "99943326"},
+	{1234567890, "89005924", "91819424", "93441116"},
+	{2000000000, "69279037", "90698825", "38618901"},
+	{20000000000, "65353130", "77737706", "47863826"},
+}
+
+func TestTOTP_RFC6238(t *testing.T) {
+	seeds := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	for _, vector := range rfc6238Vectors {
+		at := time.Unix(vector.unix, 0)
+		expected := map[string]string{"SHA1": vector.sha1, "SHA256": vector.sha256, "SHA512": vector.sha512}
+
+		for algorithm, seed := range seeds {
+			totp := &TOTP{Algorithm: algorithm, Digits: 8, Period: 30 * time.Second}
+			assert.Equal(t, expected[algorithm], totp.Code(seed, totp.Step(at)), "%s at %d", algorithm, vector.unix)
+
+			secret := totpSecretEncoding.EncodeToString(seed)
+			step, ok := totp.Verify(secret, expected[algorithm], at, 0)
+			assert.True(t, ok)
+			assert.Equal(t, totp.Step(at), step)
+		}
+	}
+}
+
+func TestTOTP_Verify(t *testing.T) {
+	totp := NewTOTP()
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+	key, err := DecodeTOTPSecret(secret)
+	require.NoError(t, err)
+
+	now := time.Unix(1_800_000_000, 0)
+	step := totp.Step(now)
+
+	t.Run("Accepts the neighbouring periods", func(t *testing.T) {
+		for _, s := range []int64{step - 1, step, step + 1} {
+			matched, ok := totp.Verify(secret, totp.Code(key, s), now, 0)
+			assert.True(t, ok)
+			assert.Equal(t, s, matched)
+		}
+	})
+
+	t.Run("Rejects codes outside the skew", func(t *testing.T) {
+		_, ok := totp.Verify(secret, totp.Code(key, step-2), now, 0)
+		assert.False(t, ok)
+		_, ok = totp.Verify(secret, totp.Code(key, step+2), now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("Rejects replayed codes", func(t *testing.T) {
+		_, ok := totp.Verify(secret, totp.Code(key, step), now, step)
+		assert.False(t, ok)
+	})
+
+	t.Run("Rejects malformed codes", func(t *testing.T) {
+		_, ok := totp.Verify(secret, "12345", now, 0)
+		assert.False(t, ok)
+		_, ok = totp.Verify("not base32!", totp.Code(key, step), now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func TestTOTP_URI(t *testing.T) {
+	uri, err := url.Parse(NewTOTP().URI("JBSWY3DPEHPK3PXP", "Shop", "john@example.com"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Shop:john@example.com", uri.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", uri.Query().Get("secret"))
+	assert.Equal(t, "Shop", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
@@ -0,0 +1,65 @@
+package validators
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"strings"
+)
+
+type BreachedPasswords interface {
+	Contains(password string) (bool, error)
+}
+
+// BreachedPasswordRanges looks passwords up in a directory laid out like the
+// k-anonymity range API of Have I Been Pwned: one file per upper case 5 hex
+// character prefix of the SHA-1 of the password, holding one "SUFFIX" or
+// "SUFFIX:COUNT" line per breached password. Only the file of the prefix is
+// read, so the full list never has to fit in memory.
+type BreachedPasswordRanges struct {
+	fsys fs.FS
+}
+
+func NewBreachedPasswordRanges(fsys fs.FS) *BreachedPasswordRanges {
+	return &BreachedPasswordRanges{fsys: fsys}
+}
+
+//go:embed breached_passwords
+var defaultBreachedPasswords embed.FS
+
+// DefaultBreachedPasswords is a short list of the most common breached
+// passwords shipped with the service.
+func DefaultBreachedPasswords() *BreachedPasswordRanges {
+	fsys, err := fs.Sub(defaultBreachedPasswords, "breached_passwords")
+	if err != nil {
+		panic(err)
+	}
+	return NewBreachedPasswordRanges(fsys)
+}
+
+func (b *BreachedPasswordRanges) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	file, err := b.fsys.Open(prefix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(line, suffix) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
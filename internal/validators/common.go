@@ -38,7 +38,7 @@ func (v *GoValidator) Validate(req dto.Request) ValidationResult {
 		errorsMap := make(map[string]string)
 		for _, fe := range ve {
 			field := strings.ToLower(fe.Field())
-			errorsMap[field] = req.FieldErrorCode(field)
+			errorsMap[field] = req.FieldErrorCode(field, fe.ActualTag())
 		}
 		return ValidationResult{
 			Valid:  false,
@@ -0,0 +1,156 @@
+package validators
+
+import (
+	"log"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Tags of the password policy rules. The "password" tag runs every rule but
+// the personal one, which needs the names of the fields to compare against:
+//
+//	Password string `validate:"required,password,password_personal=Email Name"`
+const (
+	PasswordTag             = "password"
+	PasswordRuleMinLength   = "password_min"
+	PasswordRuleMaxLength   = "password_max"
+	PasswordRuleCharClasses = "password_classes"
+	PasswordRuleBreached    = "password_breached"
+	PasswordRulePersonal    = "password_personal"
+)
+
+// minPersonalInfoLength keeps short names from rejecting unrelated passwords.
+const minPersonalInfoLength = 3
+
+type PasswordPolicy struct {
+	MinLength int
+	// MaxLength is counted in bytes, bcrypt ignores everything after 72.
+	MaxLength int
+	// MinCharClasses is how many of lower case, upper case, digits and
+	// other characters the password must mix.
+	MinCharClasses int
+	// RejectPersonalInfo blocks passwords that contain the email, the local
+	// part of the email or the name of the user.
+	RejectPersonalInfo bool
+	// Breached is optional.
+	Breached BreachedPasswords
+}
+
+// Register adds the password policy tags to v.
+func (p *PasswordPolicy) Register(v *validator.Validate) error {
+	rules := map[string]validator.Func{
+		PasswordRuleMinLength:   p.validateMinLength,
+		PasswordRuleMaxLength:   p.validateMaxLength,
+		PasswordRuleCharClasses: p.validateCharClasses,
+		PasswordRuleBreached:    p.validateBreached,
+		PasswordRulePersonal:    p.validatePersonal,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+
+	v.RegisterAlias(PasswordTag, strings.Join([]string{
+		PasswordRuleMinLength,
+		PasswordRuleMaxLength,
+		PasswordRuleCharClasses,
+		PasswordRuleBreached,
+	}, ","))
+	return nil
+}
+
+func (p *PasswordPolicy) validateMinLength(fl validator.FieldLevel) bool {
+	return len([]rune(fl.Field().String())) >= p.MinLength
+}
+
+func (p *PasswordPolicy) validateMaxLength(fl validator.FieldLevel) bool {
+	return p.MaxLength <= 0 || len(fl.Field().String()) <= p.MaxLength
+}
+
+func (p *PasswordPolicy) validateCharClasses(fl validator.FieldLevel) bool {
+	return charClasses(fl.Field().String()) >= p.MinCharClasses
+}
+
+// validateBreached lets the password through when the list cannot be read, so
+// a broken list does not lock everybody out of registration.
+func (p *PasswordPolicy) validateBreached(fl validator.FieldLevel) bool {
+	if p.Breached == nil {
+		return true
+	}
+
+	breached, err := p.Breached.Contains(fl.Field().String())
+	if err != nil {
+		log.Printf("could not check the breached password list: %v", err)
+		return true
+	}
+	return !breached
+}
+
+func (p *PasswordPolicy) validatePersonal(fl validator.FieldLevel) bool {
+	var values []string
+	parent := reflect.Indirect(fl.Parent())
+	for _, name := range strings.Fields(fl.Param()) {
+		field := parent.FieldByName(name)
+		if field.IsValid() && field.Kind() == reflect.String {
+			values = append(values, field.String())
+		}
+	}
+	return !p.ContainsPersonalInfo(fl.Field().String(), values...)
+}
+
+// ContainsPersonalInfo reports whether the password contains one of values,
+// such as the email or the name of the user. Requests that do not carry these
+// values check them once the user is loaded. It is always false when the
+// policy does not reject personal info.
+func (p *PasswordPolicy) ContainsPersonalInfo(password string, values ...string) bool {
+	if p == nil || !p.RejectPersonalInfo {
+		return false
+	}
+
+	password = strings.ToLower(password)
+	for _, value := range values {
+		for _, info := range personalInfo(value) {
+			if len(info) >= minPersonalInfoLength && strings.Contains(password, info) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// personalInfo returns value in lower case and, for an email, its local part.
+func personalInfo(value string) []string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if local, _, ok := strings.Cut(value, "@"); ok {
+		return []string{value, local}
+	}
+	return []string{value}
+}
+
+func charClasses(password string) int {
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, other} {
+		if present {
+			classes++
+		}
+	}
+	return classes
+}
@@ -0,0 +1,40 @@
+package validators
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreachedPasswordRanges_Contains(t *testing.T) {
+	// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+	ranges := NewBreachedPasswordRanges(fstest.MapFS{
+		"5BAA6": {Data: []byte("0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n")},
+	})
+
+	breached, err := ranges.Contains("password")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+
+	breached, err = ranges.Contains("correct-horse-42")
+	assert.NoError(t, err)
+	assert.False(t, breached)
+
+	breached, err = DefaultBreachedPasswords().Contains("qwerty123")
+	assert.NoError(t, err)
+	assert.True(t, breached)
+}
+
+func TestPasswordPolicy_ContainsPersonalInfo(t *testing.T) {
+	policy := &PasswordPolicy{RejectPersonalInfo: true}
+
+	assert.True(t, policy.ContainsPersonalInfo("John@Example.com!", "john@example.com"))
+	assert.True(t, policy.ContainsPersonalInfo("hello-john-1", "john@example.com"))
+	assert.True(t, policy.ContainsPersonalInfo("Jackson2026", "john@example.com", "Jamol", "Jackson"))
+	assert.False(t, policy.ContainsPersonalInfo("Al-is-here-1", "Al"))
+	assert.False(t, policy.ContainsPersonalInfo("correct-horse-42", "john@example.com", "Jamol", "Jackson"))
+
+	assert.False(t, (&PasswordPolicy{}).ContainsPersonalInfo("john-1", "john@example.com"))
+	assert.False(t, (*PasswordPolicy)(nil).ContainsPersonalInfo("john-1", "john@example.com"))
+}
@@ -0,0 +1,35 @@
+package validators
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// PhoneTag validates Uzbek mobile numbers in E.164 form, such as
+// "+998901234567".
+const PhoneTag = "uzphone"
+
+const uzPhonePrefix = "+998"
+
+// RegisterPhone adds the phone tag to v.
+func RegisterPhone(v *validator.Validate) error {
+	return v.RegisterValidation(PhoneTag, func(fl validator.FieldLevel) bool {
+		return IsUzPhone(fl.Field().String())
+	})
+}
+
+// IsUzPhone reports whether phone is "+998" followed by the nine digits of
+// the operator code and the subscriber number.
+func IsUzPhone(phone string) bool {
+	digits, ok := strings.CutPrefix(phone, uzPhonePrefix)
+	if !ok || len(digits) != 9 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
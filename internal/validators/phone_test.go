@@ -0,0 +1,18 @@
+package validators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUzPhone(t *testing.T) {
+	assert.True(t, IsUzPhone("+998901234567"))
+
+	assert.False(t, IsUzPhone("998901234567"))
+	assert.False(t, IsUzPhone("+99890123456"))
+	assert.False(t, IsUzPhone("+9989012345678"))
+	assert.False(t, IsUzPhone("+998 90 123 45"))
+	assert.False(t, IsUzPhone("+79012345678"))
+	assert.False(t, IsUzPhone(""))
+}
@@ -0,0 +1,93 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"slices"
+)
+
+// oidFIDOAAGUID is the certificate extension naming the authenticator model.
+var oidFIDOAAGUID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+
+// verifyPackedAttestation checks a packed attestation statement (WebAuthn
+// section 8.2). With x5c it is signed by an attestation certificate, without
+// it by the credential itself.
+func verifyPackedAttestation(statement map[any]any, signed []byte, credentialKey *PublicKey, aaguid []byte) (string, error) {
+	alg, _ := statement["alg"].(int64)
+	sig, _ := statement["sig"].([]byte)
+	if sig == nil {
+		return "", fmt.Errorf("%w: packed attestation without signature", ErrInvalidResponse)
+	}
+
+	chain, hasChain := statement["x5c"].([]any)
+	if !hasChain {
+		if alg != credentialKey.Algorithm {
+			return "", fmt.Errorf("%w: self attestation algorithm mismatch", ErrInvalidResponse)
+		}
+		if !credentialKey.Verify(signed, sig) {
+			return "", fmt.Errorf("%w: bad self attestation signature", ErrInvalidResponse)
+		}
+		return AttestationSelf, nil
+	}
+
+	if len(chain) == 0 {
+		return "", fmt.Errorf("%w: empty attestation certificate chain", ErrInvalidResponse)
+	}
+	der, _ := chain[0].([]byte)
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return "", fmt.Errorf("%w: attestation certificate: %v", ErrInvalidResponse, err)
+	}
+
+	if !certificateMatchesAlgorithm(cert, alg) || !verifySignature(alg, cert.PublicKey, signed, sig) {
+		return "", fmt.Errorf("%w: bad attestation signature", ErrInvalidResponse)
+	}
+	if err := verifyPackedCertificate(cert, aaguid); err != nil {
+		return "", err
+	}
+	return AttestationBasic, nil
+}
+
+func certificateMatchesAlgorithm(cert *x509.Certificate, alg int64) bool {
+	switch cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		return alg == AlgES256
+	case ed25519.PublicKey:
+		return alg == AlgEdDSA
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	default:
+		return false
+	}
+}
+
+// verifyPackedCertificate checks the requirements of WebAuthn section 8.2.1.
+func verifyPackedCertificate(cert *x509.Certificate, aaguid []byte) error {
+	switch {
+	case cert.Version != 3:
+		return fmt.Errorf("%w: attestation certificate is not version 3", ErrInvalidResponse)
+	case cert.IsCA:
+		return fmt.Errorf("%w: attestation certificate is a CA", ErrInvalidResponse)
+	case !slices.Contains(cert.Subject.OrganizationalUnit, "Authenticator Attestation"):
+		return fmt.Errorf("%w: attestation certificate has the wrong subject", ErrInvalidResponse)
+	}
+
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidFIDOAAGUID) {
+			continue
+		}
+		if ext.Critical {
+			return fmt.Errorf("%w: critical aaguid extension", ErrInvalidResponse)
+		}
+		var certAAGUID []byte
+		if _, err := asn1.Unmarshal(ext.Value, &certAAGUID); err != nil || !bytes.Equal(certAAGUID, aaguid) {
+			return fmt.Errorf("%w: aaguid does not match the attestation certificate", ErrInvalidResponse)
+		}
+	}
+	return nil
+}
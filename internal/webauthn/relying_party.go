@@ -0,0 +1,162 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidResponse        = errors.New("invalid webauthn response")
+	ErrUnsupportedAttestation = errors.New("unsupported attestation format")
+	ErrSignCount              = errors.New("signature counter did not increase")
+)
+
+// RelyingParty verifies the responses of WebAuthn ceremonies (WebAuthn
+// sections 7.1 and 7.2). ID is the domain credentials are scoped to and
+// Origins lists the origins the ceremonies may run on.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+	// RequireUserVerification rejects responses without the UV flag, which
+	// passwordless sign-in needs: the authenticator checked a PIN or a
+	// biometric, not just presence.
+	RequireUserVerification bool
+}
+
+// Registration is a credential created by a registration ceremony.
+type Registration struct {
+	CredentialID []byte
+	// PublicKey is the COSE_Key of the credential, kept as received.
+	PublicKey       []byte
+	SignCount       uint32
+	AAGUID          []byte
+	AttestationType string
+}
+
+// Attestation types a Registration can have.
+const (
+	AttestationNone  = "none"
+	AttestationSelf  = "self"
+	AttestationBasic = "basic"
+)
+
+// VerifyRegistration checks the response to a credential creation with the
+// given challenge. Attestation certificates are checked for their format, not
+// against a list of trusted authenticator vendors.
+func (rp *RelyingParty) VerifyRegistration(clientDataJSON, attestationObject, challenge []byte) (*Registration, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	value, rest, err := decodeCBOR(attestationObject)
+	if err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("%w: attestation object: %v", ErrInvalidResponse, err)
+	}
+	object, _ := value.(map[any]any)
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[any]any)
+	rawAuthData, _ := object["authData"].([]byte)
+	if format == "" || statement == nil || rawAuthData == nil {
+		return nil, fmt.Errorf("%w: incomplete attestation object", ErrInvalidResponse)
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.CredentialID == nil {
+		return nil, fmt.Errorf("%w: no attested credential data", ErrInvalidResponse)
+	}
+
+	publicKey, err := ParsePublicKey(authData.CredentialPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+
+	var attestationType string
+	switch format {
+	case "none":
+		if len(statement) > 0 {
+			return nil, fmt.Errorf("%w: none attestation with a statement", ErrInvalidResponse)
+		}
+		attestationType = AttestationNone
+	case "packed":
+		attestationType, err = verifyPackedAttestation(statement, signed, publicKey, authData.AAGUID)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAttestation, format)
+	}
+
+	return &Registration{
+		CredentialID:    bytes.Clone(authData.CredentialID),
+		PublicKey:       bytes.Clone(authData.CredentialPublicKey),
+		SignCount:       authData.SignCount,
+		AAGUID:          bytes.Clone(authData.AAGUID),
+		AttestationType: attestationType,
+	}, nil
+}
+
+// VerifyAssertion checks the response to a credential request with the given
+// challenge, signed with the stored publicKey. It returns the new signature
+// counter of the credential.
+func (rp *RelyingParty) VerifyAssertion(
+	clientDataJSON, authenticatorData, signature, challenge, publicKey []byte,
+	storedSignCount uint32,
+) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataTypeGet, challenge); err != nil {
+		return 0, err
+	}
+
+	authData, err := parseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return 0, err
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authenticatorData...), clientDataHash[:]...)
+	if !key.Verify(signed, signature) {
+		return 0, fmt.Errorf("%w: bad signature", ErrInvalidResponse)
+	}
+
+	// Authenticators without a counter always send zero. Any other counter
+	// that does not move forward hints at a cloned authenticator. Replays are
+	// for the caller to stop by using each challenge once.
+	if (authData.SignCount != 0 || storedSignCount != 0) && authData.SignCount <= storedSignCount {
+		return 0, ErrSignCount
+	}
+
+	return authData.SignCount, nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(authData *AuthenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(authData.RPIDHash, rpIDHash[:]) {
+		return fmt.Errorf("%w: rp id hash mismatch", ErrInvalidResponse)
+	}
+	if !authData.UserPresent() {
+		return fmt.Errorf("%w: user not present", ErrInvalidResponse)
+	}
+	if rp.RequireUserVerification && !authData.UserVerified() {
+		return fmt.Errorf("%w: user not verified", ErrInvalidResponse)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package webauthn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures in testdata were recorded from a software authenticator for
+// the rp id "localhost" and the origin "http://localhost:8080". They use the
+// JSON encoding of PublicKeyCredential, as browsers serialize it.
+type fixture struct {
+	RPID       string `json:"rp_id"`
+	Origin     string `json:"origin"`
+	Challenge  string `json:"challenge"`
+	PublicKey  string `json:"public_key"`
+	SignCount  uint32 `json:"sign_count"`
+	Credential struct {
+		ID       string `json:"id"`
+		Response struct {
+			ClientDataJSON    string `json:"clientDataJSON"`
+			AttestationObject string `json:"attestationObject"`
+			AuthenticatorData string `json:"authenticatorData"`
+			Signature         string `json:"signature"`
+		} `json:"response"`
+	} `json:"credential"`
+}
+
+func loadFixture(t *testing.T, name string) fixture {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	var f fixture
+	require.NoError(t, json.Unmarshal(data, &f))
+	return f
+}
+
+func b64(t *testing.T, s string) []byte {
+	decoded, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return decoded
+}
+
+func testRelyingParty() *RelyingParty {
+	return &RelyingParty{
+		ID:                      "localhost",
+		Name:                    "Shop",
+		Origins:                 []string{"http://localhost:8080"},
+		RequireUserVerification: true,
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	tests := []struct {
+		fixture         string
+		attestationType string
+	}{
+		{"registration_none.json", AttestationNone},
+		{"registration_packed_self.json", AttestationSelf},
+		{"registration_packed_x5c.json", AttestationBasic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			f := loadFixture(t, tt.fixture)
+
+			registration, err := testRelyingParty().VerifyRegistration(
+				b64(t, f.Credential.Response.ClientDataJSON),
+				b64(t, f.Credential.Response.AttestationObject),
+				b64(t, f.Challenge),
+			)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.attestationType, registration.AttestationType)
+			assert.Equal(t, b64(t, f.Credential.ID), registration.CredentialID)
+			assert.Len(t, registration.AAGUID, 16)
+
+			key, err := ParsePublicKey(registration.PublicKey)
+			require.NoError(t, err)
+			assert.Equal(t, AlgES256, key.Algorithm)
+		})
+	}
+}
+
+func TestVerifyRegistration_Rejects(t *testing.T) {
+	f := loadFixture(t, "registration_packed_x5c.json")
+	clientData := b64(t, f.Credential.Response.ClientDataJSON)
+	attestation := b64(t, f.Credential.Response.AttestationObject)
+	challenge := b64(t, f.Challenge)
+
+	t.Run("Other challenge", func(t *testing.T) {
+		_, err := testRelyingParty().VerifyRegistration(clientData, attestation, []byte("other"))
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("Other origin", func(t *testing.T) {
+		rp := testRelyingParty()
+		rp.Origins = []string{"https://shop.example.com"}
+
+		_, err := rp.VerifyRegistration(clientData, attestation, challenge)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("Other rp id", func(t *testing.T) {
+		rp := testRelyingParty()
+		rp.ID = "shop.example.com"
+
+		_, err := rp.VerifyRegistration(clientData, attestation, challenge)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("Tampered authenticator data", func(t *testing.T) {
+		tampered := append([]byte(nil), attestation...)
+		// The last byte belongs to the y coordinate of the credential key,
+		// which the attestation signature covers.
+		tampered[len(tampered)-1] ^= 0xff
+
+		_, err := testRelyingParty().VerifyRegistration(clientData, tampered, challenge)
+		assert.Error(t, err)
+	})
+
+	t.Run("Assertion instead of registration", func(t *testing.T) {
+		assertion := loadFixture(t, "assertion_packed_self.json")
+
+		_, err := testRelyingParty().VerifyRegistration(
+			b64(t, assertion.Credential.Response.ClientDataJSON), attestation, b64(t, assertion.Challenge),
+		)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	f := loadFixture(t, "assertion_packed_self.json")
+	clientData := b64(t, f.Credential.Response.ClientDataJSON)
+	authData := b64(t, f.Credential.Response.AuthenticatorData)
+	signature := b64(t, f.Credential.Response.Signature)
+	challenge := b64(t, f.Challenge)
+	publicKey := b64(t, f.PublicKey)
+
+	t.Run("Success", func(t *testing.T) {
+		signCount, err := testRelyingParty().VerifyAssertion(clientData, authData, signature, challenge, publicKey, f.SignCount)
+
+		assert.NoError(t, err)
+		assert.Greater(t, signCount, f.SignCount)
+	})
+
+	t.Run("Replayed counter", func(t *testing.T) {
+		_, err := testRelyingParty().VerifyAssertion(clientData, authData, signature, challenge, publicKey, f.SignCount+1)
+		assert.ErrorIs(t, err, ErrSignCount)
+	})
+
+	t.Run("Other credential", func(t *testing.T) {
+		other := loadFixture(t, "registration_none.json")
+		registration, err := testRelyingParty().VerifyRegistration(
+			b64(t, other.Credential.Response.ClientDataJSON),
+			b64(t, other.Credential.Response.AttestationObject),
+			b64(t, other.Challenge),
+		)
+		require.NoError(t, err)
+
+		_, err = testRelyingParty().VerifyAssertion(clientData, authData, signature, challenge, registration.PublicKey, 0)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("Other challenge", func(t *testing.T) {
+		_, err := testRelyingParty().VerifyAssertion(clientData, authData, signature, []byte("other"), publicKey, f.SignCount)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	// {1: 2, "a": [h'01', -7, true]}
+	value, rest, err := decodeCBOR([]byte{0xa2, 0x01, 0x02, 0x61, 'a', 0x83, 0x41, 0x01, 0x26, 0xf5, 0xff})
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, rest)
+	assert.Equal(t, map[any]any{int64(1): int64(2), "a": []any{[]byte{1}, int64(-7), true}}, value)
+
+	_, _, err = decodeCBOR([]byte{0x5a, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err, "length beyond the data")
+	_, _, err = decodeCBOR([]byte{0x9f})
+	assert.Error(t, err, "indefinite length")
+	_, _, err = decodeCBOR([]byte{0xa2, 0x01, 0x01, 0x01, 0x02})
+	assert.Error(t, err, "duplicate key")
+}
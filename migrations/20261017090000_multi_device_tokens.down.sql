@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_tokens_session_id;
+
+-- Keep only the most recently used session of every user before restoring the
+-- one-row-per-user constraint.
+DELETE FROM tokens t
+USING tokens newer
+WHERE t.user_id = newer.user_id
+  AND (t.last_used_at, t.id) < (newer.last_used_at, newer.id);
+
+ALTER TABLE tokens
+DROP COLUMN IF EXISTS session_id,
+DROP COLUMN IF EXISTS user_agent,
+DROP COLUMN IF EXISTS ip,
+DROP COLUMN IF EXISTS last_used_at;
+
+ALTER TABLE tokens
+ADD CONSTRAINT tokens_user_id_key UNIQUE (user_id);
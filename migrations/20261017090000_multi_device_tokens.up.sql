@@ -0,0 +1,23 @@
+ALTER TABLE tokens
+DROP CONSTRAINT IF EXISTS tokens_user_id_key;
+
+ALTER TABLE tokens
+ADD COLUMN session_id UUID,
+ADD COLUMN user_agent TEXT NOT NULL DEFAULT '',
+ADD COLUMN ip VARCHAR(45) NOT NULL DEFAULT '',
+ADD COLUMN last_used_at TIMESTAMP;
+
+-- Every existing row becomes its own session. Refresh tokens are now looked up
+-- by their SHA-256 digest, so rows still holding a bcrypt hash can no longer be
+-- presented and simply expire.
+UPDATE tokens
+SET session_id   = gen_random_uuid(),
+    last_used_at = updated_at
+WHERE session_id IS NULL;
+
+ALTER TABLE tokens
+ALTER COLUMN session_id SET NOT NULL,
+ALTER COLUMN last_used_at SET NOT NULL,
+ALTER COLUMN last_used_at SET DEFAULT now();
+
+CREATE UNIQUE INDEX idx_tokens_session_id ON tokens(session_id);
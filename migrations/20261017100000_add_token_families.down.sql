@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_tokens_session_id;
+
+DELETE FROM tokens
+WHERE rotated_at IS NOT NULL
+   OR revoked_at IS NOT NULL;
+
+ALTER TABLE tokens
+DROP COLUMN IF EXISTS parent_id,
+DROP COLUMN IF EXISTS rotated_at,
+DROP COLUMN IF EXISTS revoked_at;
+
+CREATE UNIQUE INDEX idx_tokens_session_id ON tokens(session_id);
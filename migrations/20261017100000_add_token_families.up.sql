@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_tokens_session_id;
+
+ALTER TABLE tokens
+ADD COLUMN parent_id INTEGER REFERENCES tokens (id) ON DELETE SET NULL,
+ADD COLUMN rotated_at TIMESTAMP,
+ADD COLUMN revoked_at TIMESTAMP;
+
+-- A session now keeps every token it rotated through, so session_id doubles as
+-- the token family and is no longer unique.
+CREATE INDEX idx_tokens_session_id ON tokens(session_id);
@@ -0,0 +1,13 @@
+CREATE TABLE clients (
+    id UUID PRIMARY KEY,
+    client_id TEXT NOT NULL UNIQUE,
+    secret_hash TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    grant_types TEXT NOT NULL DEFAULT '',
+    scopes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Refresh tokens issued through /oauth/token remember the client they belong to.
+ALTER TABLE tokens ADD COLUMN client_id TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS authorization_codes;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS redirect_uris;
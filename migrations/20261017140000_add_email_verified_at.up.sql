@@ -0,0 +1,6 @@
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMP;
+
+-- Accounts created before verification existed are treated as verified, so
+-- turning on AUTH_REQUIRE_VERIFIED_EMAIL does not lock them out.
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,9 @@
+CREATE TABLE rate_limit_windows (
+    key TEXT NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key, window_start)
+);
+
+CREATE INDEX idx_rate_limit_windows_expires_at ON rate_limit_windows(expires_at);
@@ -0,0 +1,18 @@
+ALTER TABLE users
+ADD COLUMN phone TEXT UNIQUE,
+ADD COLUMN phone_verified_at TIMESTAMP;
+
+CREATE TABLE sms_codes (
+    id SERIAL PRIMARY KEY,
+    phone TEXT NOT NULL,
+    purpose TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_sms_codes_phone_purpose ON sms_codes(phone, purpose);
+CREATE INDEX idx_sms_codes_user_id ON sms_codes(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE user_roles DROP CONSTRAINT IF EXISTS fk_user_roles_role;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,48 @@
+CREATE TABLE roles (
+    name VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE permissions (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role VARCHAR(50) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES permissions (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO roles (name, description) VALUES
+    ('customer', 'Buys products'),
+    ('seller', 'Sells products'),
+    ('admin', 'Administers the shop');
+
+INSERT INTO permissions (name, description) VALUES
+    ('profile:read', 'Read own profile'),
+    ('profile:write', 'Update own profile'),
+    ('orders:create', 'Place orders'),
+    ('orders:read', 'Read own orders'),
+    ('products:write', 'Create and update own products'),
+    ('orders:fulfill', 'Fulfill orders of own products'),
+    ('users:read', 'Read any user'),
+    ('users:unlock', 'Lift the login lock of a user');
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('customer', 'profile:read'),
+    ('customer', 'profile:write'),
+    ('customer', 'orders:create'),
+    ('customer', 'orders:read'),
+    ('seller', 'products:write'),
+    ('seller', 'orders:fulfill'),
+    ('admin', 'users:read'),
+    ('admin', 'users:unlock');
+
+-- Roles given before this migration stay valid, without permissions.
+INSERT INTO roles (name)
+SELECT DISTINCT role FROM user_roles
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE user_roles
+    ADD CONSTRAINT fk_user_roles_role FOREIGN KEY (role) REFERENCES roles (name);
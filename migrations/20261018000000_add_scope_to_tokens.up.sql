@@ -0,0 +1,3 @@
+-- Refresh tokens of OAuth clients remember the scope the user granted, so
+-- refreshed access tokens keep it.
+ALTER TABLE tokens ADD COLUMN scope TEXT NOT NULL DEFAULT '';
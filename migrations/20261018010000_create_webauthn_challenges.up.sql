@@ -0,0 +1,9 @@
+CREATE TABLE webauthn_challenges (
+    id SERIAL PRIMARY KEY,
+    challenge_hash TEXT NOT NULL UNIQUE,
+    purpose TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_webauthn_challenges_expires_at ON webauthn_challenges(expires_at);
@@ -0,0 +1,7 @@
+CREATE TABLE revoked_subjects (
+    subject TEXT PRIMARY KEY,
+    issued_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_revoked_subjects_expires_at ON revoked_subjects(expires_at);
@@ -0,0 +1,77 @@
+// Package authz lets services check the permissions carried by the access
+// tokens of the auth service. It does not verify tokens: the service does
+// that first and stores the verified claims in the gin context under
+// ClaimsKey, either as *Claims or as its own type implementing
+// PermissionChecker.
+//
+// It is a module of its own, so services can depend on it without the rest
+// of the auth service.
+package authz
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClaimsKey is the gin context key of the verified claims of the request.
+const ClaimsKey = "claims"
+
+// PermissionChecker is implemented by claims that carry permissions.
+type PermissionChecker interface {
+	HasPermission(permission string) bool
+}
+
+// Claims are the authorization claims of an access token of the auth service.
+// Decode the payload of a verified token into it, or embed it in the claims
+// type of the service.
+type Claims struct {
+	Roles []string `json:"roles"`
+	// Permissions are those of every role of the user when the token was
+	// issued.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// HasPermission reports whether the token grants permission.
+func (c *Claims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+type errorResponse struct {
+	Success bool              `json:"success"`
+	Errors  map[string]string `json:"errors"`
+}
+
+// RequirePermission lets a request through only when its claims grant every
+// one of permissions. Requests without claims are answered 401, those
+// lacking a permission 403.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(ClaimsKey)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse{
+				Errors: map[string]string{"error": "ERR_UNAUTHORIZED"},
+			})
+			return
+		}
+
+		claims, ok := value.(PermissionChecker)
+		if ok {
+			for _, permission := range permissions {
+				if !claims.HasPermission(permission) {
+					ok = false
+					break
+				}
+			}
+		}
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse{
+				Errors: map[string]string{"error": "ERR_FORBIDDEN"},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package authz
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims []string
+
+func (c testClaims) HasPermission(permission string) bool {
+	return slices.Contains(c, permission)
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		claims interface{}
+		status int
+	}{
+		{name: "All permissions", claims: testClaims{"orders:read", "orders:create"}, status: http.StatusOK},
+		{name: "Missing permission", claims: testClaims{"orders:read"}, status: http.StatusForbidden},
+		{name: "Token claims", claims: &Claims{Permissions: []string{"orders:read", "orders:create"}}, status: http.StatusOK},
+		{name: "Token claims missing a permission", claims: &Claims{Roles: []string{"admin"}}, status: http.StatusForbidden},
+		{name: "Claims without permissions", claims: "user-1", status: http.StatusForbidden},
+		{name: "No claims", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/orders", func(c *gin.Context) {
+				if tt.claims != nil {
+					c.Set(ClaimsKey, tt.claims)
+				}
+			}, RequirePermission("orders:read", "orders:create"), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestClaims(t *testing.T) {
+	var claims Claims
+	err := json.Unmarshal([]byte(`{"sub": "user-1", "roles": ["seller"], "permissions": ["orders:read"]}`), &claims)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"seller"}, claims.Roles)
+	assert.True(t, claims.HasPermission("orders:read"))
+	assert.False(t, claims.HasPermission("orders:create"))
+}